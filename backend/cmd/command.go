@@ -0,0 +1,59 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"log"
+)
+
+// runCommand 执行运维子命令，例如 `ibook rebuild-search`、`ibook reconcile --repair`
+func runCommand(app *App, name string, args []string) error {
+	// 命令行没有请求上下文，使用空的 gin.Context 调用 service
+	ctx := &gin.Context{}
+	switch name {
+	case "rebuild-search":
+		// 从读者库重建全文索引并覆盖快照，运行中的服务需要重启后才会加载新的快照
+		cnt, err := app.search.Rebuild(ctx)
+		if err != nil {
+			return fmt.Errorf("重建搜索索引失败：%w", err)
+		}
+		if err = app.search.SaveSnapshot(ctx); err != nil {
+			return err
+		}
+		log.Printf("搜索索引重建完成，共索引 %d 篇文章\n", cnt)
+		return nil
+	case "requeue-outbox":
+		// 处理完死信的原因后重新投递，由运行中的服务的 relay 任务应用
+		cnt, err := app.articles.RequeueDeadOutboxEvents(ctx)
+		if err != nil {
+			return fmt.Errorf("重新投递死信事件失败：%w", err)
+		}
+		log.Printf("已重新投递 %d 个死信事件\n", cnt)
+		return nil
+	case "reconcile":
+		// 默认只比对并输出报告，带 --repair 时修复不一致的文章
+		fs := flag.NewFlagSet(name, flag.ContinueOnError)
+		repair := fs.Bool("repair", false, "修复不一致的文章")
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		report, err := app.articles.ReconcileArticles(ctx, *repair, 0)
+		if err != nil {
+			return fmt.Errorf("比对作者库与读者库失败：%w", err)
+		}
+		for _, item := range report.Items {
+			line := fmt.Sprintf("文章 %d（作者 %d）：%v", item.ArticleId, item.AuthorId, item.Issues)
+			if item.Repaired {
+				line += "，已修复"
+			} else if item.RepairErr != "" {
+				line += "，修复失败：" + item.RepairErr
+			}
+			log.Println(line)
+		}
+		log.Println(report.Summary())
+		return nil
+	default:
+		return fmt.Errorf("未知命令：%s", name)
+	}
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/fsnotify/fsnotify"
 	"github.com/gin-contrib/cors"
@@ -10,12 +12,18 @@ import (
 	"github.com/spf13/viper"
 	_ "github.com/spf13/viper/remote"
 	"ibook/internal/conf"
+	"ibook/internal/job"
+	"ibook/internal/service"
 	"ibook/internal/web"
 	"ibook/pkg/middlewares/jwtauth"
 	logger2 "ibook/pkg/middlewares/logger"
 	"ibook/pkg/middlewares/ratelimit"
 	"ibook/pkg/utils/logger"
+	"net/http"
+	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 	"time"
 )
 
@@ -23,26 +31,65 @@ func main() {
 	config := conf.GetConf()
 	// initRemoteViper()
 	fmt.Println(viper.Get("server.port"))
-	server, cleanup, err := wireApp(config.SecretConf, config.DataConf.MysqlConf, config.DataConf.RedisConf, config.ServerConf)
+	app, cleanup, err := wireApp(config.SecretConf, config.DataConf.MysqlConf, config.DataConf.RedisConf, config.ServerConf, config.SearchConf, config.ArticleConf, config.BlobConf, config.EmailConf)
 	if err != nil {
 		panic(err)
 	}
 	defer cleanup()
-	if err := server.Run(config.ServerConf.Port); err != nil {
-		panic(err)
+	// 带有子命令时只执行运维命令，不启动服务
+	if len(os.Args) > 1 {
+		if err := runCommand(app, os.Args[1], os.Args[2:]); err != nil {
+			panic(err)
+		}
+		return
+	}
+	for _, j := range app.jobs {
+		j.Start()
+	}
+	srv := &http.Server{Addr: config.ServerConf.Port, Handler: app.server}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			panic(err)
+		}
+	}()
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+	// 先停止接收新请求并等待进行中的请求完成，再停止后台任务，使其在退出前完成最后一轮刷写
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		fmt.Println("关闭 http 服务出错：", err)
+	}
+	for _, j := range app.jobs {
+		j.Stop()
 	}
 }
 
-func newApp(userHandler *web.UserHandler, articleHandlers *web.ArticleHandler, middlewares []gin.HandlerFunc) *gin.Engine {
+// shutdownTimeout 退出时等待进行中请求完成的最长时间
+const shutdownTimeout = 10 * time.Second
+
+type App struct {
+	server   *gin.Engine
+	jobs     []job.Job
+	search   service.SearchService
+	articles service.ArticleService
+}
+
+func newApp(userHandler *web.UserHandler, articleHandlers *web.ArticleHandler, searchHandler *web.SearchHandler,
+	uploadHandler *web.UploadHandler, middlewares []gin.HandlerFunc, jobs []job.Job, search service.SearchService,
+	articles service.ArticleService) *App {
 	sever := gin.Default()
 	sever.Use(middlewares...)
 	// 注册 /users/*** 路由
 	userHandler.RegisterRoutesV1(sever)
 	articleHandlers.RegisterRoutesV1(sever)
-	return sever
+	searchHandler.RegisterRoutesV1(sever)
+	uploadHandler.RegisterRoutesV1(sever)
+	return &App{server: sever, jobs: jobs, search: search, articles: articles}
 }
 
-func newMiddleware(secret *conf.Secret, logger logger.Logger, redisCli redis.Cmdable) []gin.HandlerFunc {
+func newMiddleware(secret *conf.Secret, logger logger.Logger, redisCli redis.Cmdable, tokens service.UserTokenRepo) []gin.HandlerFunc {
 	corsMw := cors.New(cors.Config{
 		AllowHeaders:     []string{"Content-Type", "Authorization"},
 		ExposeHeaders:    []string{"x-jwt-token"},
@@ -63,6 +110,10 @@ func newMiddleware(secret *conf.Secret, logger logger.Logger, redisCli redis.Cmd
 		IgnorePaths("/users/login_sms/send").
 		IgnorePaths("/users/login_sms").
 		IgnorePaths("/users/login").
+		IgnorePaths("/users/password/reset/send").
+		IgnorePaths("/users/password/reset").
+		IgnorePathPrefix("/blobs/").
+		RevokeChecker(tokens.RevokedBefore).
 		Build()
 	return []gin.HandlerFunc{corsMw, loggerMw, rlMw, jwtMw}
 }
@@ -6,16 +6,16 @@
 package main
 
 import (
-	"github.com/gin-gonic/gin"
 	"github.com/google/wire"
 	"ibook/internal/conf"
 	"ibook/internal/data"
+	"ibook/internal/job"
 	"ibook/internal/service"
 	"ibook/internal/web"
 	"ibook/pkg"
 )
 
 // wireApp init gin application.
-func wireApp(*conf.Secret, *conf.MySQL, *conf.Redis, *conf.Server) (*gin.Engine, func(), error) {
-	panic(wire.Build(data.DataProviderSet, web.WebProviderSet, service.ServiceProviderSet, pkg.PkgProviderSet, newMiddleware, newApp))
+func wireApp(*conf.Secret, *conf.MySQL, *conf.Redis, *conf.Server, *conf.Search, *conf.Article, *conf.Blob, *conf.Email) (*App, func(), error) {
+	panic(wire.Build(data.DataProviderSet, web.WebProviderSet, service.ServiceProviderSet, pkg.PkgProviderSet, job.JobProviderSet, newMiddleware, newApp))
 }
@@ -7,10 +7,10 @@
 package main
 
 import (
-	"github.com/gin-gonic/gin"
 	"ibook/internal/conf"
 	"ibook/internal/data"
 	ratelimit2 "ibook/internal/data/message/sms/ratelimit"
+	"ibook/internal/job"
 	"ibook/internal/service"
 	"ibook/internal/web"
 	"ibook/pkg/utils/logger"
@@ -24,7 +24,7 @@ import (
 // Injectors from wire.go:
 
 // wireApp init gin application.
-func wireApp(secret *conf.Secret, mySQL *conf.MySQL, redis *conf.Redis, server *conf.Server) (*gin.Engine, func(), error) {
+func wireApp(secret *conf.Secret, mySQL *conf.MySQL, redis *conf.Redis, server *conf.Server, search *conf.Search, article *conf.Article, blob *conf.Blob, email *conf.Email) (*App, func(), error) {
 	db := data.NewMDB(mySQL)
 	cmdable := data.NewRDB(redis)
 	dataData, cleanup := data.NewData(db, cmdable)
@@ -32,18 +32,56 @@ func wireApp(secret *conf.Secret, mySQL *conf.MySQL, redis *conf.Redis, server *
 	userCache := data.NewUserCache(cmdable)
 	limiter := ratelimit.NewRedisSlidingWindowLimiter(cmdable)
 	smsRepo := ratelimit2.NewRateLimitSmsRepo(limiter)
+	emailRepo := data.NewEmailRepo(email)
 	verifyCodeRepo := data.NewVerifyCodeRepo(cmdable)
-	userService := service.NewUserService(userRepo, userCache, smsRepo, verifyCodeRepo)
+	userTokenRepo := data.NewUserTokenRepo(cmdable, secret)
+	followRepo := data.NewFollowRepo(dataData)
+	followCache := data.NewFollowCache(dataData)
+	userService := service.NewUserService(userRepo, userCache, smsRepo, emailRepo, verifyCodeRepo, userTokenRepo, followRepo, followCache)
 	userHandler := web.NewUserHandler(userService)
 	articleAuthorRepo := data.NewArticleAuthorRepo(dataData)
 	loggerLogger := logger.NewZapLogger()
 	articleReaderRepo := data.NewArticleReaderRepo(dataData, loggerLogger)
 	articleSyncRepo := data.NewArticleSyncRepo(dataData, loggerLogger)
-	articleService := service.NewArticleService(articleAuthorRepo, articleReaderRepo, articleSyncRepo, loggerLogger)
-	articleHandler := web.NewArticleHandler(articleService, loggerLogger)
-	v := newMiddleware(secret, loggerLogger, cmdable)
-	engine := newApp(userHandler, articleHandler, v)
-	return engine, func() {
+	articleInteractiveRepo := data.NewArticleInteractiveRepo(dataData)
+	articleInteractiveCache := data.NewArticleInteractiveCache(dataData, article)
+	articleCollectRepo := data.NewArticleCollectRepo(dataData)
+	articleRevisionRepo := data.NewArticleRevisionRepo(dataData)
+	articleScheduleRepo := data.NewArticleScheduleRepo(dataData)
+	articleTagRepo := data.NewArticleTagRepo(dataData)
+	articleSearchRepo, err := data.NewArticleSearchRepo(dataData, search, loggerLogger)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	articleHotRepo := data.NewArticleHotRepo(dataData)
+	articleRecycleRepo := data.NewArticleRecycleRepo(dataData, article)
+	articleOutboxRepo := data.NewArticleOutboxRepo(dataData)
+	articleReconcileRepo := data.NewArticleReconcileRepo(dataData)
+	articleLikeRepo := data.NewArticleLikeRepo(dataData)
+	feedRepo := data.NewFeedRepo(dataData)
+	articleService := service.NewArticleService(articleAuthorRepo, articleReaderRepo, articleSyncRepo, articleInteractiveRepo, articleInteractiveCache, articleCollectRepo, articleRevisionRepo, articleScheduleRepo, articleTagRepo, articleSearchRepo, articleHotRepo, articleRecycleRepo, articleOutboxRepo, articleReconcileRepo, articleLikeRepo, followRepo, feedRepo, loggerLogger)
+	commentRepo := data.NewCommentRepo(dataData)
+	commentCache := data.NewCommentCache(dataData)
+	commentService := service.NewCommentService(commentRepo, commentCache, articleInteractiveCache, loggerLogger)
+	articleHandler := web.NewArticleHandler(articleService, commentService, loggerLogger)
+	searchService := service.NewSearchService(articleSearchRepo, articleReaderRepo, loggerLogger)
+	searchHandler := web.NewSearchHandler(searchService, loggerLogger)
+	blobStore := data.NewBlobStore(blob)
+	blobRepo := data.NewBlobRepo(blobStore, blob)
+	uploadService := service.NewUploadService(blobRepo)
+	uploadHandler := web.NewUploadHandler(uploadService, loggerLogger)
+	v := newMiddleware(secret, loggerLogger, cmdable, userTokenRepo)
+	articleScheduleJob := job.NewArticleScheduleJob(articleService, loggerLogger)
+	searchIndexJob := job.NewSearchIndexJob(searchService, loggerLogger)
+	hotRankJob := job.NewHotRankJob(articleService, loggerLogger)
+	articlePurgeJob := job.NewArticlePurgeJob(articleService, loggerLogger)
+	outboxRelayJob := job.NewOutboxRelayJob(articleService, loggerLogger)
+	articleReconcileJob := job.NewArticleReconcileJob(articleService, article, loggerLogger)
+	readFlushJob := job.NewReadFlushJob(articleService, article, loggerLogger)
+	v2 := job.NewJobs(articleScheduleJob, searchIndexJob, hotRankJob, articlePurgeJob, outboxRelayJob, articleReconcileJob, readFlushJob)
+	app := newApp(userHandler, articleHandler, searchHandler, uploadHandler, v, v2, searchService, articleService)
+	return app, func() {
 		cleanup()
 	}, nil
 }
@@ -12,10 +12,14 @@ var iBookConfig *Config
 var RELOAD = 1
 
 type Config struct {
-	ServerConf *Server `yaml:"server"`
-	DataConf   *Data   `yaml:"data"`
-	SecretConf *Secret `yaml:"secret"`
-	Sms        *SMS    `yaml:"sms"`
+	ServerConf  *Server  `yaml:"server"`
+	DataConf    *Data    `yaml:"data"`
+	SecretConf  *Secret  `yaml:"secret"`
+	Sms         *SMS     `yaml:"sms"`
+	SearchConf  *Search  `yaml:"search"`
+	ArticleConf *Article `yaml:"article"`
+	BlobConf    *Blob    `yaml:"blob"`
+	EmailConf   *Email   `yaml:"email"`
 }
 
 type Server struct {
@@ -36,6 +40,46 @@ type Redis struct {
 	Addr string `yaml:"addr"`
 }
 
+type Search struct {
+	// IndexPath 全文索引快照的保存路径，为空时不持久化，每次启动都从读者库重建
+	IndexPath string `yaml:"index_path"`
+}
+
+type Article struct {
+	// RecycleRetentionDays 删除的文章在回收站中保留的天数，超过后被永久清除
+	RecycleRetentionDays int64 `yaml:"recycle_retention_days"`
+	// ReconcileRepair 定时比对作者库与读者库时是否自动修复，为 false 时只输出报告
+	ReconcileRepair bool `yaml:"reconcile_repair"`
+	// ReadFlushIntervalSeconds 阅读计数在缓冲区中停留的最长时间，ReadFlushBatchSize 每批写入数据库的文章数
+	ReadFlushIntervalSeconds int64 `yaml:"read_flush_interval_seconds"`
+	ReadFlushBatchSize       int64 `yaml:"read_flush_batch_size"`
+	// ReadDedupWindowMinutes 同一访问者在该时间内重复阅读同一篇文章只计数一次
+	ReadDedupWindowMinutes int64 `yaml:"read_dedup_window_minutes"`
+}
+
+type Blob struct {
+	// Driver 对象存储的实现：local / s3，为空时使用 local
+	Driver string `yaml:"driver"`
+	// PublicBaseUrl 对外访问上传文件的地址前缀，例如 https://cdn.example.com，为空时由服务自身在 /blobs 下提供
+	PublicBaseUrl string     `yaml:"public_base_url"`
+	Local         *LocalBlob `yaml:"local"`
+	S3            *S3Blob    `yaml:"s3"`
+}
+
+type LocalBlob struct {
+	Root string `yaml:"root"`
+}
+
+type S3Blob struct {
+	Endpoint        string `yaml:"endpoint"`
+	Region          string `yaml:"region"`
+	Bucket          string `yaml:"bucket"`
+	AccessKeyId     string `yaml:"access_key_id"`
+	SecretAccessKey string `yaml:"secret_access_key"`
+	// PathStyle MinIO 等自建的兼容服务通常需要开启
+	PathStyle bool `yaml:"path_style"`
+}
+
 type Secret struct {
 	JwtConf *Jwt `yaml:"jwt"`
 }
@@ -69,6 +113,23 @@ type TencentyunSMS struct {
 	SecretKey string `yaml:"secret_key"`
 }
 
+// Email smtp.host 为必填项，未配置时启动失败
+type Email struct {
+	SMTP *SMTP `yaml:"smtp"`
+}
+
+type SMTP struct {
+	Host string `yaml:"host"`
+	// 465 端口使用隐式 TLS，其他端口在服务端支持时通过 STARTTLS 升级
+	Port     int    `yaml:"port"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	// From 发件人地址，为空时使用 Username
+	From string `yaml:"from"`
+	// TimeoutSeconds 单次发送的超时时间，默认为 10 秒
+	TimeoutSeconds int64 `yaml:"timeout_seconds"`
+}
+
 func GetConf(flags ...int) *Config {
 	if iBookConfig == nil || (len(flags) != 0 && flags[0] == RELOAD) {
 		dir, err := filepath.Abs(filepath.Dir("./"))
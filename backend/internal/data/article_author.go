@@ -1,73 +1,127 @@
 package data
 
 import (
+	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"ibook/internal/service"
+	"slices"
 	"time"
 )
 
 type ArticleAuthor struct {
 	Article
+	// DeletedTime 移入回收站的时间，为 0 表示未删除
+	DeletedTime int64 `gorm:"index"`
 }
 
 type articleAuthorRepo struct {
 	db *Data
 }
 
-func (repo *articleAuthorRepo) CreateArticle(ctx *gin.Context, article *service.ArticleAuthor) (bool, error) {
+// CreateArticle 与文章在同一事务中写入 kind 类型的历史版本
+func (repo *articleAuthorRepo) CreateArticle(ctx *gin.Context, article *service.ArticleAuthor, kind service.RevisionKind) (bool, error) {
 	now := time.Now().UTC().UnixMilli()
 	newArticle := &ArticleAuthor{
-		Article{
+		Article: Article{
 			Title:       article.Title,
 			Content:     article.Content,
+			Format:      uint8(article.Format),
+			Category:    article.Category,
 			AuthorId:    article.Author.Id,
 			CreatedTime: now,
 			UpdatedTime: now,
 			Status:      uint8(article.Status),
+			Version:     1,
 		},
 	}
-	res := repo.db.mdb.Create(newArticle)
-	if err := res.Error; err != nil {
+	err := repo.db.mdb.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(newArticle).Error; err != nil {
+			return err
+		}
+		if err := createRevision(tx, newArticle.Id, article, kind); err != nil {
+			return err
+		}
+		return replaceAuthorTags(tx, newArticle.Id, article.Tags)
+	})
+	if err != nil {
 		return false, err
 	}
 	article.Id = newArticle.Id
+	article.Version = newArticle.Version
 	return true, nil
 }
 
-func (repo *articleAuthorRepo) UpdateArticle(ctx *gin.Context, article *service.ArticleAuthor) (bool, error) {
+// UpdateArticle 返回是否实际写入，内容与状态都未变化时不写入，版本号也不变
+// article.Version 为 service.AnyVersion 时不校验版本号，否则与库中版本号不一致时返回 ArticleVersionConflictErr
+// 实际写入时在同一事务中写入 kind 类型的历史版本，kind 为 RevisionKindUnknown 时不写入
+func (repo *articleAuthorRepo) UpdateArticle(ctx *gin.Context, article *service.ArticleAuthor, kind service.RevisionKind) (bool, error) {
 	now := time.Now().UTC().UnixMilli()
-	newArticle := &ArticleAuthor{
-		Article{
-			Id:          article.Id,
-			Title:       article.Title,
-			Content:     article.Content,
-			UpdatedTime: now,
-			Status:      uint8(article.Status),
-		},
-	}
-
+	changed := false
 	// 不更新 author_id 以及 created_time 字段
-	res := repo.db.mdb.WithContext(ctx).Model(newArticle).
-		Where("id=? and author_id=?", article.Id, article.Author.Id).
-		Updates(map[string]any{
-			"title":        newArticle.Title,
-			"content":      newArticle.Content,
-			"updated_time": newArticle.UpdatedTime,
-			"status":       newArticle.Status,
-		})
-	if res.RowsAffected == 0 {
-		return false, fmt.Errorf("无法编辑此文章")
-	}
-	if err := res.Error; err != nil {
+	err := repo.db.mdb.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		current := &ArticleAuthor{}
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id=? and author_id=? and deleted_time=0", article.Id, article.Author.Id).First(current).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("无法编辑此文章")
+		}
+		if err != nil {
+			return err
+		}
+		if article.Version != service.AnyVersion && article.Version != current.Version {
+			return service.ArticleVersionConflictErr
+		}
+		unchanged := current.Title == article.Title && current.Content == article.Content &&
+			current.Format == uint8(article.Format) && current.Category == article.Category &&
+			current.Status == uint8(article.Status)
+		// Tags 为 nil 表示本次不修改标签
+		if unchanged && article.Tags != nil {
+			tags, err := listAuthorTags(tx, article.Id)
+			if err != nil {
+				return err
+			}
+			unchanged = slices.Equal(tags, article.Tags)
+		}
+		if unchanged {
+			article.Version = current.Version
+			return nil
+		}
+		err = tx.Model(current).Updates(map[string]any{
+			"title":        article.Title,
+			"content":      article.Content,
+			"format":       uint8(article.Format),
+			"category":     article.Category,
+			"updated_time": now,
+			"status":       uint8(article.Status),
+			"version":      gorm.Expr("version + 1"),
+		}).Error
+		if err != nil {
+			return err
+		}
+		changed = true
+		article.Version = current.Version + 1
+		if kind != service.RevisionKindUnknown {
+			if err = createRevision(tx, article.Id, article, kind); err != nil {
+				return err
+			}
+		}
+		if article.Tags == nil {
+			return nil
+		}
+		return replaceAuthorTags(tx, article.Id, article.Tags)
+	})
+	if err != nil {
 		return false, err
 	}
-	return true, nil
+	return changed, nil
 }
 
 func (repo *articleAuthorRepo) UpdateStatusById(ctx *gin.Context, articleId int64, authorId int64, status uint8) error {
 	res := repo.db.mdb.WithContext(ctx).Model(&ArticleAuthor{}).
-		Where("id=? and author_id=?", articleId, authorId).
+		Where("id=? and author_id=? and deleted_time=0", articleId, authorId).
 		Updates(map[string]any{
 			"status": status,
 		})
@@ -84,19 +138,27 @@ func (repo *articleAuthorRepo) UpdateStatusById(ctx *gin.Context, articleId int6
 func (repo *articleAuthorRepo) GetArticleById(ctx *gin.Context, id int64, userId int64) (*service.Article, error) {
 	article := &Article{}
 	res := repo.db.mdb.WithContext(ctx).Model(&ArticleAuthor{}).
-		Where("id=? and author_id=?", id, userId).First(article)
+		Where("id=? and author_id=? and deleted_time=0", id, userId).First(article)
 	if res.Error != nil {
 		return nil, res.Error
 	}
 	if res.RowsAffected == 0 {
 		return nil, fmt.Errorf("查询无果，文章不存在或用户不匹配")
 	}
+	tags, err := listAuthorTags(repo.db.mdb.WithContext(ctx), article.Id)
+	if err != nil {
+		return nil, err
+	}
 	return &service.Article{
-		Id:      article.Id,
-		Title:   article.Title,
-		Content: article.Content,
-		Status:  service.ArticleStatus(article.Status),
-		Author:  service.Author{Id: article.AuthorId},
+		Id:       article.Id,
+		Title:    article.Title,
+		Content:  article.Content,
+		Format:   service.ContentFormat(article.Format),
+		Category: article.Category,
+		Tags:     tags,
+		Status:   service.ArticleStatus(article.Status),
+		Author:   service.Author{Id: article.AuthorId},
+		Version:  article.Version,
 	}, nil
 }
 
@@ -0,0 +1,279 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"ibook/internal/service"
+	"ibook/pkg/utils/bskit/slice"
+	"time"
+)
+
+const defaultCollectFolderName = "默认收藏夹"
+
+type CollectFolder struct {
+	Id          int64  `gorm:"primaryKey,autoIncrement"`
+	UserId      int64  `gorm:"uniqueIndex:uid_name"`
+	Name        string `gorm:"type:varchar(64);uniqueIndex:uid_name"`
+	Visibility  uint8
+	CreatedTime int64
+	UpdatedTime int64
+}
+
+// CollectRecord 一个用户对同一篇文章只保留一条收藏记录，更换收藏夹即更新 FolderId
+type CollectRecord struct {
+	Id          int64 `gorm:"primaryKey,autoIncrement"`
+	UserId      int64 `gorm:"uniqueIndex:uid_aid"`
+	ArticleId   int64 `gorm:"uniqueIndex:uid_aid"`
+	FolderId    int64 `gorm:"index"`
+	CreatedTime int64
+	UpdatedTime int64
+	Status      uint8
+}
+
+const (
+	collectStatusCanceled uint8 = iota
+	collectStatusActive
+)
+
+type articleCollectRepo struct {
+	data *Data
+}
+
+func NewArticleCollectRepo(data *Data) service.ArticleCollectRepo {
+	return &articleCollectRepo{data: data}
+}
+
+func (repo *articleCollectRepo) CreateFolder(ctx *gin.Context, folder *service.CollectFolder) error {
+	now := time.Now().UTC().UnixMilli()
+	f := &CollectFolder{
+		UserId:      folder.UserId,
+		Name:        folder.Name,
+		Visibility:  uint8(folder.Visibility),
+		CreatedTime: now,
+		UpdatedTime: now,
+	}
+	err := repo.data.mdb.WithContext(ctx).Create(f).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return service.CollectFolderAlreadyExistsErr
+		}
+		return err
+	}
+	folder.Id = f.Id
+	folder.CreatedTime = now
+	folder.UpdatedTime = now
+	return nil
+}
+
+func (repo *articleCollectRepo) GetFolderById(ctx *gin.Context, folderId int64) (*service.CollectFolder, error) {
+	f := &CollectFolder{}
+	err := repo.data.mdb.WithContext(ctx).Where("id=?", folderId).First(f).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, service.CollectFolderNotExistsErr
+		}
+		return nil, err
+	}
+	return toServiceCollectFolder(f, 0), nil
+}
+
+func (repo *articleCollectRepo) ListFolders(ctx *gin.Context, userId int64, onlyPublic bool) ([]*service.CollectFolder, error) {
+	var folders []CollectFolder
+	query := repo.data.mdb.WithContext(ctx).Where("user_id=?", userId)
+	if onlyPublic {
+		query = query.Where("visibility=?", service.CollectFolderPublic)
+	}
+	if err := query.Order("id asc").Find(&folders).Error; err != nil {
+		return nil, err
+	}
+	if len(folders) == 0 {
+		return []*service.CollectFolder{}, nil
+	}
+	// 统计每个收藏夹中仍处于收藏状态的文章数
+	var counts []struct {
+		FolderId int64
+		Cnt      int64
+	}
+	folderIds := slice.Map[CollectFolder, int64](folders, func(idx int, src CollectFolder) int64 {
+		return src.Id
+	})
+	err := repo.data.mdb.WithContext(ctx).Model(&CollectRecord{}).
+		Select("folder_id, count(*) as cnt").
+		Where("folder_id in ? and status=?", folderIds, collectStatusActive).
+		Group("folder_id").Scan(&counts).Error
+	if err != nil {
+		return nil, err
+	}
+	cntMap := make(map[int64]int64, len(counts))
+	for _, c := range counts {
+		cntMap[c.FolderId] = c.Cnt
+	}
+	return slice.Map[CollectFolder, *service.CollectFolder](folders, func(idx int, src CollectFolder) *service.CollectFolder {
+		return toServiceCollectFolder(&src, cntMap[src.Id])
+	}), nil
+}
+
+func (repo *articleCollectRepo) ListFolderArticles(ctx *gin.Context, folderId int64, offset int64, limit int64) ([]*service.Article, error) {
+	var dbRes []Article
+	err := repo.data.mdb.WithContext(ctx).Model(&ArticleReader{}).
+		Select("article_readers.*").
+		Joins("join collect_records on collect_records.article_id = article_readers.id").
+		Where("collect_records.folder_id=? and collect_records.status=? and article_readers.status=?",
+			folderId, collectStatusActive, service.ArticleStatusPublished).
+		Order("collect_records.updated_time desc").
+		Offset(int(offset)).Limit(int(limit)).
+		Find(&dbRes).Error
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[Article, *service.Article](dbRes, func(idx int, src Article) *service.Article {
+		return toServiceArticle(&src)
+	}), nil
+}
+
+// UpsertCollectInfo 收藏文章，返回值表示收藏计数是否发生了变化（重复收藏或仅移动收藏夹时不计数）
+func (repo *articleCollectRepo) UpsertCollectInfo(ctx *gin.Context, userId int64, articleId int64, folderId int64) (bool, error) {
+	now := time.Now().UTC().UnixMilli()
+	changed := false
+	err := repo.data.mdb.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 1. 校验文章处于发表状态
+		var articleCnt int64
+		err := tx.Model(&ArticleReader{}).
+			Where("id=? and status=?", articleId, service.ArticleStatusPublished).
+			Count(&articleCnt).Error
+		if err != nil {
+			return err
+		}
+		if articleCnt == 0 {
+			return service.ArticleNotExistsErr
+		}
+		// 2. 确定收藏夹，未指定时使用默认收藏夹
+		if folderId <= 0 {
+			folderId, err = repo.getOrCreateDefaultFolder(tx, userId, now)
+			if err != nil {
+				return err
+			}
+		} else {
+			var folderCnt int64
+			err = tx.Model(&CollectFolder{}).Where("id=? and user_id=?", folderId, userId).Count(&folderCnt).Error
+			if err != nil {
+				return err
+			}
+			if folderCnt == 0 {
+				return service.CollectFolderNotExistsErr
+			}
+		}
+		// 3. 写入收藏记录。先以取消状态插入占位记录（已存在时不做修改），再加锁读取旧记录判断计数是否需要变化，
+		// 避免并发的首次收藏在不存在的记录上加间隙锁导致死锁，或在唯一索引上冲突
+		err = tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&CollectRecord{
+			UserId:      userId,
+			ArticleId:   articleId,
+			FolderId:    folderId,
+			CreatedTime: now,
+			UpdatedTime: now,
+			Status:      collectStatusCanceled,
+		}).Error
+		if err != nil {
+			return err
+		}
+		record := &CollectRecord{}
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id=? and article_id=?", userId, articleId).First(record).Error
+		if err != nil {
+			return err
+		}
+		changed = record.Status != collectStatusActive
+		err = tx.Model(record).Updates(map[string]any{
+			"folder_id":    folderId,
+			"status":       collectStatusActive,
+			"updated_time": now,
+		}).Error
+		if err != nil || !changed {
+			return err
+		}
+		// 4. 更新收藏计数
+		return tx.Clauses(clause.OnConflict{
+			DoUpdates: clause.Assignments(map[string]any{
+				"collect_cnt":  gorm.Expr("collect_cnt + 1"),
+				"updated_time": now,
+			}),
+		}).Create(&Interactive{
+			ArticleId:   articleId,
+			CollectCnt:  1,
+			CreatedTime: now,
+			UpdatedTime: now,
+		}).Error
+	})
+	return changed, err
+}
+
+// CancelCollectInfo 取消收藏，返回值表示收藏计数是否发生了变化
+func (repo *articleCollectRepo) CancelCollectInfo(ctx *gin.Context, userId int64, articleId int64) (bool, error) {
+	now := time.Now().UTC().UnixMilli()
+	changed := false
+	err := repo.data.mdb.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&CollectRecord{}).
+			Where("user_id=? and article_id=? and status=?", userId, articleId, collectStatusActive).
+			Updates(map[string]any{
+				"status":       collectStatusCanceled,
+				"updated_time": now,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+		changed = true
+		return tx.Model(&Interactive{}).
+			Where("article_id=? and collect_cnt > 0", articleId).
+			Updates(map[string]any{
+				"collect_cnt":  gorm.Expr("collect_cnt - 1"),
+				"updated_time": now,
+			}).Error
+	})
+	return changed, err
+}
+
+func (repo *articleCollectRepo) getOrCreateDefaultFolder(tx *gorm.DB, userId int64, now int64) (int64, error) {
+	// 并发创建默认收藏夹时由唯一索引去重，失败的一方直接读取已创建的收藏夹
+	err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&CollectFolder{
+		UserId:      userId,
+		Name:        defaultCollectFolderName,
+		Visibility:  uint8(service.CollectFolderPrivate),
+		CreatedTime: now,
+		UpdatedTime: now,
+	}).Error
+	if err != nil {
+		return 0, fmt.Errorf("获取默认收藏夹失败：%w", err)
+	}
+	folder := &CollectFolder{}
+	err = tx.Where("user_id=? and name=?", userId, defaultCollectFolderName).First(folder).Error
+	if err != nil {
+		return 0, fmt.Errorf("获取默认收藏夹失败：%w", err)
+	}
+	return folder.Id, nil
+}
+
+func (repo *articleCollectRepo) Collected(ctx *gin.Context, userId int64, articleId int64) (bool, error) {
+	var cnt int64
+	err := repo.data.mdb.WithContext(ctx).Model(&CollectRecord{}).
+		Where("user_id=? and article_id=? and status=?", userId, articleId, collectStatusActive).
+		Count(&cnt).Error
+	return cnt > 0, err
+}
+
+func toServiceCollectFolder(f *CollectFolder, articleCnt int64) *service.CollectFolder {
+	return &service.CollectFolder{
+		Id:          f.Id,
+		UserId:      f.UserId,
+		Name:        f.Name,
+		Visibility:  service.CollectFolderVisibility(f.Visibility),
+		ArticleCnt:  articleCnt,
+		CreatedTime: f.CreatedTime,
+		UpdatedTime: f.UpdatedTime,
+	}
+}
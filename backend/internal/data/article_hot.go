@@ -0,0 +1,120 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"ibook/internal/service"
+	"strconv"
+	"time"
+)
+
+const (
+	hotRankKey = "article:hot"
+	hotLockKey = "article:hot:lock"
+	// hotWriteBatch 替换热榜时每条 ZADD 命令写入的成员数量
+	hotWriteBatch = 200
+)
+
+type articleHotRepo struct {
+	data *Data
+}
+
+func NewArticleHotRepo(data *Data) service.ArticleHotRepo {
+	return &articleHotRepo{data: data}
+}
+
+type hotCandidateRow struct {
+	Id          int64
+	CreatedTime int64
+	ReadCnt     int64
+	LikeCnt     int64
+	CollectCnt  int64
+}
+
+func (repo *articleHotRepo) ScanHotCandidates(ctx *gin.Context, afterId int64, limit int64) ([]*service.HotCandidate, error) {
+	var rows []hotCandidateRow
+	// 没有互动记录的文章计数按 0 处理
+	err := repo.data.mdb.WithContext(ctx).Model(&ArticleReader{}).
+		Select("article_readers.id, article_readers.created_time, "+
+			"COALESCE(interactives.read_cnt, 0) AS read_cnt, "+
+			"COALESCE(interactives.like_cnt, 0) AS like_cnt, "+
+			"COALESCE(interactives.collect_cnt, 0) AS collect_cnt").
+		Joins("LEFT JOIN interactives ON interactives.article_id = article_readers.id").
+		Where("article_readers.status = ? and article_readers.id > ?", service.ArticleStatusPublished, afterId).
+		Order("article_readers.id").
+		Limit(int(limit)).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*service.HotCandidate, 0, len(rows))
+	for _, row := range rows {
+		res = append(res, &service.HotCandidate{
+			ArticleId:   row.Id,
+			CreatedTime: row.CreatedTime,
+			ReadCnt:     row.ReadCnt,
+			LikeCnt:     row.LikeCnt,
+			CollectCnt:  row.CollectCnt,
+		})
+	}
+	return res, nil
+}
+
+func (repo *articleHotRepo) ReplaceHotScores(ctx *gin.Context, scores []service.HotScore) error {
+	if len(scores) == 0 {
+		return repo.data.rdb.Del(ctx, hotRankKey).Err()
+	}
+	// 先写入临时 key 再 RENAME，读请求不会看到写了一半的热榜
+	tmpKey := fmt.Sprintf("%s:tmp:%d", hotRankKey, time.Now().UnixNano())
+	pipe := repo.data.rdb.TxPipeline()
+	for start := 0; start < len(scores); start += hotWriteBatch {
+		end := min(start+hotWriteBatch, len(scores))
+		members := make([]redis.Z, 0, end-start)
+		for _, s := range scores[start:end] {
+			members = append(members, redis.Z{Score: s.Score, Member: strconv.FormatInt(s.ArticleId, 10)})
+		}
+		pipe.ZAdd(ctx, tmpKey, members...)
+	}
+	pipe.Rename(ctx, tmpKey, hotRankKey)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (repo *articleHotRepo) IncrHotScore(ctx *gin.Context, articleId int64, delta float64) error {
+	// XX 保证只更新已在榜中的文章，避免只带有部分分数的文章进入热榜
+	err := repo.data.rdb.ZAddArgsIncr(ctx, hotRankKey, redis.ZAddArgs{
+		XX:      true,
+		Members: []redis.Z{{Score: delta, Member: strconv.FormatInt(articleId, 10)}},
+	}).Err()
+	if errors.Is(err, redis.Nil) {
+		return nil
+	}
+	return err
+}
+
+func (repo *articleHotRepo) RemoveHotArticle(ctx *gin.Context, articleId int64) error {
+	return repo.data.rdb.ZRem(ctx, hotRankKey, articleId).Err()
+}
+
+func (repo *articleHotRepo) ListHotArticleIds(ctx *gin.Context, offset int64, limit int64) ([]int64, error) {
+	members, err := repo.data.rdb.ZRevRange(ctx, hotRankKey, offset, offset+limit-1).Result()
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int64, 0, len(members))
+	for _, member := range members {
+		id, err := strconv.ParseInt(member, 10, 64)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func (repo *articleHotRepo) TryLockRecompute(ctx *gin.Context, ttl time.Duration) (bool, error) {
+	// 锁不主动释放，到期后自然失效，从而限制全量计算的频率
+	return repo.data.rdb.SetNX(ctx, hotLockKey, time.Now().UnixMilli(), ttl).Result()
+}
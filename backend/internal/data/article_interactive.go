@@ -4,21 +4,52 @@ import (
 	_ "embed"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
+	"ibook/internal/conf"
 	"ibook/internal/service"
+	"math/rand"
+	"strconv"
 	"time"
 )
 
 var (
 	//go:embed script/lua/article/interactive_incr_cnt.lua
 	luaIncrCnt string
+	//go:embed script/lua/article/interactive_buffer_read.lua
+	luaBufferRead string
+	//go:embed script/lua/article/interactive_set_cnt.lua
+	luaSetCnt string
+	//go:embed script/lua/article/interactive_unlock_flush.lua
+	luaUnlockFlush string
+	//go:embed script/lua/article/interactive_take_buffer.lua
+	luaTakeBuffer string
 )
 
 const (
 	fieldReadCnt    = "read_cnt"
 	fieldCollectCnt = "collect_cnt"
 	fieldLikeCnt    = "like_cnt"
+	fieldDislikeCnt = "dislike_cnt"
+	fieldCommentCnt = "comment_cnt"
+
+	// readBufferKey 尚未写入数据库的阅读计数，field 为文章 id
+	readBufferKey = "article:read_buffer"
+	// readFlushingKey 正在写入数据库的阅读计数，写入成功的文章会从中删除
+	// 刷写中途进程退出时保留在该 key 中，下一次刷写时优先处理
+	readFlushingKey = "article:read_buffer:flushing"
+	// readBatchKey 处理中计数的批次号，缓冲区每次移入处理中时重新生成
+	readBatchKey     = "article:read_buffer:batch"
+	readFlushLockKey = "article:read_buffer:lock"
+
+	// interactiveCacheTTL 计数缓存的过期时间，加上随机抖动避免同一批文章的缓存同时过期
+	interactiveCacheTTL    = 15 * time.Minute
+	interactiveCacheJitter = 3 * time.Minute
+
+	// defaultReadDedupWindowMinutes 未配置时同一访问者重复阅读同一篇文章的去重窗口
+	defaultReadDedupWindowMinutes = 30
 )
 
 type articleInteractiveRepo struct {
@@ -26,74 +57,281 @@ type articleInteractiveRepo struct {
 }
 
 type articleInteractiveCache struct {
-	data *Data
+	data        *Data
+	dedupWindow time.Duration
 }
 
 func NewArticleInteractiveRepo(data *Data) service.ArticleInteractiveRepo {
 	return &articleInteractiveRepo{data: data}
 }
 
-func newArticleInteractiveCache(data *Data) service.ArticleInteractiveCache {
-	return &articleInteractiveCache{data: data}
+func NewArticleInteractiveCache(data *Data, aConf *conf.Article) service.ArticleInteractiveCache {
+	minutes := int64(defaultReadDedupWindowMinutes)
+	if aConf != nil && aConf.ReadDedupWindowMinutes > 0 {
+		minutes = aConf.ReadDedupWindowMinutes
+	}
+	return &articleInteractiveCache{data: data, dedupWindow: time.Duration(minutes) * time.Minute}
 }
 
-func (repo *articleInteractiveRepo) IncrReadCount(ctx *gin.Context, articleId int64) error {
+// IncrReadCounts 批量累加阅读计数，一条语句写入一批文章
+// 每篇文章记录最近一次写入的批次号，同一批次中已写入的文章不再累加
+func (repo *articleInteractiveRepo) IncrReadCounts(ctx *gin.Context, batchId string, counts map[int64]int64) error {
+	if len(counts) == 0 {
+		return nil
+	}
 	now := time.Now().UTC().UnixMilli()
+	inters := make([]Interactive, 0, len(counts))
+	for articleId, cnt := range counts {
+		inters = append(inters, Interactive{
+			ArticleId:   articleId,
+			ReadCnt:     cnt,
+			ReadBatchId: batchId,
+			CreatedTime: now,
+			UpdatedTime: now,
+		})
+	}
+	// MySQL 按顺序执行赋值，read_cnt 需要在 read_batch_id 更新之前比较旧的批次号
 	return repo.data.mdb.WithContext(ctx).Clauses(clause.OnConflict{
-		DoUpdates: clause.Assignments(map[string]any{
-			"read_cnt":     gorm.Expr("read_cnt + 1"),
-			"updated_time": now,
-		}),
-	}).Create(&Interactive{
-		ArticleId:   articleId,
-		ReadCnt:     1,
-		CreatedTime: now,
-		UpdatedTime: now,
-	}).Error
-}
-
-func (repo *articleInteractiveRepo) IncrLikeCnt(ctx *gin.Context, articleId int64) error {
-	now := time.Now().UTC().UnixMilli()
-	return repo.data.mdb.WithContext(ctx).Clauses(clause.OnConflict{
-		DoUpdates: clause.Assignments(map[string]any{
-			"like_cnt":     gorm.Expr("like_cnt + 1"),
-			"updated_time": now,
-		}),
-	}).Create(&Interactive{
-		ArticleId:   articleId,
-		LikeCnt:     1,
-		CreatedTime: now,
-		UpdatedTime: now,
-	}).Error
-}
-
-func (repo *articleInteractiveRepo) DecrLikeCnt(ctx *gin.Context, articleId int64) error {
-	now := time.Now().UTC().UnixMilli()
-	return repo.data.mdb.WithContext(ctx).Clauses(clause.OnConflict{
-		DoUpdates: clause.Assignments(map[string]any{
-			"like_cnt":     gorm.Expr("like_cnt - 1"),
-			"updated_time": now,
-		}),
-	}).Create(&Interactive{
-		ArticleId:   articleId,
-		LikeCnt:     0,
-		CreatedTime: now,
-		UpdatedTime: now,
-	}).Error
+		DoUpdates: clause.Set{
+			{
+				Column: clause.Column{Name: "read_cnt"},
+				Value:  gorm.Expr("IF(read_batch_id = VALUES(read_batch_id), read_cnt, read_cnt + VALUES(read_cnt))"),
+			},
+			{Column: clause.Column{Name: "read_batch_id"}, Value: gorm.Expr("VALUES(read_batch_id)")},
+			{Column: clause.Column{Name: "updated_time"}, Value: now},
+		},
+	}).Create(&inters).Error
+}
+
+func (repo *articleInteractiveRepo) GetInteractive(ctx *gin.Context, articleId int64) (*service.Interactive, error) {
+	inters, err := repo.GetInteractives(ctx, []int64{articleId})
+	if err != nil {
+		return nil, err
+	}
+	return inters[0], nil
+}
+
+// GetInteractives 批量查询互动计数，按 articleIds 的顺序返回，没有互动记录的文章计数为 0
+func (repo *articleInteractiveRepo) GetInteractives(ctx *gin.Context, articleIds []int64) ([]*service.Interactive, error) {
+	var rows []Interactive
+	if len(articleIds) > 0 {
+		err := repo.data.mdb.WithContext(ctx).Where("article_id in ?", articleIds).Find(&rows).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+	rowMap := make(map[int64]*Interactive, len(rows))
+	for i := range rows {
+		rowMap[rows[i].ArticleId] = &rows[i]
+	}
+	res := make([]*service.Interactive, 0, len(articleIds))
+	for _, id := range articleIds {
+		inter := &service.Interactive{ArticleId: id}
+		if row, ok := rowMap[id]; ok {
+			inter.ReadCnt = row.ReadCnt
+			inter.LikeCnt = row.LikeCnt
+			inter.DislikeCnt = row.DislikeCnt
+			inter.CollectCnt = row.CollectCnt
+			inter.CommentCnt = row.CommentCnt
+		}
+		res = append(res, inter)
+	}
+	return res, nil
+}
+
+func (cache *articleInteractiveCache) GetInteractive(ctx *gin.Context, articleId int64) (*service.Interactive, error) {
+	inters, err := cache.GetInteractives(ctx, []int64{articleId})
+	if err != nil {
+		return nil, err
+	}
+	inter, ok := inters[articleId]
+	if !ok {
+		return nil, service.InteractiveNotInCacheErr
+	}
+	return inter, nil
+}
+
+// GetInteractives 批量查询缓存中的互动计数，只返回命中的文章
+func (cache *articleInteractiveCache) GetInteractives(ctx *gin.Context, articleIds []int64) (map[int64]*service.Interactive, error) {
+	res := make(map[int64]*service.Interactive, len(articleIds))
+	if len(articleIds) == 0 {
+		return res, nil
+	}
+	pipe := cache.data.rdb.Pipeline()
+	cmds := make([]*redis.MapStringStringCmd, 0, len(articleIds))
+	for _, id := range articleIds {
+		cmds = append(cmds, pipe.HGetAll(ctx, genArticleInteractiveCacheKey(id)))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+	for i, cmd := range cmds {
+		fields := cmd.Val()
+		// key 不存在时 HGETALL 返回空 map
+		if len(fields) == 0 {
+			continue
+		}
+		// 字段不存在时 ParseInt 出错，计数按 0 处理
+		readCnt, _ := strconv.ParseInt(fields[fieldReadCnt], 10, 64)
+		likeCnt, _ := strconv.ParseInt(fields[fieldLikeCnt], 10, 64)
+		dislikeCnt, _ := strconv.ParseInt(fields[fieldDislikeCnt], 10, 64)
+		collectCnt, _ := strconv.ParseInt(fields[fieldCollectCnt], 10, 64)
+		commentCnt, _ := strconv.ParseInt(fields[fieldCommentCnt], 10, 64)
+		res[articleIds[i]] = &service.Interactive{
+			ArticleId:  articleIds[i],
+			ReadCnt:    readCnt,
+			LikeCnt:    likeCnt,
+			DislikeCnt: dislikeCnt,
+			CollectCnt: collectCnt,
+			CommentCnt: commentCnt,
+		}
+	}
+	return res, nil
+}
+
+// SetInteractives 写入从数据库加载的计数，阅读数会加上缓冲区中尚未刷写的部分，缓存已存在的文章不会被覆盖
+func (cache *articleInteractiveCache) SetInteractives(ctx *gin.Context, inters []*service.Interactive) error {
+	if len(inters) == 0 {
+		return nil
+	}
+	pipe := cache.data.rdb.Pipeline()
+	for _, inter := range inters {
+		ttl := interactiveCacheTTL + time.Duration(rand.Int63n(int64(interactiveCacheJitter)))
+		pipe.Eval(ctx, luaSetCnt, []string{genArticleInteractiveCacheKey(inter.ArticleId), readBufferKey, readFlushingKey},
+			ttl.Milliseconds(), inter.ReadCnt, inter.LikeCnt, inter.CollectCnt, inter.CommentCnt, inter.DislikeCnt,
+			strconv.FormatInt(inter.ArticleId, 10))
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// BufferReadCount 去重窗口内首次阅读时将计数累加到缓冲区，由定时任务批量写入数据库，同时更新展示用的计数缓存
+// 无论是否在窗口内都会记入独立读者，返回本次阅读是否被计数
+func (cache *articleInteractiveCache) BufferReadCount(ctx *gin.Context, articleId int64, viewer string) (bool, error) {
+	res, err := cache.data.rdb.Eval(ctx, luaBufferRead, []string{
+		fmt.Sprintf("article:read_dedup:%d:%s", articleId, viewer),
+		readBufferKey,
+		genArticleInteractiveCacheKey(articleId),
+		genArticleReadersKey(articleId),
+	}, strconv.FormatInt(articleId, 10), viewer, cache.dedupWindow.Milliseconds()).Int64()
+	if err != nil {
+		return false, err
+	}
+	return res == 1, nil
+}
+
+// GetUniqueReaderCounts 批量查询文章的独立读者数，基于 HyperLogLog，存在约 0.81% 的误差
+func (cache *articleInteractiveCache) GetUniqueReaderCounts(ctx *gin.Context, articleIds []int64) (map[int64]int64, error) {
+	res := make(map[int64]int64, len(articleIds))
+	if len(articleIds) == 0 {
+		return res, nil
+	}
+	pipe := cache.data.rdb.Pipeline()
+	cmds := make([]*redis.IntCmd, 0, len(articleIds))
+	for _, id := range articleIds {
+		cmds = append(cmds, pipe.PFCount(ctx, genArticleReadersKey(id)))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+	for i, cmd := range cmds {
+		res[articleIds[i]] = cmd.Val()
+	}
+	return res, nil
+}
+
+// TakeBufferedReadCounts 取出最多 limit 篇文章的待写入计数及其批次号
+// 上一轮的计数未处理完时继续处理，否则将缓冲区整体移入处理中并生成新的批次号，之后的阅读计入新的缓冲区
+func (cache *articleInteractiveCache) TakeBufferedReadCounts(ctx *gin.Context, limit int64) (string, map[int64]int64, error) {
+	batchId, err := cache.data.rdb.Eval(ctx, luaTakeBuffer, []string{readFlushingKey, readBufferKey, readBatchKey},
+		uuid.NewString()).Text()
+	if err != nil {
+		return "", nil, err
+	}
+	if batchId == "" {
+		return "", map[int64]int64{}, nil
+	}
+	fields, _, err := cache.data.rdb.HScan(ctx, readFlushingKey, 0, "", limit).Result()
+	if err != nil {
+		return "", nil, err
+	}
+	counts := make(map[int64]int64, len(fields)/2)
+	var invalid []string
+	// HSCAN 的 count 只是建议值，按 limit 截断
+	for i := 0; i+1 < len(fields) && int64(len(counts)) < limit; i += 2 {
+		articleId, err1 := strconv.ParseInt(fields[i], 10, 64)
+		cnt, err2 := strconv.ParseInt(fields[i+1], 10, 64)
+		if err1 != nil || err2 != nil {
+			invalid = append(invalid, fields[i])
+			continue
+		}
+		counts[articleId] = cnt
+	}
+	// 无法解析的字段直接丢弃，避免每一轮都取到
+	if len(invalid) > 0 {
+		if err = cache.data.rdb.HDel(ctx, readFlushingKey, invalid...).Err(); err != nil {
+			return "", nil, err
+		}
+	}
+	return batchId, counts, nil
+}
+
+// AckBufferedReadCounts 计数写入数据库后从处理中删除，hash 为空时 key 会被自动删除
+func (cache *articleInteractiveCache) AckBufferedReadCounts(ctx *gin.Context, articleIds []int64) error {
+	if len(articleIds) == 0 {
+		return nil
+	}
+	fields := make([]string, 0, len(articleIds))
+	for _, id := range articleIds {
+		fields = append(fields, strconv.FormatInt(id, 10))
+	}
+	return cache.data.rdb.HDel(ctx, readFlushingKey, fields...).Err()
 }
 
-func (cache *articleInteractiveCache) IncrReadCountInCache(ctx *gin.Context, articleId int64) error {
-	return cache.data.rdb.Eval(ctx, luaIncrCnt, []string{genArticleInteractiveCacheKey(articleId)}, fieldReadCnt, 1).Err()
+func (cache *articleInteractiveCache) TryLockReadFlush(ctx *gin.Context, ttl time.Duration) (string, error) {
+	token := uuid.NewString()
+	ok, err := cache.data.rdb.SetNX(ctx, readFlushLockKey, token, ttl).Result()
+	if err != nil || !ok {
+		return "", err
+	}
+	return token, nil
 }
 
-func (cache *articleInteractiveCache) IncrLikeCountInCache(ctx *gin.Context, articleId int64) error {
-	return cache.data.rdb.Eval(ctx, luaIncrCnt, []string{genArticleInteractiveCacheKey(articleId)}, fieldLikeCnt, 1).Err()
+func (cache *articleInteractiveCache) UnlockReadFlush(ctx *gin.Context, token string) error {
+	return cache.data.rdb.Eval(ctx, luaUnlockFlush, []string{readFlushLockKey}, token).Err()
 }
 
-func (cache *articleInteractiveCache) DecrLikeCountInCache(ctx *gin.Context, articleId int64) error {
-	return cache.data.rdb.Eval(ctx, luaIncrCnt, []string{genArticleInteractiveCacheKey(articleId)}, fieldLikeCnt, -1).Err()
+// IncrReactionCountInCache 表态变化时点赞数与踩数在同一个 pipeline 中更新
+func (cache *articleInteractiveCache) IncrReactionCountInCache(ctx *gin.Context, articleId int64, likeDelta int64, dislikeDelta int64) error {
+	key := genArticleInteractiveCacheKey(articleId)
+	pipe := cache.data.rdb.Pipeline()
+	if likeDelta != 0 {
+		pipe.Eval(ctx, luaIncrCnt, []string{key}, fieldLikeCnt, likeDelta)
+	}
+	if dislikeDelta != 0 {
+		pipe.Eval(ctx, luaIncrCnt, []string{key}, fieldDislikeCnt, dislikeDelta)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (cache *articleInteractiveCache) IncrCollectCountInCache(ctx *gin.Context, articleId int64) error {
+	return cache.data.rdb.Eval(ctx, luaIncrCnt, []string{genArticleInteractiveCacheKey(articleId)}, fieldCollectCnt, 1).Err()
+}
+
+func (cache *articleInteractiveCache) DecrCollectCountInCache(ctx *gin.Context, articleId int64) error {
+	return cache.data.rdb.Eval(ctx, luaIncrCnt, []string{genArticleInteractiveCacheKey(articleId)}, fieldCollectCnt, -1).Err()
+}
+
+func (cache *articleInteractiveCache) IncrCommentCountInCache(ctx *gin.Context, articleId int64, delta int64) error {
+	return cache.data.rdb.Eval(ctx, luaIncrCnt, []string{genArticleInteractiveCacheKey(articleId)}, fieldCommentCnt, delta).Err()
 }
 
 func genArticleInteractiveCacheKey(articleId int64) string {
 	return fmt.Sprintf("article:interactive:%d", articleId)
 }
+
+// genArticleReadersKey 记录文章独立读者的 HyperLogLog，不设置过期时间，文章被永久清除时删除
+func genArticleReadersKey(articleId int64) string {
+	return fmt.Sprintf("article:readers:%d", articleId)
+}
@@ -0,0 +1,164 @@
+package data
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"ibook/internal/service"
+	"ibook/pkg/utils/bskit/slice"
+	"time"
+)
+
+type articleLikeRepo struct {
+	data *Data
+}
+
+func NewArticleLikeRepo(data *Data) service.ArticleLikeRepo {
+	return &articleLikeRepo{data: data}
+}
+
+// SetReaction 加锁读取旧记录，只有表态真正变化时才更新计数，重复提交不会重复计数
+func (repo *articleLikeRepo) SetReaction(ctx *gin.Context, userId int64, articleId int64, status service.ReactionStatus) (service.ReactionStatus, error) {
+	now := time.Now().UTC().UnixMilli()
+	prev := service.ReactionNormal
+	err := repo.data.mdb.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 1. 点赞或踩时校验文章处于发表状态，取消表态不做限制，文章撤回后仍可取消
+		if status != service.ReactionNormal {
+			var articleCnt int64
+			err := tx.Model(&ArticleReader{}).
+				Where("id=? and status=?", articleId, service.ArticleStatusPublished).
+				Count(&articleCnt).Error
+			if err != nil {
+				return err
+			}
+			if articleCnt == 0 {
+				return service.ArticleNotExistsErr
+			}
+		}
+		// 2. 写入表态记录。先以未表态状态插入占位记录，已存在时不做处理，再加锁读取并转换状态
+		// 避免记录不存在时加锁读取产生间隙锁，并发的首次表态互相等待而死锁或插入重复记录
+		if status != service.ReactionNormal {
+			err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&LikeRecord{
+				UserId:      userId,
+				ArticleId:   articleId,
+				CreatedTime: now,
+				UpdatedTime: now,
+				Status:      uint8(service.ReactionNormal),
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+		record := &LikeRecord{}
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id=? and article_id=?", userId, articleId).First(record).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// 取消表态时没有记录，无需处理
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		prev = service.ReactionStatus(record.Status)
+		if prev == status {
+			return nil
+		}
+		err = tx.Model(record).Updates(map[string]any{
+			"status":       uint8(status),
+			"updated_time": now,
+		}).Error
+		if err != nil {
+			return err
+		}
+		// 3. 更新点赞数与踩数
+		likeDelta, dislikeDelta := service.ReactionCountDelta(prev, status)
+		return tx.Clauses(clause.OnConflict{
+			DoUpdates: clause.Assignments(map[string]any{
+				"like_cnt":     gorm.Expr("like_cnt + ?", likeDelta),
+				"dislike_cnt":  gorm.Expr("dislike_cnt + ?", dislikeDelta),
+				"updated_time": now,
+			}),
+		}).Create(&Interactive{
+			ArticleId:   articleId,
+			LikeCnt:     max(likeDelta, 0),
+			DislikeCnt:  max(dislikeDelta, 0),
+			CreatedTime: now,
+			UpdatedTime: now,
+		}).Error
+	})
+	return prev, err
+}
+
+func (repo *articleLikeRepo) GetReaction(ctx *gin.Context, userId int64, articleId int64) (service.ReactionStatus, error) {
+	var statuses []uint8
+	err := repo.data.mdb.WithContext(ctx).Model(&LikeRecord{}).
+		Where("user_id=? and article_id=?", userId, articleId).
+		Limit(1).Pluck("status", &statuses).Error
+	if err != nil || len(statuses) == 0 {
+		return service.ReactionNormal, err
+	}
+	return service.ReactionStatus(statuses[0]), nil
+}
+
+type likeRecordRow struct {
+	Id          int64
+	UserId      int64
+	NickName    string
+	ArticleId   int64
+	UpdatedTime int64
+}
+
+func (repo *articleLikeRepo) ListUserLikes(ctx *gin.Context, userId int64, cursor service.ListCursor, limit int64) ([]*service.LikeRecord, error) {
+	var rows []likeRecordRow
+	// 只返回仍处于发表状态的文章，避免已撤回的文章占用分页
+	query := repo.data.mdb.WithContext(ctx).Model(&LikeRecord{}).
+		Select("like_records.id, like_records.user_id, like_records.article_id, like_records.updated_time").
+		Joins("JOIN article_readers ON article_readers.id = like_records.article_id").
+		Where("like_records.user_id=? and like_records.status=? and article_readers.status=?",
+			userId, service.ReactionLike, service.ArticleStatusPublished)
+	err := withLikeCursor(query, cursor).
+		Order("like_records.updated_time desc, like_records.id desc").
+		Limit(int(limit)).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return toServiceLikeRecords(rows), nil
+}
+
+func (repo *articleLikeRepo) ListArticleLikers(ctx *gin.Context, articleId int64, cursor service.ListCursor, limit int64) ([]*service.LikeRecord, error) {
+	var rows []likeRecordRow
+	query := repo.data.mdb.WithContext(ctx).Model(&LikeRecord{}).
+		Select("like_records.id, like_records.user_id, users.nick_name, like_records.article_id, like_records.updated_time").
+		Joins("LEFT JOIN users ON users.id = like_records.user_id").
+		Where("like_records.article_id=? and like_records.status=?", articleId, service.ReactionLike)
+	err := withLikeCursor(query, cursor).
+		Order("like_records.updated_time desc, like_records.id desc").
+		Limit(int(limit)).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return toServiceLikeRecords(rows), nil
+}
+
+func withLikeCursor(query *gorm.DB, cursor service.ListCursor) *gorm.DB {
+	if cursor.IsZero() {
+		return query
+	}
+	return query.Where("like_records.updated_time < ? or (like_records.updated_time = ? and like_records.id < ?)",
+		cursor.Value, cursor.Value, cursor.Id)
+}
+
+func toServiceLikeRecords(rows []likeRecordRow) []*service.LikeRecord {
+	return slice.Map[likeRecordRow, *service.LikeRecord](rows, func(idx int, src likeRecordRow) *service.LikeRecord {
+		return &service.LikeRecord{
+			Id:        src.Id,
+			UserId:    src.UserId,
+			NickName:  src.NickName,
+			ArticleId: src.ArticleId,
+			LikedTime: src.UpdatedTime,
+		}
+	})
+}
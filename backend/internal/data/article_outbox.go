@@ -0,0 +1,316 @@
+package data
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"ibook/internal/service"
+	"ibook/pkg/utils/bskit/slice"
+	"time"
+)
+
+// ArticleOutbox 与作者库修改在同一事务中写入的事件，relay 按 id 顺序将其应用到读者库与缓存
+// 抢占方式与 ArticleSchedule 相同，通过 version 乐观锁与 LeaseUntil 租约避免多副本重复应用
+type ArticleOutbox struct {
+	Id            int64 `gorm:"primaryKey,autoIncrement"`
+	ArticleId     int64 `gorm:"index"`
+	EventType     uint8
+	Payload       string `gorm:"type:mediumtext"`
+	Status        uint8  `gorm:"index:status_retry,priority:1"`
+	NextRetryTime int64  `gorm:"index:status_retry,priority:2"`
+	LeaseUntil    int64
+	Attempts      int64
+	LastErr       string `gorm:"type:varchar(1024)"`
+	Version       int64
+	CreatedTime   int64
+	UpdatedTime   int64
+}
+
+// publishPayload 发表事件携带发表时的文章快照，避免 relay 读到之后被编辑过的草稿
+type publishPayload struct {
+	Article Article
+	Tags    []string
+}
+
+func newPublishPayload(articleR *service.ArticleReader, tags []string) *publishPayload {
+	return &publishPayload{
+		Article: Article{
+			Title:    articleR.Title,
+			Content:  articleR.Content,
+			Format:   uint8(articleR.Format),
+			Category: articleR.Category,
+			Abstract: articleR.Abstract,
+			HTML:     articleR.HTML,
+			AuthorId: articleR.Author.Id,
+			Status:   uint8(articleR.Status),
+		},
+		Tags: tags,
+	}
+}
+
+type statusPayload struct {
+	AuthorId int64
+	Status   uint8
+}
+
+type articleOutboxRepo struct {
+	data *Data
+}
+
+func NewArticleOutboxRepo(data *Data) service.ArticleOutboxRepo {
+	return &articleOutboxRepo{data: data}
+}
+
+// appendOutboxEvent 在调用方的事务中写入事件
+func appendOutboxEvent(tx *gorm.DB, articleId int64, eventType service.OutboxEventType, payload any) error {
+	jdata, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("json编码出错：%w", err)
+	}
+	now := time.Now().UTC().UnixMilli()
+	return tx.Create(&ArticleOutbox{
+		ArticleId:     articleId,
+		EventType:     uint8(eventType),
+		Payload:       string(jdata),
+		Status:        uint8(service.OutboxStatusPending),
+		NextRetryTime: now,
+		CreatedTime:   now,
+		UpdatedTime:   now,
+	}).Error
+}
+
+// cancelOutboxEvents 在调用方的事务中取消文章所有未完成的事件，用于删除文章
+func cancelOutboxEvents(tx *gorm.DB, articleId int64) error {
+	return tx.Model(&ArticleOutbox{}).
+		Where("article_id=? and status in ?", articleId,
+			[]service.OutboxStatus{service.OutboxStatusPending, service.OutboxStatusRunning, service.OutboxStatusDead}).
+		Updates(map[string]any{
+			"status":       service.OutboxStatusCanceled,
+			"version":      gorm.Expr("version + 1"),
+			"updated_time": time.Now().UTC().UnixMilli(),
+		}).Error
+}
+
+// ListDueEvents 查询到期待应用的事件，以及应用中但租约已过期的事件
+// 同一篇文章存在更早的未完成事件（含死信）时跳过，保证事件按写入顺序应用
+func (repo *articleOutboxRepo) ListDueEvents(ctx *gin.Context, articleId int64, now int64, limit int64) ([]*service.OutboxEvent, error) {
+	var events []ArticleOutbox
+	query := repo.data.mdb.WithContext(ctx).Model(&ArticleOutbox{}).
+		Where("(status=? and next_retry_time<=?) or (status=? and lease_until<?)",
+			service.OutboxStatusPending, now, service.OutboxStatusRunning, now).
+		Where("not exists (select 1 from article_outboxes prev where prev.article_id = article_outboxes.article_id "+
+			"and prev.id < article_outboxes.id and prev.status in ?)",
+			[]service.OutboxStatus{service.OutboxStatusPending, service.OutboxStatusRunning, service.OutboxStatusDead})
+	if articleId > 0 {
+		query = query.Where("article_id=?", articleId)
+	}
+	err := query.Order("id asc").Limit(int(limit)).Find(&events).Error
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[ArticleOutbox, *service.OutboxEvent](events, func(idx int, src ArticleOutbox) *service.OutboxEvent {
+		return toServiceOutboxEvent(&src)
+	}), nil
+}
+
+// ClaimEvent 抢占事件的应用权，version 不一致说明已被其他副本抢占或已被取消
+func (repo *articleOutboxRepo) ClaimEvent(ctx *gin.Context, event *service.OutboxEvent, now int64, leaseUntil int64) (bool, error) {
+	res := repo.data.mdb.WithContext(ctx).Model(&ArticleOutbox{}).
+		Where("id=? and version=?", event.Id, event.Version).
+		Where("(status=? and next_retry_time<=?) or (status=? and lease_until<?)",
+			service.OutboxStatusPending, now, service.OutboxStatusRunning, now).
+		Updates(map[string]any{
+			"status":       service.OutboxStatusRunning,
+			"lease_until":  leaseUntil,
+			"attempts":     gorm.Expr("attempts + 1"),
+			"version":      gorm.Expr("version + 1"),
+			"updated_time": now,
+		})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return false, nil
+	}
+	event.Status = service.OutboxStatusRunning
+	event.Attempts++
+	event.Version++
+	return true, nil
+}
+
+// ApplyEvent 在事务中将事件写入读者库，并在读者库记录已应用的事件 id
+// 读者库的 sync_seq 大于事件 id 说明已应用过更新的事件，等于时说明数据库已写入、只需重做缓存更新
+func (repo *articleOutboxRepo) ApplyEvent(ctx *gin.Context, event *service.OutboxEvent) error {
+	ob := &ArticleOutbox{}
+	if err := repo.data.mdb.WithContext(ctx).Where("id=?", event.Id).First(ob).Error; err != nil {
+		return err
+	}
+	var (
+		publish  publishPayload
+		status   statusPayload
+		authorId int64
+	)
+	switch service.OutboxEventType(ob.EventType) {
+	case service.OutboxEventPublish:
+		if err := json.Unmarshal([]byte(ob.Payload), &publish); err != nil {
+			return fmt.Errorf("json解码出错：%w", err)
+		}
+		authorId = publish.Article.AuthorId
+	case service.OutboxEventStatus:
+		if err := json.Unmarshal([]byte(ob.Payload), &status); err != nil {
+			return fmt.Errorf("json解码出错：%w", err)
+		}
+		authorId = status.AuthorId
+	default:
+		return fmt.Errorf("未知的事件类型：%d", ob.EventType)
+	}
+
+	skipped := false
+	err := repo.data.mdb.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 1. 作者库中文章已删除时不再写入读者库，与删除操作互斥
+		var authorCnt int64
+		err := tx.Model(&ArticleAuthor{}).Clauses(clause.Locking{Strength: "SHARE"}).
+			Where("id=? and deleted_time=0", ob.ArticleId).
+			Count(&authorCnt).Error
+		if err != nil {
+			return err
+		}
+		if authorCnt == 0 {
+			skipped = true
+			return nil
+		}
+		// 2. 已应用过该事件或更新的事件时跳过
+		reader := &ArticleReader{}
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id", "sync_seq").
+			Where("id=?", ob.ArticleId).First(reader).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if reader.SyncSeq > ob.Id {
+			skipped = true
+			return nil
+		}
+		if reader.SyncSeq == ob.Id {
+			return nil
+		}
+		// 3. 写入读者库，同时更新 updated_time 使搜索索引等下游能感知到变化
+		now := time.Now().UTC().UnixMilli()
+		if service.OutboxEventType(ob.EventType) == service.OutboxEventStatus {
+			return tx.Model(&ArticleReader{}).Where("id=?", ob.ArticleId).Updates(map[string]any{
+				"status":       status.Status,
+				"updated_time": now,
+				"sync_seq":     ob.Id,
+			}).Error
+		}
+		a := publish.Article
+		err = tx.Clauses(clause.OnConflict{
+			DoUpdates: clause.Assignments(map[string]any{
+				"title":        a.Title,
+				"content":      a.Content,
+				"format":       a.Format,
+				"category":     a.Category,
+				"abstract":     a.Abstract,
+				"html":         a.HTML,
+				"updated_time": now,
+				"status":       a.Status,
+				"sync_seq":     ob.Id,
+			}),
+		}).Create(&ArticleReader{
+			Article: Article{
+				Id:          ob.ArticleId,
+				Title:       a.Title,
+				Content:     a.Content,
+				Format:      a.Format,
+				Category:    a.Category,
+				Abstract:    a.Abstract,
+				HTML:        a.HTML,
+				AuthorId:    a.AuthorId,
+				CreatedTime: now,
+				UpdatedTime: now,
+				Status:      a.Status,
+			},
+			SyncSeq: ob.Id,
+		}).Error
+		if err != nil {
+			return fmt.Errorf("更新或插入读者库文章时出错：%w", err)
+		}
+		return replaceReaderTags(tx, ob.ArticleId, publish.Tags)
+	})
+	if err != nil || skipped {
+		return err
+	}
+	// 4. 事务提交后更新缓存，失败时事件会被重试，此时只重做这一步
+	return repo.refreshCache(ctx, ob, &status, authorId)
+}
+
+func (repo *articleOutboxRepo) refreshCache(ctx *gin.Context, ob *ArticleOutbox, status *statusPayload, authorId int64) error {
+	if service.OutboxEventType(ob.EventType) == service.OutboxEventPublish {
+		article := &ArticleReader{}
+		// 缓存的内容以读者库为准，created_time 只有读者库中有
+		if err := repo.data.mdb.WithContext(ctx).Where("id=?", ob.ArticleId).First(article).Error; err != nil {
+			return err
+		}
+		jdata, err := json.Marshal(article)
+		if err != nil {
+			return fmt.Errorf("json编码出错：%w", err)
+		}
+		if err = repo.data.rdb.Set(ctx, genArticleCacheKey(ob.ArticleId), jdata, time.Minute*10).Err(); err != nil {
+			return fmt.Errorf("设置文章缓存时出错：%w", err)
+		}
+	} else if status.Status == service.ArticleStatusPrivate {
+		// 状态设为私有时需要从缓存中删除掉对应的文章
+		if err := repo.data.rdb.Del(ctx, genArticleCacheKey(ob.ArticleId)).Err(); err != nil {
+			return fmt.Errorf("缓存删除异常: %w", err)
+		}
+	}
+	if err := repo.data.rdb.Del(ctx, firstPageKey(authorId), firstPageKey(0)).Err(); err != nil {
+		return fmt.Errorf("列表缓存删除异常: %w", err)
+	}
+	return nil
+}
+
+// FinishEvent 记录应用结果，status 为 pending 时表示稍后在 retryAt 重试
+func (repo *articleOutboxRepo) FinishEvent(ctx *gin.Context, event *service.OutboxEvent, status service.OutboxStatus, lastErr string, retryAt int64) error {
+	updates := map[string]any{
+		"status":       status,
+		"last_err":     truncateRunes(lastErr, lastErrMaxLen),
+		"version":      gorm.Expr("version + 1"),
+		"updated_time": time.Now().UTC().UnixMilli(),
+	}
+	if status == service.OutboxStatusPending {
+		updates["next_retry_time"] = retryAt
+	}
+	return repo.data.mdb.WithContext(ctx).Model(&ArticleOutbox{}).
+		Where("id=? and version=?", event.Id, event.Version).
+		Updates(updates).Error
+}
+
+func (repo *articleOutboxRepo) RequeueDeadEvents(ctx *gin.Context) (int64, error) {
+	now := time.Now().UTC().UnixMilli()
+	res := repo.data.mdb.WithContext(ctx).Model(&ArticleOutbox{}).
+		Where("status=?", service.OutboxStatusDead).
+		Updates(map[string]any{
+			"status":          service.OutboxStatusPending,
+			"attempts":        0,
+			"next_retry_time": now,
+			"version":         gorm.Expr("version + 1"),
+			"updated_time":    now,
+		})
+	return res.RowsAffected, res.Error
+}
+
+func toServiceOutboxEvent(src *ArticleOutbox) *service.OutboxEvent {
+	return &service.OutboxEvent{
+		Id:        src.Id,
+		ArticleId: src.ArticleId,
+		Type:      service.OutboxEventType(src.EventType),
+		Status:    service.OutboxStatus(src.Status),
+		Attempts:  src.Attempts,
+		LastErr:   src.LastErr,
+		Version:   src.Version,
+	}
+}
@@ -5,7 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm/clause"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
 	"ibook/internal/service"
 	"ibook/pkg/utils/bskit/slice"
 	"ibook/pkg/utils/logger"
@@ -18,8 +19,13 @@ var (
 	CacheUnknownErr = fmt.Errorf("缓存服务异常")
 )
 
+// firstPageSize 首页列表缓存的文章 id 数量
+const firstPageSize = 100
+
 type ArticleReader struct {
 	Article
+	// SyncSeq 最近一次应用到读者库的发件箱事件 id，用于保证事件幂等且按顺序应用
+	SyncSeq int64
 }
 
 type articleReaderRepo struct {
@@ -27,86 +33,150 @@ type articleReaderRepo struct {
 	logger logger.Logger
 }
 
-func (repo *articleReaderRepo) UpsertArticle(ctx *gin.Context, article *service.ArticleReader) error {
-	now := time.Now().UnixMilli()
-	newArticle := &ArticleReader{
-		Article{
-			Id:          article.Id,
-			Title:       article.Title,
-			Content:     article.Content,
-			AuthorId:    article.Author.Id,
-			CreatedTime: now,
-			UpdatedTime: now,
-			Status:      uint8(article.Status),
-		},
-	}
-	err := repo.db.mdb.Clauses(clause.OnConflict{
-		DoUpdates: clause.Assignments(map[string]interface{}{
-			"title":        newArticle.Title,
-			"content":      newArticle.Content,
-			"updated_time": now,
-			"status":       newArticle.Status,
-		}),
-	}).Create(newArticle).Error
-	if err != nil {
-		return fmt.Errorf("更新或插入读者库文章时出错：%w", err)
+func (repo *articleReaderRepo) GetPubArticleById(ctx *gin.Context, articleId int64) (*service.Article, error) {
+	l := logger.TagCtxLogger(ctx, repo.logger, "articleReaderRepo - GetPubArticleById")
+	// 1. 先查缓存，缓存中的文章为最近一次发表时写入的内容
+	article := &ArticleReader{}
+	cacheData, err := repo.db.rdb.Get(ctx, genArticleCacheKey(articleId)).Bytes()
+	if err == nil && json.Unmarshal(cacheData, article) == nil {
+		if article.Status != service.ArticleStatusPublished {
+			return nil, service.ArticleNotExistsErr
+		}
+		return toServiceArticle(&article.Article), nil
+	}
+	if err != nil && !errors.Is(err, redis.Nil) {
+		l.Warn("文章Redis缓存查询失败", logger.Field{
+			Key:   "详情",
+			Value: err,
+		})
 	}
-	jdata, err := json.Marshal(newArticle)
+	// 2. 缓存未命中，回源到读者库
+	err = repo.db.mdb.WithContext(ctx).
+		Where("id=? and status=?", articleId, service.ArticleStatusPublished).
+		First(article).Error
 	if err != nil {
-		return fmt.Errorf("json编码出错：%w", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, service.ArticleNotExistsErr
+		}
+		return nil, err
+	}
+	// 3. 回写缓存，失败时不影响本次查询
+	jdata, err := json.Marshal(article)
+	if err == nil {
+		err = repo.db.rdb.Set(ctx, genArticleCacheKey(articleId), jdata, time.Minute*10).Err()
 	}
-	_, err = repo.db.rdb.Set(ctx, genArticleCacheKey(newArticle.Id, newArticle.AuthorId), jdata, time.Minute*10).Result()
 	if err != nil {
-		return fmt.Errorf("设置文章缓存时出错：%w", err)
+		l.Warn("文章缓存回写失败", logger.Field{
+			Key:   "详情",
+			Value: err,
+		})
 	}
-	return nil
+	return toServiceArticle(&article.Article), nil
 }
 
-func (repo *articleReaderRepo) UpdateStatusById(ctx *gin.Context, articleId int64, authorId int64, status uint8) error {
-	res := repo.db.mdb.WithContext(ctx).Model(&ArticleReader{}).
-		Where("id=? and author_id=?", articleId, authorId).
-		Updates(map[string]any{
-			"status": status,
+func (repo *articleReaderRepo) ListAll(ctx *gin.Context, cursor service.ListCursor, limit int64) ([]*service.Article, error) {
+	return repo.listPubArticles(ctx, 0, cursor, limit)
+}
+
+func (repo *articleReaderRepo) ListById(ctx *gin.Context, userId int64, cursor service.ListCursor, limit int64) ([]*service.Article, error) {
+	return repo.listPubArticles(ctx, userId, cursor, limit)
+}
+
+// GetPubArticlesByIds 按 ids 的顺序批量获取已发表的文章，未发表或不存在的文章会被跳过
+func (repo *articleReaderRepo) GetPubArticlesByIds(ctx *gin.Context, ids []int64) ([]*service.Article, error) {
+	l := logger.TagCtxLogger(ctx, repo.logger, "articleReaderRepo - GetPubArticlesByIds")
+	if len(ids) == 0 {
+		return []*service.Article{}, nil
+	}
+	// 1. 先从文章详情缓存中批量获取
+	found := make(map[int64]*Article, len(ids))
+	keys := slice.Map[int64, string](ids, func(idx int, src int64) string {
+		return genArticleCacheKey(src)
+	})
+	cacheRes, err := repo.db.rdb.MGet(ctx, keys...).Result()
+	if err != nil {
+		l.Warn("文章Redis缓存批量查询失败", logger.Field{
+			Key:   "详情",
+			Value: err,
 		})
-	if res.Error != nil {
-		return res.Error
+		cacheRes = nil
 	}
-	if status == service.ArticleStatusPrivate {
-		// 状态设为私有时需要从缓存中删除掉对应的文章
-		_, err := repo.db.rdb.Del(ctx, genArticleCacheKey(articleId, authorId)).Result()
+	for _, val := range cacheRes {
+		str, ok := val.(string)
+		if !ok {
+			continue
+		}
+		article := &ArticleReader{}
+		if json.Unmarshal([]byte(str), article) == nil {
+			found[article.Id] = &article.Article
+		}
+	}
+	// 2. 缓存未命中的部分回源到读者库，并回写缓存
+	missIds := slice.FilterMap[int64, int64](ids, func(idx int, src int64) (int64, bool) {
+		_, ok := found[src]
+		return src, !ok
+	})
+	if len(missIds) > 0 {
+		var dbRes []ArticleReader
+		err = repo.db.mdb.WithContext(ctx).Where("id in ?", missIds).Find(&dbRes).Error
 		if err != nil {
-			return fmt.Errorf("缓存删除异常: %w", err)
-		}
-	}
-	if res.RowsAffected == 0 {
-		return fmt.Errorf("文章已隐藏或作者不对应")
-	}
-	return nil
-}
-
-func (repo *articleReaderRepo) ListAll(ctx *gin.Context, offset int64, limit int64) ([]*service.Article, error) {
-	l := logger.TagCtxLogger(ctx, repo.logger, "articleReaderRepo - ListAll")
-	// 1. 实时查询当前的文章列表 id
-	//var articleIds []int
-	//status := repo.db.mdb.WithContext(ctx).Model(&ArticleReader{}).Select("id").
-	//	Order("updated_time desc").Offset(int(offset)).Limit(int(limit)).Find(&articleIds)
-	//if status.Error != nil {
-	//	l.Error("查询读者库文章列表时出错", logger.Field{
-	//		Key:   "详情",
-	//		Value: status.Error,
-	//	})
-	//}
-	//if len(articleIds) == 0 {
-	//	return []*service.Article{}, nil
-	//}
-	// 2. 如果当前
-	if offset+limit <= 100 {
-		// 走缓存查询
-		cacheRes, err := repo.listArticlesInCache(ctx, 0)
-		// 缓存查询成功
-		if err == nil {
-			return cacheRes, nil
+			return nil, err
 		}
+		pipe := repo.db.rdb.Pipeline()
+		for i := range dbRes {
+			found[dbRes[i].Id] = &dbRes[i].Article
+			if jdata, er := json.Marshal(&dbRes[i]); er == nil {
+				pipe.Set(ctx, genArticleCacheKey(dbRes[i].Id), jdata, time.Minute*10)
+			}
+		}
+		if _, err = pipe.Exec(ctx); err != nil {
+			l.Warn("文章缓存批量回写失败", logger.Field{
+				Key:   "详情",
+				Value: err,
+			})
+		}
+	}
+	return slice.FilterMap[int64, *service.Article](ids, func(idx int, src int64) (*service.Article, bool) {
+		article, ok := found[src]
+		if !ok || article.Status != service.ArticleStatusPublished {
+			return nil, false
+		}
+		return toServiceArticle(article), true
+	}), nil
+}
+
+func NewArticleReaderRepo(db *Data, myLogger logger.Logger) service.ArticleReaderRepo {
+	return &articleReaderRepo{db: db, logger: myLogger}
+}
+
+// firstPageKey 文章列表首页缓存，author 为 0 时表示全站列表
+func firstPageKey(author int64) string {
+	return fmt.Sprintf("article:first_page:%d", author)
+}
+
+// listEntry 首页缓存中只保存文章 id 与排序字段，文章内容从文章详情缓存中获取
+type listEntry struct {
+	Id          int64 `json:"id"`
+	UpdatedTime int64 `json:"utime"`
+}
+
+// after 判断 entry 在 (updated_time desc, id desc) 的排序下是否位于 cursor 之后
+func (entry listEntry) after(cursor service.ListCursor) bool {
+	if cursor.IsZero() {
+		return true
+	}
+	return entry.UpdatedTime < cursor.Value || (entry.UpdatedTime == cursor.Value && entry.Id < cursor.Id)
+}
+
+// listPubArticles 按 (updated_time, id) 倒序分页查询已发表的文章，authorId <= 0 时查询全站文章
+// 前 firstPageSize 篇文章的 id 会被缓存，落在该范围内的分页请求不访问 DB
+func (repo *articleReaderRepo) listPubArticles(ctx *gin.Context, authorId int64, cursor service.ListCursor, limit int64) ([]*service.Article, error) {
+	l := logger.TagCtxLogger(ctx, repo.logger, "articleReaderRepo - listPubArticles")
+	if authorId < 0 {
+		authorId = 0
+	}
+	entries, err := repo.listEntriesInCache(ctx, authorId)
+	if err != nil {
 		// 缓存查询失败，且缓存服务异常
 		if !errors.Is(err, NotInCacheErr) {
 			// 考虑降级
@@ -116,83 +186,143 @@ func (repo *articleReaderRepo) ListAll(ctx *gin.Context, offset int64, limit int
 			})
 			return nil, fmt.Errorf("缓存服务异常，触发降级")
 		}
+		// 缓存中无数据，从 DB 加载首页并回写缓存
+		entries, err = repo.loadFirstPageEntries(ctx, authorId)
+		if err != nil {
+			return nil, err
+		}
 	}
-	// 缓存中无数据，需要进入 DB 查询
+	window := make([]int64, 0, limit)
+	for _, entry := range entries {
+		if int64(len(window)) >= limit {
+			break
+		}
+		if entry.after(cursor) {
+			window = append(window, entry.Id)
+		}
+	}
+	// 首页缓存能覆盖本次请求的范围（或者首页之后已无更多文章）时直接按 id 获取文章
+	if int64(len(window)) == limit || len(entries) < firstPageSize {
+		articles, err := repo.GetPubArticlesByIds(ctx, window)
+		if err != nil {
+			return nil, err
+		}
+		if len(articles) == len(window) {
+			return articles, nil
+		}
+		// 首页缓存中有文章已被撤回或删除，直接返回会少于一页，调用方据此判断是否还有下一页时会出错
+		// 删除过时的首页缓存，本次请求回源 DB
+		if err = repo.db.rdb.Del(ctx, firstPageKey(authorId)).Err(); err != nil {
+			l.Warn("删除过时的首页缓存失败", logger.Field{
+				Key:   "详情",
+				Value: err,
+			})
+		}
+	}
+	// 超出首页缓存范围的深分页，或首页缓存已过时，按游标进入 DB 查询
 	var dbRes []Article
-	if offset+limit <= 100 {
-		offset = 0
-		limit = 100
-	}
-	status := repo.db.mdb.WithContext(ctx).Model(&Article{}).
-		Offset(int(offset)).
-		Limit(int(limit)).
-		Order("updated_time desc").Find(&dbRes)
-	if status.Error != nil {
+	query := repo.pubArticlesQuery(ctx, authorId)
+	if !cursor.IsZero() {
+		query = query.Where("updated_time < ? or (updated_time = ? and id < ?)", cursor.Value, cursor.Value, cursor.Id)
+	}
+	err = query.Order("updated_time desc, id desc").Limit(int(limit)).Find(&dbRes).Error
+	if err != nil {
 		l.Error("MySQL 查询时出错", logger.Field{
 			Key:   "详情",
-			Value: status.Error,
+			Value: err,
 		})
-		return nil, status.Error
+		return nil, err
 	}
-	if status.RowsAffected == 0 {
-		return []*service.Article{}, nil
+	return slice.Map[Article, *service.Article](dbRes, func(idx int, src Article) *service.Article {
+		return toServiceArticle(&src)
+	}), nil
+}
+
+// ListByFilter 按标签、分类筛选已发表的文章，筛选结果不走首页缓存，直接按游标查询 DB
+func (repo *articleReaderRepo) ListByFilter(ctx *gin.Context, filter service.ArticleListFilter, cursor service.ListCursor, limit int64) ([]*service.Article, error) {
+	query := repo.db.mdb.WithContext(ctx).Model(&ArticleReader{}).
+		Select("article_readers.*").
+		Where("article_readers.status=?", service.ArticleStatusPublished)
+	if filter.AuthorId > 0 {
+		query = query.Where("article_readers.author_id=?", filter.AuthorId)
 	}
-	res := slice.Map[Article, *service.Article](dbRes, func(idx int, src Article) *service.Article {
-		return &service.Article{
-			Id:       src.Id,
-			Title:    src.Title,
-			Abstract: "",
-			Content:  src.Content,
-			Status:   service.ArticleStatus(src.Status),
-			Author: service.Author{
-				Id:   src.AuthorId,
-				Name: "功能未完善",
-			},
-			UpdatedTime: src.UpdatedTime,
-			CreatedTime: src.CreatedTime,
+	if len(filter.AuthorIds) > 0 {
+		query = query.Where("article_readers.author_id in ?", filter.AuthorIds)
+	}
+	if filter.Category != "" {
+		query = query.Where("article_readers.category=?", filter.Category)
+	}
+	if filter.Tag != "" {
+		tagId, err := getTagId(repo.db.mdb.WithContext(ctx), filter.Tag)
+		if err != nil {
+			return nil, err
 		}
-	})
-	// 如果当前查询的是 top100 的热点列表，则将数据进行缓存
-	if offset == 0 && limit == 100 {
-		go func() {
-			// 异步将结果写入缓存
-			bs, err := json.Marshal(res)
-			if err != nil {
-				// 序列化失败
-				l.Error("json序列化失败")
-				return
-			}
-			_, err = repo.db.rdb.Set(ctx, "article:first_page:0", bs, time.Minute*10).Result()
-			if err != nil {
-				// 回写缓存失败
-				l.Error("缓存回写失败", logger.Field{
-					Key:   "详情",
-					Value: err,
-				})
-			}
-			// 预写缓存
-		}()
+		if tagId == 0 {
+			return []*service.Article{}, nil
+		}
+		query = query.Joins("join reader_article_tags on reader_article_tags.article_id = article_readers.id").
+			Where("reader_article_tags.tag_id=?", tagId)
 	}
-	return res, nil
-}
-
-func (repo *articleReaderRepo) ListById(ctx *gin.Context, userId int64, offset int64, limit int64) ([]*service.Article, error) {
-	panic("implement me")
+	if !cursor.IsZero() {
+		query = query.Where("article_readers.updated_time < ? or (article_readers.updated_time = ? and article_readers.id < ?)",
+			cursor.Value, cursor.Value, cursor.Id)
+	}
+	var dbRes []Article
+	err := query.Order("article_readers.updated_time desc, article_readers.id desc").
+		Limit(int(limit)).Find(&dbRes).Error
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[Article, *service.Article](dbRes, func(idx int, src Article) *service.Article {
+		return toServiceArticle(&src)
+	}), nil
 }
 
-func NewArticleReaderRepo(db *Data, myLogger logger.Logger) service.ArticleReaderRepo {
-	return &articleReaderRepo{db: db, logger: myLogger}
+func (repo *articleReaderRepo) pubArticlesQuery(ctx *gin.Context, authorId int64) *gorm.DB {
+	query := repo.db.mdb.WithContext(ctx).Model(&ArticleReader{}).
+		Where("status=?", service.ArticleStatusPublished)
+	if authorId > 0 {
+		query = query.Where("author_id=?", authorId)
+	}
+	return query
 }
 
-func firstPageKey(author int64) string {
-	return fmt.Sprintf("article:first_page:%d", author)
+func (repo *articleReaderRepo) loadFirstPageEntries(ctx *gin.Context, authorId int64) ([]listEntry, error) {
+	l := logger.TagCtxLogger(ctx, repo.logger, "articleReaderRepo - loadFirstPageEntries")
+	var entries []listEntry
+	err := repo.pubArticlesQuery(ctx, authorId).
+		Select("id", "updated_time").
+		Order("updated_time desc, id desc").
+		Limit(firstPageSize).Find(&entries).Error
+	if err != nil {
+		l.Error("MySQL 查询时出错", logger.Field{
+			Key:   "详情",
+			Value: err,
+		})
+		return nil, err
+	}
+	bs, err := json.Marshal(entries)
+	if err == nil {
+		err = repo.db.rdb.Set(ctx, firstPageKey(authorId), bs, time.Minute*10).Err()
+	}
+	if err != nil {
+		// 回写缓存失败
+		l.Error("缓存回写失败", logger.Field{
+			Key:   "详情",
+			Value: err,
+		})
+	}
+	return entries, nil
 }
 
-func (repo *articleReaderRepo) listArticlesInCache(ctx *gin.Context, userId int64) ([]*service.Article, error) {
-	l := logger.TagCtxLogger(ctx, repo.logger, "articleReaderRepo - listArticlesInCache")
+func (repo *articleReaderRepo) listEntriesInCache(ctx *gin.Context, userId int64) ([]listEntry, error) {
+	l := logger.TagCtxLogger(ctx, repo.logger, "articleReaderRepo - listEntriesInCache")
 	// 先查缓存
 	cacheData, err := repo.db.rdb.Get(ctx, firstPageKey(userId)).Bytes()
 	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, NotInCacheErr
+		}
 		// 考虑降级
 		l.Warn("文章Redis缓存查询失败", logger.Field{
 			Key:   "详情",
@@ -200,15 +330,29 @@ func (repo *articleReaderRepo) listArticlesInCache(ctx *gin.Context, userId int6
 		})
 		return nil, err
 	}
-	var articles []*service.Article
-	err = json.Unmarshal(cacheData, &articles)
-	// 查询成功
-	if len(articles) > 0 {
-		return articles, nil
+	var entries []listEntry
+	if err = json.Unmarshal(cacheData, &entries); err != nil {
+		return nil, NotInCacheErr
 	}
-	return nil, NotInCacheErr
+	return entries, nil
 }
 
-func genArticleCacheKey(articleId, authorId int64) string {
-	return fmt.Sprintf("article:%s:%s", strconv.Itoa(int(articleId)), strconv.Itoa(int(authorId)))
+func genArticleCacheKey(articleId int64) string {
+	return fmt.Sprintf("article:%s", strconv.Itoa(int(articleId)))
+}
+
+func toServiceArticle(src *Article) *service.Article {
+	return &service.Article{
+		Id:          src.Id,
+		Title:       src.Title,
+		Abstract:    src.Abstract,
+		Content:     src.Content,
+		Format:      service.ContentFormat(src.Format),
+		Category:    src.Category,
+		HTML:        src.HTML,
+		Status:      service.ArticleStatus(src.Status),
+		Author:      service.Author{Id: src.AuthorId},
+		UpdatedTime: src.UpdatedTime,
+		CreatedTime: src.CreatedTime,
+	}
 }
@@ -0,0 +1,185 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"ibook/internal/service"
+	"time"
+)
+
+const reconcileLockKey = "article:reconcile:lock"
+
+// reconcileArticleRow 比对时只取需要的列，正文在数据库中计算哈希，避免传输全文
+type reconcileArticleRow struct {
+	Id          int64
+	AuthorId    int64
+	Status      uint8
+	Title       string
+	ContentHash string
+	Version     int64
+	DeletedTime int64
+}
+
+type articleReconcileRepo struct {
+	data *Data
+}
+
+func NewArticleReconcileRepo(data *Data) service.ArticleReconcileRepo {
+	return &articleReconcileRepo{data: data}
+}
+
+func (repo *articleReconcileRepo) ScanReconcileRows(ctx *gin.Context, afterId int64, limit int64) ([]*service.ReconcileRow, int64, error) {
+	db := repo.data.mdb.WithContext(ctx)
+	// 1. 取两库中 id 的并集，读者库中独有的 id 即为孤立文章
+	var ids []int64
+	err := db.Raw("select id from (select id from article_authors where id>? "+
+		"union select id from article_readers where id>?) t order by id limit ?",
+		afterId, afterId, limit).Scan(&ids).Error
+	if err != nil {
+		return nil, afterId, err
+	}
+	if len(ids) == 0 {
+		return nil, afterId, nil
+	}
+	// 2. 分别查询两库中的对比数据
+	var authors, readers []reconcileArticleRow
+	err = db.Model(&ArticleAuthor{}).
+		Select("id, author_id, status, title, md5(content) as content_hash, version, deleted_time").
+		Where("id in ?", ids).Scan(&authors).Error
+	if err != nil {
+		return nil, afterId, err
+	}
+	err = db.Model(&ArticleReader{}).
+		Select("id, author_id, status, title, md5(content) as content_hash").
+		Where("id in ?", ids).Scan(&readers).Error
+	if err != nil {
+		return nil, afterId, err
+	}
+	// 3. 查询仍有未完成发件箱事件的文章
+	var syncing []int64
+	err = db.Model(&ArticleOutbox{}).Distinct("article_id").
+		Where("article_id in ? and status in ?", ids,
+			[]service.OutboxStatus{service.OutboxStatusPending, service.OutboxStatusRunning, service.OutboxStatusDead}).
+		Pluck("article_id", &syncing).Error
+	if err != nil {
+		return nil, afterId, err
+	}
+
+	rows := make(map[int64]*service.ReconcileRow, len(ids))
+	res := make([]*service.ReconcileRow, 0, len(ids))
+	for _, id := range ids {
+		row := &service.ReconcileRow{ArticleId: id}
+		rows[id] = row
+		res = append(res, row)
+	}
+	for _, a := range authors {
+		row := rows[a.Id]
+		row.AuthorId = a.AuthorId
+		row.AuthorExists = true
+		row.AuthorDeleted = a.DeletedTime > 0
+		row.AuthorStatus = service.ArticleStatus(a.Status)
+		row.AuthorTitle = a.Title
+		row.AuthorContentHash = a.ContentHash
+		row.AuthorVersion = a.Version
+	}
+	for _, r := range readers {
+		row := rows[r.Id]
+		if !row.AuthorExists {
+			row.AuthorId = r.AuthorId
+		}
+		row.ReaderExists = true
+		row.ReaderStatus = service.ArticleStatus(r.Status)
+		row.ReaderTitle = r.Title
+		row.ReaderContentHash = r.ContentHash
+	}
+	for _, id := range syncing {
+		if row, ok := rows[id]; ok {
+			row.Syncing = true
+		}
+	}
+	return res, ids[len(ids)-1], nil
+}
+
+func (repo *articleReconcileRepo) EnqueueRepair(ctx *gin.Context, articleId int64, version int64, articleR *service.ArticleReader) error {
+	return repo.data.mdb.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 锁住作者库中的文章，保证写入的事件基于比对时的版本
+		current := &ArticleAuthor{}
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id=? and deleted_time=0", articleId).First(current).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return service.ArticleNotExistsErr
+		}
+		if err != nil {
+			return err
+		}
+		if current.Version != version {
+			return service.ArticleVersionConflictErr
+		}
+		if articleR == nil {
+			return appendOutboxEvent(tx, articleId, service.OutboxEventStatus, &statusPayload{
+				AuthorId: current.AuthorId,
+				Status:   current.Status,
+			})
+		}
+		if current.Status != uint8(service.ArticleStatusPublished) {
+			return service.ArticleVersionConflictErr
+		}
+		tags, err := listAuthorTags(tx, articleId)
+		if err != nil {
+			return err
+		}
+		return appendOutboxEvent(tx, articleId, service.OutboxEventPublish, newPublishPayload(articleR, tags))
+	})
+}
+
+func (repo *articleReconcileRepo) RemoveOrphanReader(ctx *gin.Context, articleId int64) error {
+	reader := &ArticleReader{}
+	err := repo.data.mdb.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 与恢复、发表互斥，作者库中文章仍有效时不删除
+		author := &ArticleAuthor{}
+		err := tx.Clauses(clause.Locking{Strength: "SHARE"}).
+			Select("id", "deleted_time").
+			Where("id=?", articleId).First(author).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		deleted := err == nil
+		if deleted && author.DeletedTime == 0 {
+			return fmt.Errorf("作者库中文章仍存在，不是孤立文章")
+		}
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id", "author_id").
+			Where("id=?", articleId).First(reader).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if deleted {
+			// 与删除文章一致，设为私有并更新 updated_time，使各副本的搜索索引增量同步能感知到
+			err = tx.Model(&ArticleReader{}).Where("id=?", articleId).Updates(map[string]any{
+				"status":       service.ArticleStatusPrivate,
+				"updated_time": time.Now().UTC().UnixMilli(),
+			}).Error
+		} else {
+			err = tx.Where("id=?", articleId).Delete(&ArticleReader{}).Error
+		}
+		if err != nil {
+			return err
+		}
+		return tx.Where("article_id=?", articleId).Delete(&ReaderArticleTag{}).Error
+	})
+	if err != nil {
+		return err
+	}
+	return repo.data.rdb.Del(ctx, genArticleCacheKey(articleId), firstPageKey(reader.AuthorId), firstPageKey(0)).Err()
+}
+
+func (repo *articleReconcileRepo) TryLockReconcile(ctx *gin.Context, ttl time.Duration) (bool, error) {
+	// 锁不主动释放，到期后自然失效，从而限制比对的频率
+	return repo.data.rdb.SetNX(ctx, reconcileLockKey, time.Now().UnixMilli(), ttl).Result()
+}
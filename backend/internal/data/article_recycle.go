@@ -0,0 +1,164 @@
+package data
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"ibook/internal/conf"
+	"ibook/internal/service"
+	"strconv"
+	"time"
+)
+
+// defaultRecycleRetentionDays 未配置回收站保留天数时使用的默认值
+const defaultRecycleRetentionDays = 30
+
+type articleRecycleRepo struct {
+	data      *Data
+	retention time.Duration
+}
+
+func NewArticleRecycleRepo(data *Data, aConf *conf.Article) service.ArticleRecycleRepo {
+	days := int64(defaultRecycleRetentionDays)
+	if aConf != nil && aConf.RecycleRetentionDays > 0 {
+		days = aConf.RecycleRetentionDays
+	}
+	return &articleRecycleRepo{data: data, retention: time.Duration(days) * 24 * time.Hour}
+}
+
+func (repo *articleRecycleRepo) DeleteArticle(ctx *gin.Context, articleId int64, authorId int64) error {
+	now := time.Now().UTC().UnixMilli()
+	err := repo.data.mdb.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 1. 作者库软删除
+		res := tx.Model(&ArticleAuthor{}).
+			Where("id=? and author_id=? and deleted_time=0", articleId, authorId).
+			Updates(map[string]any{
+				"deleted_time": now,
+				"updated_time": now,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return service.ArticleNotExistsErr
+		}
+		// 2. 取消尚未应用的发件箱事件，并将读者库中的文章设为私有，恢复后需要重新发表
+		// 读者库中的记录保留到彻底删除时，更新 updated_time 使各副本的搜索索引增量同步能感知到删除
+		if err := cancelOutboxEvents(tx, articleId); err != nil {
+			return err
+		}
+		err := tx.Model(&ArticleReader{}).
+			Where("id=? and author_id=?", articleId, authorId).
+			Updates(map[string]any{
+				"status":       service.ArticleStatusPrivate,
+				"updated_time": now,
+			}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Where("article_id=?", articleId).Delete(&ReaderArticleTag{}).Error
+	})
+	if err != nil {
+		return err
+	}
+	// 3. 事务提交后删除文章缓存与列表缓存
+	return repo.data.rdb.Del(ctx, genArticleCacheKey(articleId), firstPageKey(authorId), firstPageKey(0)).Err()
+}
+
+func (repo *articleRecycleRepo) ListDeletedArticles(ctx *gin.Context, authorId int64, offset int64, limit int64) ([]*service.DeletedArticle, error) {
+	var rows []ArticleAuthor
+	err := repo.data.mdb.WithContext(ctx).
+		Select("id", "title", "format", "category", "author_id", "created_time", "updated_time", "status", "deleted_time").
+		Where("author_id=? and deleted_time>?", authorId, repo.cutoff()).
+		Order("deleted_time desc").
+		Offset(int(offset)).Limit(int(limit)).
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*service.DeletedArticle, 0, len(rows))
+	for i := range rows {
+		res = append(res, &service.DeletedArticle{
+			Article:     *toServiceArticle(&rows[i].Article),
+			DeletedTime: rows[i].DeletedTime,
+			PurgeTime:   rows[i].DeletedTime + repo.retention.Milliseconds(),
+		})
+	}
+	return res, nil
+}
+
+func (repo *articleRecycleRepo) RestoreArticle(ctx *gin.Context, articleId int64, authorId int64) error {
+	// 读者库中的文章已设为私有，恢复后作为草稿，版本号加一使打开的旧编辑页失效
+	res := repo.data.mdb.WithContext(ctx).Model(&ArticleAuthor{}).
+		Where("id=? and author_id=? and deleted_time>?", articleId, authorId, repo.cutoff()).
+		Updates(map[string]any{
+			"deleted_time": 0,
+			"status":       service.ArticleStatusUnpublished,
+			"version":      gorm.Expr("version + 1"),
+			"updated_time": time.Now().UTC().UnixMilli(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return service.ArticleNotInRecycleBinErr
+	}
+	return nil
+}
+
+func (repo *articleRecycleRepo) ListExpiredArticles(ctx *gin.Context, limit int64) ([]int64, error) {
+	var ids []int64
+	err := repo.data.mdb.WithContext(ctx).Model(&ArticleAuthor{}).
+		Where("deleted_time>0 and deleted_time<=?", repo.cutoff()).
+		Order("deleted_time").
+		Limit(int(limit)).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+func (repo *articleRecycleRepo) PurgeArticle(ctx *gin.Context, articleId int64) error {
+	err := repo.data.mdb.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 再次确认文章已超过保留期限，避免与恢复操作并发时误删
+		res := tx.Where("id=? and deleted_time>0 and deleted_time<=?", articleId, repo.cutoff()).Delete(&ArticleAuthor{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+		var commentIds []int64
+		if err := tx.Model(&Comment{}).Where("article_id=?", articleId).Pluck("id", &commentIds).Error; err != nil {
+			return err
+		}
+		if len(commentIds) > 0 {
+			if err := tx.Where("comment_id in ?", commentIds).Delete(&CommentLike{}).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("id=?", articleId).Delete(&ArticleReader{}).Error; err != nil {
+			return err
+		}
+		for _, model := range []any{&Comment{}, &LikeRecord{}, &CollectRecord{}, &Interactive{},
+			&ArticleRevision{}, &ArticleSchedule{}, &AuthorArticleTag{}, &ReaderArticleTag{}, &ArticleOutbox{}} {
+			if err := tx.Where("article_id=?", articleId).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	// 丢弃尚未写入数据库的阅读计数，避免下次刷新时为已删除的文章重新创建互动记录
+	field := strconv.FormatInt(articleId, 10)
+	pipe := repo.data.rdb.TxPipeline()
+	pipe.HDel(ctx, readBufferKey, field)
+	pipe.HDel(ctx, readFlushingKey, field)
+	pipe.Del(ctx, genArticleInteractiveCacheKey(articleId), genArticleReadersKey(articleId))
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// cutoff 早于该时间删除的文章已超过保留期限
+func (repo *articleRecycleRepo) cutoff() int64 {
+	return time.Now().Add(-repo.retention).UTC().UnixMilli()
+}
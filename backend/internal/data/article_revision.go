@@ -0,0 +1,87 @@
+package data
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"ibook/internal/service"
+	"ibook/pkg/utils/bskit/slice"
+	"time"
+)
+
+// ArticleRevision 作者每次保存、发表、恢复文章时写入的不可变历史版本
+type ArticleRevision struct {
+	Id          int64 `gorm:"primaryKey,autoIncrement"`
+	ArticleId   int64 `gorm:"index"`
+	AuthorId    int64
+	Title       string
+	Content     string
+	Format      uint8
+	Kind        uint8
+	CreatedTime int64
+}
+
+type articleRevisionRepo struct {
+	data *Data
+}
+
+func NewArticleRevisionRepo(data *Data) service.ArticleRevisionRepo {
+	return &articleRevisionRepo{data: data}
+}
+
+// createRevision 写入历史版本，由保存或发表文章的事务调用，保证每次写入都有对应的历史版本
+func createRevision(tx *gorm.DB, articleId int64, article *service.ArticleAuthor, kind service.RevisionKind) error {
+	return tx.Create(&ArticleRevision{
+		ArticleId:   articleId,
+		AuthorId:    article.Author.Id,
+		Title:       article.Title,
+		Content:     article.Content,
+		Format:      uint8(article.Format),
+		Kind:        uint8(kind),
+		CreatedTime: time.Now().UTC().UnixMilli(),
+	}).Error
+}
+
+// ListRevisions 按时间倒序列出历史版本，不返回正文
+func (repo *articleRevisionRepo) ListRevisions(ctx *gin.Context, articleId int64, authorId int64, offset int64, limit int64) ([]*service.ArticleRevision, error) {
+	var revs []ArticleRevision
+	err := repo.data.mdb.WithContext(ctx).
+		Select("id", "article_id", "author_id", "title", "format", "kind", "created_time").
+		Where("article_id=? and author_id=?", articleId, authorId).
+		Order("id desc").
+		Offset(int(offset)).Limit(int(limit)).
+		Find(&revs).Error
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[ArticleRevision, *service.ArticleRevision](revs, func(idx int, src ArticleRevision) *service.ArticleRevision {
+		return toServiceRevision(&src)
+	}), nil
+}
+
+func (repo *articleRevisionRepo) GetRevision(ctx *gin.Context, revisionId int64, authorId int64) (*service.ArticleRevision, error) {
+	rev := &ArticleRevision{}
+	err := repo.data.mdb.WithContext(ctx).
+		Where("id=? and author_id=?", revisionId, authorId).
+		First(rev).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, service.ArticleRevisionNotExistsErr
+		}
+		return nil, err
+	}
+	return toServiceRevision(rev), nil
+}
+
+func toServiceRevision(src *ArticleRevision) *service.ArticleRevision {
+	return &service.ArticleRevision{
+		Id:          src.Id,
+		ArticleId:   src.ArticleId,
+		AuthorId:    src.AuthorId,
+		Title:       src.Title,
+		Content:     src.Content,
+		Format:      service.ContentFormat(src.Format),
+		Kind:        service.RevisionKind(src.Kind),
+		CreatedTime: src.CreatedTime,
+	}
+}
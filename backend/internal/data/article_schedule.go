@@ -0,0 +1,221 @@
+package data
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"ibook/internal/service"
+	"ibook/pkg/utils/bskit/slice"
+	"time"
+)
+
+// ArticleSchedule 定时发表/定时撤回计划
+// 多副本通过 version 字段做乐观锁抢占计划，抢占成功后在 LeaseUntil 之前其他副本不会重复执行
+type ArticleSchedule struct {
+	Id          int64 `gorm:"primaryKey,autoIncrement"`
+	ArticleId   int64 `gorm:"index:aid_action"`
+	AuthorId    int64 `gorm:"index"`
+	Action      uint8 `gorm:"index:aid_action"`
+	ExecuteAt   int64 `gorm:"index:status_exec,priority:2"`
+	Status      uint8 `gorm:"index:status_exec,priority:1"`
+	LeaseUntil  int64
+	Attempts    int64
+	LastErr     string `gorm:"type:varchar(1024)"`
+	Version     int64
+	CreatedTime int64
+	UpdatedTime int64
+}
+
+type articleScheduleRepo struct {
+	data *Data
+}
+
+func NewArticleScheduleRepo(data *Data) service.ArticleScheduleRepo {
+	return &articleScheduleRepo{data: data}
+}
+
+// UpsertPendingSchedule 同一篇文章的同一种动作只保留一条待执行计划，已存在时只修改执行时间
+func (repo *articleScheduleRepo) UpsertPendingSchedule(ctx *gin.Context, schedule *service.ArticleSchedule) error {
+	now := time.Now().UTC().UnixMilli()
+	return repo.data.mdb.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		s := &ArticleSchedule{}
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("article_id=? and action=? and status=?", schedule.ArticleId, schedule.Action, service.ScheduleStatusPending).
+			First(s).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			s = &ArticleSchedule{
+				ArticleId:   schedule.ArticleId,
+				AuthorId:    schedule.AuthorId,
+				Action:      uint8(schedule.Action),
+				ExecuteAt:   schedule.ExecuteAt,
+				Status:      uint8(service.ScheduleStatusPending),
+				CreatedTime: now,
+				UpdatedTime: now,
+			}
+			err = tx.Create(s).Error
+		case err != nil:
+			return err
+		default:
+			s.ExecuteAt = schedule.ExecuteAt
+			s.Version++
+			s.UpdatedTime = now
+			err = tx.Model(&ArticleSchedule{}).Where("id=?", s.Id).Updates(map[string]any{
+				"execute_at":   s.ExecuteAt,
+				"version":      s.Version,
+				"updated_time": now,
+			}).Error
+		}
+		if err != nil {
+			return err
+		}
+		*schedule = *toServiceSchedule(s)
+		return nil
+	})
+}
+
+func (repo *articleScheduleRepo) CancelPendingSchedule(ctx *gin.Context, articleId int64, authorId int64, action service.ScheduleAction) error {
+	return repo.data.mdb.WithContext(ctx).Model(&ArticleSchedule{}).
+		Where("article_id=? and author_id=? and action=? and status=?", articleId, authorId, action, service.ScheduleStatusPending).
+		Updates(map[string]any{
+			"status":       service.ScheduleStatusCanceled,
+			"version":      gorm.Expr("version + 1"),
+			"updated_time": time.Now().UTC().UnixMilli(),
+		}).Error
+}
+
+func (repo *articleScheduleRepo) GetSchedule(ctx *gin.Context, scheduleId int64, authorId int64) (*service.ArticleSchedule, error) {
+	s := &ArticleSchedule{}
+	err := repo.data.mdb.WithContext(ctx).Where("id=? and author_id=?", scheduleId, authorId).First(s).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, service.ArticleScheduleNotExistsErr
+		}
+		return nil, err
+	}
+	return toServiceSchedule(s), nil
+}
+
+// ListPendingSchedules 列出作者待执行的计划，articleId 为 0 时列出全部文章
+func (repo *articleScheduleRepo) ListPendingSchedules(ctx *gin.Context, authorId int64, articleId int64) ([]*service.ArticleSchedule, error) {
+	var schedules []ArticleSchedule
+	query := repo.data.mdb.WithContext(ctx).
+		Where("author_id=? and status=?", authorId, service.ScheduleStatusPending)
+	if articleId > 0 {
+		query = query.Where("article_id=?", articleId)
+	}
+	if err := query.Order("execute_at asc").Find(&schedules).Error; err != nil {
+		return nil, err
+	}
+	return slice.Map[ArticleSchedule, *service.ArticleSchedule](schedules, func(idx int, src ArticleSchedule) *service.ArticleSchedule {
+		return toServiceSchedule(&src)
+	}), nil
+}
+
+func (repo *articleScheduleRepo) UpdateScheduleTime(ctx *gin.Context, scheduleId int64, authorId int64, executeAt int64) error {
+	res := repo.data.mdb.WithContext(ctx).Model(&ArticleSchedule{}).
+		Where("id=? and author_id=? and status=?", scheduleId, authorId, service.ScheduleStatusPending).
+		Updates(map[string]any{
+			"execute_at":   executeAt,
+			"version":      gorm.Expr("version + 1"),
+			"updated_time": time.Now().UTC().UnixMilli(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return service.ArticleScheduleNotExistsErr
+	}
+	return nil
+}
+
+func (repo *articleScheduleRepo) CancelSchedule(ctx *gin.Context, scheduleId int64, authorId int64) error {
+	res := repo.data.mdb.WithContext(ctx).Model(&ArticleSchedule{}).
+		Where("id=? and author_id=? and status=?", scheduleId, authorId, service.ScheduleStatusPending).
+		Updates(map[string]any{
+			"status":       service.ScheduleStatusCanceled,
+			"version":      gorm.Expr("version + 1"),
+			"updated_time": time.Now().UTC().UnixMilli(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return service.ArticleScheduleNotExistsErr
+	}
+	return nil
+}
+
+// ListDueSchedules 查询到期待执行的计划，以及执行中但租约已过期（执行副本可能已宕机）的计划
+func (repo *articleScheduleRepo) ListDueSchedules(ctx *gin.Context, now int64, limit int64) ([]*service.ArticleSchedule, error) {
+	var schedules []ArticleSchedule
+	err := repo.data.mdb.WithContext(ctx).
+		Where("(status=? and execute_at<=?) or (status=? and lease_until<?)",
+			service.ScheduleStatusPending, now, service.ScheduleStatusRunning, now).
+		Order("execute_at asc").Limit(int(limit)).
+		Find(&schedules).Error
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[ArticleSchedule, *service.ArticleSchedule](schedules, func(idx int, src ArticleSchedule) *service.ArticleSchedule {
+		return toServiceSchedule(&src)
+	}), nil
+}
+
+// ClaimSchedule 抢占计划的执行权，version 不一致说明已被其他副本抢占或被作者修改
+func (repo *articleScheduleRepo) ClaimSchedule(ctx *gin.Context, schedule *service.ArticleSchedule, now int64, leaseUntil int64) (bool, error) {
+	res := repo.data.mdb.WithContext(ctx).Model(&ArticleSchedule{}).
+		Where("id=? and version=?", schedule.Id, schedule.Version).
+		Where("(status=? and execute_at<=?) or (status=? and lease_until<?)",
+			service.ScheduleStatusPending, now, service.ScheduleStatusRunning, now).
+		Updates(map[string]any{
+			"status":       service.ScheduleStatusRunning,
+			"lease_until":  leaseUntil,
+			"attempts":     gorm.Expr("attempts + 1"),
+			"version":      gorm.Expr("version + 1"),
+			"updated_time": now,
+		})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return false, nil
+	}
+	schedule.Status = service.ScheduleStatusRunning
+	schedule.Attempts++
+	schedule.Version++
+	return true, nil
+}
+
+// FinishSchedule 记录执行结果，status 为 pending 时表示稍后在 retryAt 重试
+func (repo *articleScheduleRepo) FinishSchedule(ctx *gin.Context, schedule *service.ArticleSchedule, status service.ScheduleStatus, lastErr string, retryAt int64) error {
+	updates := map[string]any{
+		"status":       status,
+		"last_err":     truncateRunes(lastErr, lastErrMaxLen),
+		"version":      gorm.Expr("version + 1"),
+		"updated_time": time.Now().UTC().UnixMilli(),
+	}
+	if status == service.ScheduleStatusPending {
+		updates["execute_at"] = retryAt
+	}
+	return repo.data.mdb.WithContext(ctx).Model(&ArticleSchedule{}).
+		Where("id=? and version=?", schedule.Id, schedule.Version).
+		Updates(updates).Error
+}
+
+func toServiceSchedule(src *ArticleSchedule) *service.ArticleSchedule {
+	return &service.ArticleSchedule{
+		Id:          src.Id,
+		ArticleId:   src.ArticleId,
+		AuthorId:    src.AuthorId,
+		Action:      service.ScheduleAction(src.Action),
+		ExecuteAt:   src.ExecuteAt,
+		Status:      service.ScheduleStatus(src.Status),
+		Attempts:    src.Attempts,
+		LastErr:     src.LastErr,
+		Version:     src.Version,
+		CreatedTime: src.CreatedTime,
+		UpdatedTime: src.UpdatedTime,
+	}
+}
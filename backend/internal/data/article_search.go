@@ -0,0 +1,203 @@
+package data
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"ibook/internal/conf"
+	"ibook/internal/service"
+	"ibook/pkg/utils/fulltext"
+	"ibook/pkg/utils/logger"
+	"ibook/pkg/utils/markdown"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	searchScanBatch = 500
+	// searchCatchUpLag 增量同步时回看的时间窗口，容忍多副本之间的时钟误差与事务提交延迟
+	searchCatchUpLag = 5 * time.Second
+)
+
+// articleSearchRepo 基于内存倒排索引的全文检索，索引定期保存快照到磁盘
+// 每个副本各自维护索引，通过读者库的 updated_time 增量同步其他副本上的发表与撤回
+type articleSearchRepo struct {
+	data      *Data
+	tokenizer fulltext.Tokenizer
+	path      string
+	logger    logger.Logger
+
+	idx   atomic.Pointer[fulltext.Index]
+	ready atomic.Bool
+	// mu 保证增量同步、重建与保存快照串行执行
+	mu sync.Mutex
+	// watermark 已同步到的读者库 updated_time
+	watermark int64
+}
+
+func NewArticleSearchRepo(data *Data, sConf *conf.Search, myLogger logger.Logger) (service.ArticleSearchRepo, error) {
+	tokenizer, err := fulltext.NewGseTokenizer()
+	if err != nil {
+		return nil, fmt.Errorf("加载分词词典失败：%w", err)
+	}
+	repo := &articleSearchRepo{data: data, tokenizer: tokenizer, logger: myLogger}
+	if sConf != nil {
+		repo.path = sConf.IndexPath
+	}
+	repo.idx.Store(fulltext.NewIndex(tokenizer))
+	return repo, nil
+}
+
+func (repo *articleSearchRepo) IndexArticle(ctx *gin.Context, article *service.Article) error {
+	repo.idx.Load().Upsert(toSearchDocument(article.Id, article.Title, article.Content, uint8(article.Format)))
+	return nil
+}
+
+func (repo *articleSearchRepo) RemoveArticle(ctx *gin.Context, articleId int64) error {
+	repo.idx.Load().Delete(articleId)
+	return nil
+}
+
+func (repo *articleSearchRepo) Search(ctx *gin.Context, query string, offset int, limit int) ([]*service.SearchHit, []string, int, error) {
+	if !repo.ready.Load() {
+		return nil, nil, 0, service.SearchIndexNotReadyErr
+	}
+	idx := repo.idx.Load()
+	terms := idx.Tokenize(query)
+	hits, total := idx.Search(terms, offset, limit)
+	res := make([]*service.SearchHit, 0, len(hits))
+	for _, hit := range hits {
+		res = append(res, &service.SearchHit{ArticleId: hit.Id, Score: hit.Score})
+	}
+	return res, terms, total, nil
+}
+
+func (repo *articleSearchRepo) CatchUp(ctx *gin.Context) (int, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	idx := repo.idx.Load()
+	since := repo.watermark - searchCatchUpLag.Milliseconds()
+	cnt := 0
+	err := repo.scan(ctx, since, false, func(article *Article) {
+		if article.Status == service.ArticleStatusPublished {
+			idx.Upsert(toSearchDocument(article.Id, article.Title, article.Content, article.Format))
+		} else {
+			idx.Delete(article.Id)
+		}
+		if article.UpdatedTime > repo.watermark {
+			repo.watermark = article.UpdatedTime
+		}
+		cnt++
+	})
+	return cnt, err
+}
+
+func (repo *articleSearchRepo) Rebuild(ctx *gin.Context) (int, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	// 重建期间发生的变化由之后的增量同步补齐，因此水位取开始重建的时间
+	startedAt := time.Now().UnixMilli()
+	idx := fulltext.NewIndex(repo.tokenizer)
+	err := repo.scan(ctx, 0, true, func(article *Article) {
+		idx.Upsert(toSearchDocument(article.Id, article.Title, article.Content, article.Format))
+	})
+	if err != nil {
+		return 0, err
+	}
+	repo.idx.Store(idx)
+	repo.watermark = startedAt
+	repo.ready.Store(true)
+	return idx.Len(), nil
+}
+
+// scan 按 (updated_time, id) 升序分批遍历读者库中 updated_time 大于 since 的文章
+func (repo *articleSearchRepo) scan(ctx *gin.Context, since int64, onlyPublished bool, fn func(article *Article)) error {
+	cursor := service.ListCursor{Value: since}
+	for {
+		var batch []Article
+		query := repo.data.mdb.WithContext(ctx).Model(&ArticleReader{}).
+			Select("id", "title", "content", "format", "status", "updated_time").
+			Where("updated_time > ? or (updated_time = ? and id > ?)", cursor.Value, cursor.Value, cursor.Id)
+		if onlyPublished {
+			query = query.Where("status=?", service.ArticleStatusPublished)
+		}
+		err := query.Order("updated_time asc, id asc").Limit(searchScanBatch).Find(&batch).Error
+		if err != nil {
+			return err
+		}
+		for i := range batch {
+			fn(&batch[i])
+		}
+		if len(batch) < searchScanBatch {
+			return nil
+		}
+		last := batch[len(batch)-1]
+		cursor = service.ListCursor{Value: last.UpdatedTime, Id: last.Id}
+	}
+}
+
+func (repo *articleSearchRepo) LoadSnapshot(ctx *gin.Context) error {
+	if repo.path == "" {
+		return errors.New("未配置索引快照路径")
+	}
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	f, err := os.Open(repo.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var watermark int64
+	idx := fulltext.NewIndex(repo.tokenizer)
+	dec := gob.NewDecoder(f)
+	if err = dec.Decode(&watermark); err != nil {
+		return fmt.Errorf("解析索引快照失败：%w", err)
+	}
+	if err = dec.Decode(idx); err != nil {
+		return fmt.Errorf("解析索引快照失败：%w", err)
+	}
+	repo.idx.Store(idx)
+	repo.watermark = watermark
+	repo.ready.Store(true)
+	return nil
+}
+
+// SaveSnapshot 先写入临时文件再重命名，避免进程中途退出时留下不完整的快照
+func (repo *articleSearchRepo) SaveSnapshot(ctx *gin.Context) error {
+	if repo.path == "" || !repo.ready.Load() {
+		return nil
+	}
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(repo.path), 0o755); err != nil {
+		return err
+	}
+	tmp := repo.path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	enc := gob.NewEncoder(f)
+	if err = enc.Encode(repo.watermark); err == nil {
+		err = enc.Encode(repo.idx.Load())
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("保存索引快照失败：%w", err)
+	}
+	return os.Rename(tmp, repo.path)
+}
+
+func toSearchDocument(id int64, title string, content string, format uint8) fulltext.Document {
+	if service.ContentFormat(format) == service.ContentFormatMarkdown {
+		content = markdown.PlainText(content)
+	}
+	return fulltext.Document{Id: id, Title: title, Content: content}
+}
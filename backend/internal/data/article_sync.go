@@ -4,39 +4,51 @@ import (
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
-	"gorm.io/gorm/clause"
 	"ibook/internal/service"
 	"ibook/pkg/utils/logger"
-	"time"
 )
 
 type Article struct {
-	Id          int64  `gorm:"primaryKey,authIncrement"`
-	Title       string `gorm:"type=varchar(1024)"`
-	Content     string `gorm:"type=blob"`
+	Id       int64  `gorm:"primaryKey,authIncrement"`
+	Title    string `gorm:"type=varchar(1024)"`
+	Content  string `gorm:"type=blob"`
+	Format   uint8
+	Category string `gorm:"type:varchar(64);index"`
+	// Abstract 与 HTML 在发表时生成，只有读者库中有值
+	Abstract    string `gorm:"type:varchar(512)"`
+	HTML        string `gorm:"type:mediumtext"`
 	AuthorId    int64  `gorm:"index=aid_ctime"`
 	CreatedTime int64  `gorm:"index=aid_ctime"`
-	UpdatedTime int64
-	Status      uint8
+	UpdatedTime int64  `gorm:"index:status_utime,priority:2;index:utime"`
+	Status      uint8  `gorm:"index:status_utime,priority:1"`
+	// Version 作者库草稿的版本号，每次写入加一，用于检测并发编辑
+	Version int64
 }
 
 type Interactive struct {
-	Id          int64 `gorm:"primaryKey,autoIncrement"`
-	ArticleId   int64 `gorm:"unique"`
-	ReadCnt     int64 // 阅读数
-	LikeCnt     int64 // 点赞数
-	CollectCnt  int64 // 收藏数
+	Id         int64 `gorm:"primaryKey,autoIncrement"`
+	ArticleId  int64 `gorm:"unique"`
+	ReadCnt    int64 // 阅读数
+	LikeCnt    int64 // 点赞数
+	DislikeCnt int64 // 踩数
+	CollectCnt int64 // 收藏数
+	CommentCnt int64 // 评论数
+	// ReadBatchId 最近一次写入的阅读计数批次号，同一批次重复写入时不再累加
+	ReadBatchId string `gorm:"type:varchar(64)"`
 	CreatedTime int64
 	UpdatedTime int64
 }
 
+// LikeRecord 用户对文章的表态，每个用户对每篇文章只有一条记录
 type LikeRecord struct {
 	Id          int64 `gorm:"primaryKey,autoIncrement"`
-	UserId      int64 `gorm:"index=uid_aid, unique"`
-	ArticleId   int64 `gorm:"index=uid_aid, unique"`
+	UserId      int64 `gorm:"uniqueIndex:uid_aid;index:uid_status_utime,priority:1"`
+	ArticleId   int64 `gorm:"uniqueIndex:uid_aid;index:aid_status_utime,priority:1"`
 	CreatedTime int64
-	UpdatedTime int64
-	Status      uint8
+	// 按点赞时间分页查询用户的点赞与文章的点赞用户
+	UpdatedTime int64 `gorm:"index:uid_status_utime,priority:3;index:aid_status_utime,priority:3"`
+	// Status 取值为 service.ReactionStatus
+	Status uint8 `gorm:"index:uid_status_utime,priority:2;index:aid_status_utime,priority:2"`
 }
 
 type articleSyncRepo struct {
@@ -45,21 +57,19 @@ type articleSyncRepo struct {
 }
 
 // Sync 同步发表文章，文章 status 都应为 published
+// 作者库、发表的历史版本与发表事件在同一事务中写入，读者库与缓存由 relay 应用事件时更新
 func (repo *articleSyncRepo) Sync(ctx *gin.Context, articleA *service.ArticleAuthor, articleR *service.ArticleReader) error {
-	err := repo.data.mdb.Transaction(func(tx *gorm.DB) error {
+	err := repo.data.mdb.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		// flag > 0 -> 更新 || flag <= 0 -> 创建
 		flag := articleA.Id
-		authorRepo := NewArticleAuthorRepo(repo.data)
+		authorRepo := NewArticleAuthorRepo(&Data{mdb: tx, rdb: repo.data.rdb})
 		if flag > 0 {
-			ok, err := authorRepo.UpdateArticle(ctx, articleA)
-			if err != nil {
+			// 内容未变化时作者库不写入，不视为失败，发表的历史版本在下面统一写入
+			if _, err := authorRepo.UpdateArticle(ctx, articleA, service.RevisionKindUnknown); err != nil {
 				return fmt.Errorf("同步发表过程出错：更新作者文章失败：%w", err)
 			}
-			if !ok {
-				return fmt.Errorf("同步发表过程出错：更新作者文章失败")
-			}
 		} else {
-			ok, err := authorRepo.CreateArticle(ctx, articleA)
+			ok, err := authorRepo.CreateArticle(ctx, articleA, service.RevisionKindPublish)
 			if err != nil {
 				return fmt.Errorf("同步发表过程出错：创建作者文章失败：%w", err)
 			}
@@ -68,94 +78,33 @@ func (repo *articleSyncRepo) Sync(ctx *gin.Context, articleA *service.ArticleAut
 			}
 		}
 		articleR.Id = articleA.Id
-		readerRepo := NewArticleReaderRepo(repo.data, repo.logger)
-		return readerRepo.UpsertArticle(ctx, articleR)
+		// 每次发表都记录历史版本，内容未变化的重新发表也不例外
+		if flag > 0 {
+			if err := createRevision(tx, articleA.Id, articleA, service.RevisionKindPublish); err != nil {
+				return fmt.Errorf("同步发表过程出错：写入历史版本失败：%w", err)
+			}
+		}
+		// 标签为 nil 时沿用制作库中已有的标签
+		tags, err := listAuthorTags(tx, articleA.Id)
+		if err != nil {
+			return fmt.Errorf("同步发表过程出错：查询文章标签失败：%w", err)
+		}
+		return appendOutboxEvent(tx, articleA.Id, service.OutboxEventPublish, newPublishPayload(articleR, tags))
 	})
 	return err
 }
 
 func (repo *articleSyncRepo) SyncUpdateStatus(ctx *gin.Context, articleId int64, authorId int64, status uint8) error {
 	err := repo.data.mdb.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		mdbData := &Data{mdb: tx}
-		authorRepo := NewArticleAuthorRepo(mdbData)
-		readerRepo := NewArticleReaderRepo(mdbData, repo.logger)
+		authorRepo := NewArticleAuthorRepo(&Data{mdb: tx, rdb: repo.data.rdb})
 		err := authorRepo.UpdateStatusById(ctx, articleId, authorId, status)
 		if err != nil {
 			return fmt.Errorf("同步更新文章状态时出错 - article_author: %w", err)
 		}
-		err = readerRepo.UpdateStatusById(ctx, articleId, authorId, status)
-		if err != nil {
-			return fmt.Errorf("同步更新文章状态时出错 - article_reader: %w", err)
-		}
-		return nil
-	})
-	return err
-}
-
-func (repo *articleSyncRepo) UpsertLikeInfo(ctx *gin.Context, userId int64, articleId int64) error {
-	now := time.Now().UTC().UnixMilli()
-	err := repo.data.mdb.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		// 1. 向记录表中插入记录
-		er := tx.Clauses(clause.OnConflict{
-			DoUpdates: clause.Assignments(map[string]any{
-				"updated_time": now,
-				"status":       1,
-			}),
-		}).Create(&LikeRecord{
-			UserId:      userId,
-			ArticleId:   articleId,
-			CreatedTime: now,
-			UpdatedTime: now,
-			Status:      1,
-		}).Error
-		if er != nil {
-			return er
-		}
-		return tx.Clauses(clause.OnConflict{
-			DoUpdates: clause.Assignments(map[string]any{
-				"like_cnt":     gorm.Expr("like_cnt + 1"),
-				"updated_time": now,
-			}),
-		}).Create(&Interactive{
-			ArticleId:   articleId,
-			LikeCnt:     1,
-			CreatedTime: now,
-			UpdatedTime: now,
-		}).Error
-	})
-	if err != nil {
-		return err
-	}
-	return nil
-}
-
-func (repo *articleSyncRepo) CancelLikeInfo(ctx *gin.Context, userId int64, articleId int64) error {
-	now := time.Now().UTC().UnixMilli()
-	err := repo.data.mdb.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		// 将记录隐藏
-		res := tx.Model(&LikeRecord{}).
-			Where("user_id=? and articleId=?", userId, articleId).
-			Updates(map[string]any{
-				"status":       0,
-				"updated_time": now,
-			})
-		if res.Error != nil {
-			return nil
-		}
-		if res.RowsAffected == 0 {
-			return fmt.Errorf("未找到相应点赞记录")
-		}
-		return tx.Clauses(clause.OnConflict{
-			DoUpdates: clause.Assignments(map[string]any{
-				"like_cnt":     gorm.Expr("like_cnt - 1"),
-				"updated_time": now,
-			}),
-		}).Create(&Interactive{
-			ArticleId:   articleId,
-			LikeCnt:     0,
-			CreatedTime: now,
-			UpdatedTime: now,
-		}).Error
+		return appendOutboxEvent(tx, articleId, service.OutboxEventStatus, &statusPayload{
+			AuthorId: authorId,
+			Status:   status,
+		})
 	})
 	return err
 }
@@ -0,0 +1,167 @@
+package data
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"ibook/internal/service"
+	"time"
+)
+
+type Tag struct {
+	Id          int64  `gorm:"primaryKey,autoIncrement"`
+	Name        string `gorm:"type:varchar(64);uniqueIndex"`
+	CreatedTime int64
+}
+
+// AuthorArticleTag 制作库中文章与标签的关联，编辑文章时写入
+type AuthorArticleTag struct {
+	Id          int64 `gorm:"primaryKey,autoIncrement"`
+	ArticleId   int64 `gorm:"uniqueIndex:aid_tid"`
+	TagId       int64 `gorm:"uniqueIndex:aid_tid;index"`
+	CreatedTime int64
+}
+
+// ReaderArticleTag 读者库中文章与标签的关联，发表时由制作库复制
+type ReaderArticleTag struct {
+	Id          int64 `gorm:"primaryKey,autoIncrement"`
+	ArticleId   int64 `gorm:"uniqueIndex:aid_tid"`
+	TagId       int64 `gorm:"uniqueIndex:aid_tid;index"`
+	CreatedTime int64
+}
+
+type articleTagRepo struct {
+	data *Data
+}
+
+func NewArticleTagRepo(data *Data) service.ArticleTagRepo {
+	return &articleTagRepo{data: data}
+}
+
+// ListTagsByArticleIds 批量获取读者库中文章的标签
+func (repo *articleTagRepo) ListTagsByArticleIds(ctx *gin.Context, ids []int64) (map[int64][]string, error) {
+	res := make(map[int64][]string, len(ids))
+	if len(ids) == 0 {
+		return res, nil
+	}
+	var rows []struct {
+		ArticleId int64
+		Name      string
+	}
+	err := repo.data.mdb.WithContext(ctx).Model(&ReaderArticleTag{}).
+		Select("reader_article_tags.article_id, tags.name").
+		Joins("join tags on tags.id = reader_article_tags.tag_id").
+		Where("reader_article_tags.article_id in ?", ids).
+		Order("reader_article_tags.id asc").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		res[row.ArticleId] = append(res[row.ArticleId], row.Name)
+	}
+	return res, nil
+}
+
+// ListTagCounts 按已发表文章数倒序列出标签
+func (repo *articleTagRepo) ListTagCounts(ctx *gin.Context, limit int64) ([]*service.TagCount, error) {
+	var counts []*service.TagCount
+	err := repo.data.mdb.WithContext(ctx).Model(&ReaderArticleTag{}).
+		Select("tags.name as name, count(*) as article_cnt").
+		Joins("join tags on tags.id = reader_article_tags.tag_id").
+		Joins("join article_readers on article_readers.id = reader_article_tags.article_id").
+		Where("article_readers.status=?", service.ArticleStatusPublished).
+		Group("tags.id, tags.name").
+		Order("article_cnt desc, tags.id asc").
+		Limit(int(limit)).
+		Scan(&counts).Error
+	return counts, err
+}
+
+// getTagId 查询标签 id，标签不存在时返回 0
+func getTagId(db *gorm.DB, name string) (int64, error) {
+	tag := &Tag{}
+	err := db.Where("name=?", name).First(tag).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, nil
+	}
+	return tag.Id, err
+}
+
+// replaceAuthorTags 将制作库中文章的标签替换为 names，不存在的标签会被创建
+func replaceAuthorTags(tx *gorm.DB, articleId int64, names []string) error {
+	now := time.Now().UTC().UnixMilli()
+	if err := tx.Where("article_id=?", articleId).Delete(&AuthorArticleTag{}).Error; err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	tags := make([]Tag, 0, len(names))
+	for _, name := range names {
+		tags = append(tags, Tag{Name: name, CreatedTime: now})
+	}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&tags).Error; err != nil {
+		return err
+	}
+	// 已存在的标签插入时被忽略，需要重新查询 id
+	var existing []Tag
+	if err := tx.Where("name in ?", names).Find(&existing).Error; err != nil {
+		return err
+	}
+	idMap := make(map[string]int64, len(existing))
+	for _, tag := range existing {
+		idMap[tag.Name] = tag.Id
+	}
+	// 按 names 的顺序写入，保持作者填写标签的顺序
+	relations := make([]AuthorArticleTag, 0, len(names))
+	for _, name := range names {
+		if id, ok := idMap[name]; ok {
+			relations = append(relations, AuthorArticleTag{ArticleId: articleId, TagId: id, CreatedTime: now})
+		}
+	}
+	if len(relations) == 0 {
+		return nil
+	}
+	return tx.Create(&relations).Error
+}
+
+func listAuthorTags(db *gorm.DB, articleId int64) ([]string, error) {
+	names := make([]string, 0)
+	err := db.Model(&AuthorArticleTag{}).
+		Joins("join tags on tags.id = author_article_tags.tag_id").
+		Where("author_article_tags.article_id=?", articleId).
+		Order("author_article_tags.id asc").
+		Pluck("tags.name", &names).Error
+	return names, err
+}
+
+// replaceReaderTags 将读者库中文章的标签替换为发表时的标签，发表时标签已写入制作库，这里不会创建新标签
+func replaceReaderTags(tx *gorm.DB, articleId int64, names []string) error {
+	now := time.Now().UTC().UnixMilli()
+	if err := tx.Where("article_id=?", articleId).Delete(&ReaderArticleTag{}).Error; err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	var existing []Tag
+	if err := tx.Where("name in ?", names).Find(&existing).Error; err != nil {
+		return err
+	}
+	idMap := make(map[string]int64, len(existing))
+	for _, tag := range existing {
+		idMap[tag.Name] = tag.Id
+	}
+	relations := make([]ReaderArticleTag, 0, len(names))
+	for _, name := range names {
+		if id, ok := idMap[name]; ok {
+			relations = append(relations, ReaderArticleTag{ArticleId: articleId, TagId: id, CreatedTime: now})
+		}
+	}
+	if len(relations) == 0 {
+		return nil
+	}
+	return tx.Create(&relations).Error
+}
@@ -0,0 +1,78 @@
+package data
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"ibook/internal/conf"
+	"ibook/internal/service"
+	"ibook/pkg/utils/blobstore"
+	"io"
+	"strings"
+)
+
+const (
+	defaultBlobRoot = "./data/blobs"
+	// defaultBlobBaseUrl 未配置 PublicBaseUrl 时由服务自身提供上传文件的访问
+	defaultBlobBaseUrl = "/blobs"
+)
+
+func NewBlobStore(bConf *conf.Blob) blobstore.BlobStore {
+	if bConf != nil && bConf.Driver == "s3" {
+		if bConf.S3 == nil {
+			panic("初始化对象存储失败: 缺少 s3 配置")
+		}
+		store, err := blobstore.NewS3Store(blobstore.S3Config{
+			Endpoint:        bConf.S3.Endpoint,
+			Region:          bConf.S3.Region,
+			Bucket:          bConf.S3.Bucket,
+			AccessKeyId:     bConf.S3.AccessKeyId,
+			SecretAccessKey: bConf.S3.SecretAccessKey,
+			PathStyle:       bConf.S3.PathStyle,
+		})
+		if err != nil {
+			panic("初始化对象存储失败: " + err.Error())
+		}
+		return store
+	}
+	root := defaultBlobRoot
+	if bConf != nil && bConf.Local != nil && bConf.Local.Root != "" {
+		root = bConf.Local.Root
+	}
+	store, err := blobstore.NewLocalStore(root)
+	if err != nil {
+		panic("初始化对象存储失败: " + err.Error())
+	}
+	return store
+}
+
+type blobRepo struct {
+	store   blobstore.BlobStore
+	baseUrl string
+}
+
+func NewBlobRepo(store blobstore.BlobStore, bConf *conf.Blob) service.BlobRepo {
+	baseUrl := defaultBlobBaseUrl
+	if bConf != nil && bConf.PublicBaseUrl != "" {
+		baseUrl = strings.TrimRight(bConf.PublicBaseUrl, "/")
+	}
+	return &blobRepo{store: store, baseUrl: baseUrl}
+}
+
+func (repo *blobRepo) Put(ctx *gin.Context, key string, data []byte, contentType string) error {
+	return repo.store.Put(ctx, key, data, contentType)
+}
+
+func (repo *blobRepo) Open(ctx *gin.Context, key string) (io.ReadCloser, *service.BlobInfo, error) {
+	rc, info, err := repo.store.Get(ctx, key)
+	if errors.Is(err, blobstore.ErrNotFound) || errors.Is(err, blobstore.ErrInvalidKey) {
+		return nil, nil, service.BlobNotExistsErr
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return rc, &service.BlobInfo{Size: info.Size, ContentType: info.ContentType}, nil
+}
+
+func (repo *blobRepo) Url(key string) string {
+	return repo.baseUrl + "/" + key
+}
@@ -0,0 +1,433 @@
+package data
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"ibook/internal/service"
+	"time"
+)
+
+const (
+	commentLikeStatusActive   = 1
+	commentLikeStatusCanceled = 0
+)
+
+type Comment struct {
+	Id        int64 `gorm:"primaryKey,autoIncrement"`
+	ArticleId int64 `gorm:"index:aid_root_ctime,priority:1;index:aid_root_like,priority:1"`
+	UserId    int64
+	// RootId 为 0 表示一级评论，否则为所属的一级评论
+	RootId      int64 `gorm:"index;index:aid_root_ctime,priority:2;index:aid_root_like,priority:2"`
+	ParentId    int64
+	ReplyToUid  int64
+	Content     string `gorm:"type:varchar(4096)"`
+	LikeCnt     int64  `gorm:"index:aid_root_like,priority:3"`
+	ReplyCnt    int64
+	CreatedTime int64 `gorm:"index:aid_root_ctime,priority:3"`
+	UpdatedTime int64
+}
+
+type CommentLike struct {
+	Id          int64 `gorm:"primaryKey,autoIncrement"`
+	UserId      int64 `gorm:"uniqueIndex:uid_cid"`
+	CommentId   int64 `gorm:"uniqueIndex:uid_cid;index"`
+	Status      uint8
+	CreatedTime int64
+	UpdatedTime int64
+}
+
+// commentRow 评论及评论者昵称
+type commentRow struct {
+	Comment
+	UserName string
+}
+
+type commentRepo struct {
+	data *Data
+}
+
+type commentCache struct {
+	data *Data
+}
+
+func NewCommentRepo(data *Data) service.CommentRepo {
+	return &commentRepo{data: data}
+}
+
+func NewCommentCache(data *Data) service.CommentCache {
+	return &commentCache{data: data}
+}
+
+func (repo *commentRepo) CreateComment(ctx *gin.Context, comment *service.Comment) error {
+	now := time.Now().UTC().UnixMilli()
+	newComment := &Comment{
+		ArticleId:   comment.ArticleId,
+		UserId:      comment.UserId,
+		ParentId:    comment.ParentId,
+		Content:     comment.Content,
+		CreatedTime: now,
+		UpdatedTime: now,
+	}
+	err := repo.data.mdb.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 1. 校验文章处于发表状态
+		var articleCnt int64
+		err := tx.Model(&ArticleReader{}).
+			Where("id=? and status=?", comment.ArticleId, service.ArticleStatusPublished).
+			Count(&articleCnt).Error
+		if err != nil {
+			return err
+		}
+		if articleCnt == 0 {
+			return service.ArticleNotExistsErr
+		}
+		// 2. 回复时确定所属的一级评论与被回复者，并更新一级评论的回复数
+		if comment.ParentId > 0 {
+			parent := &Comment{}
+			err = tx.Where("id=? and article_id=?", comment.ParentId, comment.ArticleId).First(parent).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return service.CommentNotExistsErr
+			}
+			if err != nil {
+				return err
+			}
+			newComment.RootId = parent.RootId
+			if parent.RootId == 0 {
+				newComment.RootId = parent.Id
+			}
+			newComment.ReplyToUid = parent.UserId
+			// 按一级评论、被回复评论的顺序加锁（与删除评论的顺序一致），确认它们在加锁后仍然存在，
+			// 避免与删除并发时写入一条挂在已删除评论下的回复
+			if err = lockComments(tx, newComment.RootId, parent.Id); err != nil {
+				return err
+			}
+			res := tx.Model(&Comment{}).Where("id=?", newComment.RootId).
+				Update("reply_cnt", gorm.Expr("reply_cnt + 1"))
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected != 1 {
+				return service.CommentNotExistsErr
+			}
+		}
+		if err = tx.Create(newComment).Error; err != nil {
+			return err
+		}
+		// 3. 更新文章的评论计数
+		return tx.Clauses(clause.OnConflict{
+			DoUpdates: clause.Assignments(map[string]any{
+				"comment_cnt":  gorm.Expr("comment_cnt + 1"),
+				"updated_time": now,
+			}),
+		}).Create(&Interactive{
+			ArticleId:   comment.ArticleId,
+			CommentCnt:  1,
+			CreatedTime: now,
+			UpdatedTime: now,
+		}).Error
+	})
+	if err != nil {
+		return err
+	}
+	comment.Id = newComment.Id
+	comment.RootId = newComment.RootId
+	comment.ReplyToUid = newComment.ReplyToUid
+	comment.CreatedTime = newComment.CreatedTime
+	return nil
+}
+
+// lockComments 按参数顺序对评论加锁，任意一条评论不存在时返回 CommentNotExistsErr
+func lockComments(tx *gorm.DB, ids ...int64) error {
+	seen := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		var lockedIds []int64
+		err := tx.Model(&Comment{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id=?", id).Pluck("id", &lockedIds).Error
+		if err != nil {
+			return err
+		}
+		if len(lockedIds) == 0 {
+			return service.CommentNotExistsErr
+		}
+	}
+	return nil
+}
+
+func (repo *commentRepo) GetCommentById(ctx *gin.Context, commentId int64) (*service.Comment, int64, error) {
+	var row struct {
+		Comment
+		AuthorId int64
+	}
+	res := repo.data.mdb.WithContext(ctx).Model(&Comment{}).
+		Select("comments.*, article_readers.author_id").
+		Joins("LEFT JOIN article_readers ON article_readers.id = comments.article_id").
+		Where("comments.id=?", commentId).
+		Scan(&row)
+	if res.Error != nil {
+		return nil, 0, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, 0, service.CommentNotExistsErr
+	}
+	return toServiceComment(&commentRow{Comment: row.Comment}), row.AuthorId, nil
+}
+
+func (repo *commentRepo) DeleteComment(ctx *gin.Context, comment *service.Comment) (int64, error) {
+	now := time.Now().UTC().UnixMilli()
+	var deleted int64
+	err := repo.data.mdb.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 1. 删除评论，一级评论连同其下的回复一起删除
+		// 先锁住一级评论再读取回复，与发表回复的加锁顺序一致，使并发发表的回复要么被一起删除，要么发表失败
+		rootId := comment.RootId
+		if rootId == 0 {
+			rootId = comment.Id
+		}
+		var rootIds []int64
+		err := tx.Model(&Comment{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id=?", rootId).Pluck("id", &rootIds).Error
+		if err != nil {
+			return err
+		}
+		ids := []int64{comment.Id}
+		if comment.RootId == 0 {
+			var replyIds []int64
+			err = tx.Model(&Comment{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("root_id=?", comment.Id).Pluck("id", &replyIds).Error
+			if err != nil {
+				return err
+			}
+			ids = append(ids, replyIds...)
+		}
+		res := tx.Where("id in ?", ids).Delete(&Comment{})
+		if res.Error != nil {
+			return res.Error
+		}
+		// 并发删除时由先提交的一方更新计数
+		deleted = res.RowsAffected
+		if deleted == 0 {
+			return nil
+		}
+		if err := tx.Where("comment_id in ?", ids).Delete(&CommentLike{}).Error; err != nil {
+			return err
+		}
+		// 2. 删除回复时更新一级评论的回复数
+		if comment.RootId > 0 {
+			err := tx.Model(&Comment{}).Where("id=? and reply_cnt>0", comment.RootId).
+				Update("reply_cnt", gorm.Expr("reply_cnt - 1")).Error
+			if err != nil {
+				return err
+			}
+		}
+		// 3. 更新文章的评论计数
+		return tx.Model(&Interactive{}).Where("article_id=?", comment.ArticleId).
+			Updates(map[string]any{
+				"comment_cnt":  gorm.Expr("GREATEST(comment_cnt - ?, 0)", deleted),
+				"updated_time": now,
+			}).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return deleted, nil
+}
+
+func (repo *commentRepo) ListRootComments(ctx *gin.Context, articleId int64, sort service.CommentSort, cursor service.ListCursor, limit int64) ([]*service.Comment, error) {
+	column := "comments.created_time"
+	if sort == service.CommentSortLike {
+		column = "comments.like_cnt"
+	}
+	query := repo.commentsQuery(ctx).Where("comments.article_id=? and comments.root_id=0", articleId)
+	if !cursor.IsZero() {
+		query = query.Where(fmt.Sprintf("%s < ? or (%s = ? and comments.id < ?)", column, column),
+			cursor.Value, cursor.Value, cursor.Id)
+	}
+	var rows []commentRow
+	err := query.Order(column + " desc").Order("comments.id desc").Limit(int(limit)).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return toServiceComments(rows), nil
+}
+
+func (repo *commentRepo) ListReplies(ctx *gin.Context, rootId int64, cursor service.ListCursor, limit int64) ([]*service.Comment, error) {
+	// 回复的 id 随时间递增，按 id 正序即为时间正序
+	query := repo.commentsQuery(ctx).Where("comments.root_id=?", rootId)
+	if !cursor.IsZero() {
+		query = query.Where("comments.id > ?", cursor.Id)
+	}
+	var rows []commentRow
+	err := query.Order("comments.id").Limit(int(limit)).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return toServiceComments(rows), nil
+}
+
+func (repo *commentRepo) ListReplyPreviews(ctx *gin.Context, rootIds []int64, n int) (map[int64][]*service.Comment, error) {
+	res := make(map[int64][]*service.Comment, len(rootIds))
+	if len(rootIds) == 0 {
+		return res, nil
+	}
+	// 使用窗口函数一次取出每条一级评论下最早的 n 条回复
+	var rows []commentRow
+	err := repo.data.mdb.WithContext(ctx).Raw(
+		"SELECT t.*, users.nick_name AS user_name FROM "+
+			"(SELECT comments.*, ROW_NUMBER() OVER (PARTITION BY root_id ORDER BY id) AS rn FROM comments WHERE root_id IN ?) AS t "+
+			"LEFT JOIN users ON users.id = t.user_id WHERE t.rn <= ? ORDER BY t.id", rootIds, n).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range toServiceComments(rows) {
+		res[c.RootId] = append(res[c.RootId], c)
+	}
+	return res, nil
+}
+
+func (repo *commentRepo) UpsertCommentLike(ctx *gin.Context, userId int64, commentId int64) (bool, error) {
+	return repo.setCommentLike(ctx, userId, commentId, commentLikeStatusActive)
+}
+
+func (repo *commentRepo) CancelCommentLike(ctx *gin.Context, userId int64, commentId int64) (bool, error) {
+	return repo.setCommentLike(ctx, userId, commentId, commentLikeStatusCanceled)
+}
+
+// setCommentLike 加锁读取旧记录，仅在点赞状态变化时更新评论的点赞数
+func (repo *commentRepo) setCommentLike(ctx *gin.Context, userId int64, commentId int64, status uint8) (bool, error) {
+	now := time.Now().UTC().UnixMilli()
+	changed := false
+	err := repo.data.mdb.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 先以取消状态插入占位记录，已存在时不做处理，再加锁读取并转换状态
+		// 避免记录不存在时加锁读取产生间隙锁，并发的首次点赞互相等待而死锁或插入重复记录
+		if status != commentLikeStatusCanceled {
+			err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&CommentLike{
+				UserId:      userId,
+				CommentId:   commentId,
+				Status:      commentLikeStatusCanceled,
+				CreatedTime: now,
+				UpdatedTime: now,
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+		record := &CommentLike{}
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id=? and comment_id=?", userId, commentId).First(record).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// 取消点赞时没有记录，无需处理
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if record.Status == status {
+			return nil
+		}
+		err = tx.Model(record).Updates(map[string]any{
+			"status":       status,
+			"updated_time": now,
+		}).Error
+		if err != nil {
+			return err
+		}
+		changed = true
+		expr := gorm.Expr("like_cnt + 1")
+		if status == commentLikeStatusCanceled {
+			expr = gorm.Expr("like_cnt - 1")
+		}
+		return tx.Model(&Comment{}).Where("id=?", commentId).Update("like_cnt", expr).Error
+	})
+	if err != nil {
+		return false, err
+	}
+	return changed, nil
+}
+
+func (repo *commentRepo) LikedComments(ctx *gin.Context, userId int64, commentIds []int64) (map[int64]bool, error) {
+	res := make(map[int64]bool, len(commentIds))
+	if len(commentIds) == 0 {
+		return res, nil
+	}
+	var liked []int64
+	err := repo.data.mdb.WithContext(ctx).Model(&CommentLike{}).
+		Where("user_id=? and comment_id in ? and status=?", userId, commentIds, commentLikeStatusActive).
+		Pluck("comment_id", &liked).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range liked {
+		res[id] = true
+	}
+	return res, nil
+}
+
+func (repo *commentRepo) commentsQuery(ctx *gin.Context) *gorm.DB {
+	return repo.data.mdb.WithContext(ctx).Model(&Comment{}).
+		Select("comments.*, users.nick_name AS user_name").
+		Joins("LEFT JOIN users ON users.id = comments.user_id")
+}
+
+func (cache *commentCache) GetFirstPage(ctx *gin.Context, articleId int64, sort service.CommentSort) ([]*service.Comment, error) {
+	bs, err := cache.data.rdb.Get(ctx, commentFirstPageKey(articleId, sort)).Bytes()
+	if err != nil {
+		return nil, err
+	}
+	comments := []*service.Comment{}
+	if err = json.Unmarshal(bs, &comments); err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+func (cache *commentCache) SetFirstPage(ctx *gin.Context, articleId int64, sort service.CommentSort, comments []*service.Comment) error {
+	if comments == nil {
+		comments = []*service.Comment{}
+	}
+	bs, err := json.Marshal(comments)
+	if err != nil {
+		return err
+	}
+	return cache.data.rdb.Set(ctx, commentFirstPageKey(articleId, sort), bs, time.Minute*5).Err()
+}
+
+func (cache *commentCache) DelFirstPage(ctx *gin.Context, articleId int64) error {
+	return cache.data.rdb.Del(ctx,
+		commentFirstPageKey(articleId, service.CommentSortTime),
+		commentFirstPageKey(articleId, service.CommentSortLike)).Err()
+}
+
+func commentFirstPageKey(articleId int64, sort service.CommentSort) string {
+	return fmt.Sprintf("comment:first_page:%d:%d", articleId, sort)
+}
+
+func toServiceComments(rows []commentRow) []*service.Comment {
+	res := make([]*service.Comment, 0, len(rows))
+	for i := range rows {
+		res = append(res, toServiceComment(&rows[i]))
+	}
+	return res
+}
+
+func toServiceComment(row *commentRow) *service.Comment {
+	return &service.Comment{
+		Id:          row.Id,
+		ArticleId:   row.ArticleId,
+		UserId:      row.UserId,
+		UserName:    row.UserName,
+		RootId:      row.RootId,
+		ParentId:    row.ParentId,
+		ReplyToUid:  row.ReplyToUid,
+		Content:     row.Content,
+		LikeCnt:     row.LikeCnt,
+		ReplyCnt:    row.ReplyCnt,
+		CreatedTime: row.CreatedTime,
+	}
+}
@@ -9,11 +9,16 @@ import (
 	"ibook/internal/conf"
 	"ibook/internal/data/message/sms/ratelimit"
 	"log"
+	"unicode/utf8"
 )
 
 // DataProviderSet is data providers.
 var DataProviderSet = wire.NewSet(NewData,
-	NewUserRepo, NewVerifyCodeRepo, NewArticleReaderRepo, NewArticleAuthorRepo, NewArticleSyncRepo, NewUserCache, NewMDB, NewRDB, ratelimit.NewRateLimitSmsRepo)
+	NewUserRepo, NewVerifyCodeRepo, NewArticleReaderRepo, NewArticleAuthorRepo, NewArticleSyncRepo, NewUserCache, NewMDB, NewRDB, ratelimit.NewRateLimitSmsRepo,
+	NewArticleInteractiveRepo, NewArticleInteractiveCache, NewArticleCollectRepo, NewArticleRevisionRepo, NewArticleScheduleRepo, NewArticleTagRepo,
+	NewArticleSearchRepo, NewArticleHotRepo, NewCommentRepo, NewCommentCache, NewArticleRecycleRepo, NewArticleOutboxRepo,
+	NewArticleReconcileRepo, NewArticleLikeRepo, NewBlobStore, NewBlobRepo, NewFollowRepo, NewFollowCache, NewFeedRepo,
+	NewEmailRepo, NewUserTokenRepo)
 
 type Data struct {
 	rdb redis.Cmdable
@@ -31,7 +36,8 @@ func NewData(mdb *gorm.DB, rdb redis.Cmdable) (*Data, func()) {
 }
 
 func NewMDB(mConf *conf.MySQL) *gorm.DB {
-	db, err := gorm.Open(mysql.Open(mConf.DSN))
+	// 开启 TranslateError，使唯一键冲突能以 gorm.ErrDuplicatedKey 的形式返回
+	db, err := gorm.Open(mysql.Open(mConf.DSN), &gorm.Config{TranslateError: true})
 	if err != nil {
 		panic("初始化 MySQL 连接失败: " + err.Error())
 	}
@@ -56,5 +62,19 @@ func NewRDB(rConf *conf.Redis) redis.Cmdable {
 }
 
 func initTable(db *gorm.DB) error {
-	return db.AutoMigrate(&User{}, ArticleAuthor{}, ArticleReader{}, Interactive{}, LikeRecord{})
+	return db.AutoMigrate(&User{}, ArticleAuthor{}, ArticleReader{}, Interactive{}, LikeRecord{},
+		CollectFolder{}, CollectRecord{}, ArticleRevision{}, ArticleSchedule{},
+		Tag{}, AuthorArticleTag{}, ReaderArticleTag{}, Comment{}, CommentLike{}, ArticleOutbox{},
+		FollowRelation{}, FollowStatistic{})
+}
+
+// lastErrMaxLen 错误信息列 varchar(1024) 可保存的最大字符数
+const lastErrMaxLen = 1024
+
+// truncateRunes 按字符截断字符串，避免在多字节字符中间截断导致写入 MySQL 失败
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
 }
@@ -0,0 +1,19 @@
+package data
+
+import (
+	"ibook/internal/conf"
+	"ibook/internal/data/message/email/smtp"
+	"ibook/internal/service/message/email"
+)
+
+// NewEmailRepo 通过 SMTP 发送邮件，未配置 SMTP 服务器时启动失败，避免重置密码的验证码无法送达或被打印到日志中
+func NewEmailRepo(eConf *conf.Email) email.EmailRepo {
+	if eConf == nil || eConf.SMTP == nil || eConf.SMTP.Host == "" {
+		panic("初始化邮件服务失败: 未配置 SMTP 服务器")
+	}
+	repo, err := smtp.NewSMTPEmailRepo(eConf.SMTP)
+	if err != nil {
+		panic("初始化邮件服务失败: " + err.Error())
+	}
+	return repo
+}
@@ -0,0 +1,105 @@
+package data
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"ibook/internal/service"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	// feedInboxSize 每个收件箱保留的文章数量，更早的文章不再出现在关注流中
+	feedInboxSize = 1000
+	// feedInboxTTL 长期没有收到新文章的收件箱会过期
+	feedInboxTTL = 30 * 24 * time.Hour
+)
+
+type feedRepo struct {
+	data *Data
+}
+
+func NewFeedRepo(data *Data) service.FeedRepo {
+	return &feedRepo{data: data}
+}
+
+func (repo *feedRepo) PushInbox(ctx *gin.Context, userIds []int64, item *service.FeedItem) error {
+	member := feedMember(item)
+	pipe := repo.data.rdb.Pipeline()
+	for _, userId := range userIds {
+		key := feedInboxKey(userId)
+		pipe.ZAdd(ctx, key, redis.Z{Score: float64(item.PublishedTime), Member: member})
+		pipe.ZRemRangeByRank(ctx, key, 0, -feedInboxSize-1)
+		pipe.Expire(ctx, key, feedInboxTTL)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (repo *feedRepo) ListInbox(ctx *gin.Context, userId int64, cursor service.ListCursor, limit int64) ([]*service.FeedItem, error) {
+	key := feedInboxKey(userId)
+	if cursor.IsZero() {
+		zs, err := repo.data.rdb.ZRevRangeByScoreWithScores(ctx, key, &redis.ZRangeBy{
+			Min: "-inf", Max: "+inf", Count: limit,
+		}).Result()
+		if err != nil {
+			return nil, err
+		}
+		return parseFeedItems(zs), nil
+	}
+	// 1. 与游标发表时间相同的文章按 id 过滤，同一毫秒内发表的文章很少，全部取出
+	score := strconv.FormatInt(cursor.Value, 10)
+	ties, err := repo.data.rdb.ZRevRangeByScoreWithScores(ctx, key, &redis.ZRangeBy{
+		Min: score, Max: score,
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*service.FeedItem, 0, limit)
+	for _, item := range parseFeedItems(ties) {
+		if item.ArticleId < cursor.Id {
+			res = append(res, item)
+		}
+	}
+	if int64(len(res)) >= limit {
+		return res[:limit], nil
+	}
+	// 2. 更早发表的文章
+	zs, err := repo.data.rdb.ZRevRangeByScoreWithScores(ctx, key, &redis.ZRangeBy{
+		Min: "-inf", Max: "(" + score, Count: limit - int64(len(res)),
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+	return append(res, parseFeedItems(zs)...), nil
+}
+
+// parseFeedItems 按发表时间与文章 id 倒序返回，跳过无法解析的成员
+func parseFeedItems(zs []redis.Z) []*service.FeedItem {
+	res := make([]*service.FeedItem, 0, len(zs))
+	for _, z := range zs {
+		item := &service.FeedItem{PublishedTime: int64(z.Score)}
+		if _, err := fmt.Sscanf(z.Member, "%d:%d", &item.ArticleId, &item.AuthorId); err != nil {
+			continue
+		}
+		res = append(res, item)
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		if res[i].PublishedTime != res[j].PublishedTime {
+			return res[i].PublishedTime > res[j].PublishedTime
+		}
+		return res[i].ArticleId > res[j].ArticleId
+	})
+	return res
+}
+
+// feedMember 文章 id 补零到固定宽度，使发表时间相同的成员按字典序排列时与按 id 排列一致
+func feedMember(item *service.FeedItem) string {
+	return fmt.Sprintf("%019d:%d", item.ArticleId, item.AuthorId)
+}
+
+func feedInboxKey(userId int64) string {
+	return fmt.Sprintf("feed:inbox:%d", userId)
+}
@@ -0,0 +1,306 @@
+package data
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"ibook/internal/service"
+	"ibook/pkg/utils/bskit/slice"
+	"time"
+)
+
+const (
+	followStatusActive   = 1
+	followStatusCanceled = 0
+
+	followStatisticTTL = 10 * time.Minute
+)
+
+// FollowRelation 关注关系，每对 (follower, followee) 只有一条记录，取消关注时只修改状态
+type FollowRelation struct {
+	Id         int64 `gorm:"primaryKey,autoIncrement"`
+	FollowerId int64 `gorm:"uniqueIndex:follower_followee,priority:1;index:follower_status_utime,priority:1"`
+	FolloweeId int64 `gorm:"uniqueIndex:follower_followee,priority:2;index:followee_status_utime,priority:1"`
+	Status     uint8 `gorm:"index:follower_status_utime,priority:2;index:followee_status_utime,priority:2"`
+	// 按关注时间分页查询粉丝列表与关注列表
+	UpdatedTime int64 `gorm:"index:follower_status_utime,priority:3;index:followee_status_utime,priority:3"`
+	CreatedTime int64
+}
+
+// FollowStatistic 用户的粉丝数与关注数
+type FollowStatistic struct {
+	Id           int64 `gorm:"primaryKey,autoIncrement"`
+	UserId       int64 `gorm:"unique"`
+	FollowerCnt  int64
+	FollowingCnt int64
+	CreatedTime  int64
+	UpdatedTime  int64
+}
+
+// followRow 关注关系及列表中展示的用户资料
+type followRow struct {
+	Id          int64
+	FollowerId  int64
+	FolloweeId  int64
+	NickName    string
+	AvatarUrl   string
+	UpdatedTime int64
+}
+
+type followRepo struct {
+	data *Data
+}
+
+type followCache struct {
+	data *Data
+}
+
+func NewFollowRepo(data *Data) service.FollowRepo {
+	return &followRepo{data: data}
+}
+
+func NewFollowCache(data *Data) service.FollowCache {
+	return &followCache{data: data}
+}
+
+func (repo *followRepo) Follow(ctx *gin.Context, followerId int64, followeeId int64) (bool, error) {
+	now := time.Now().UTC().UnixMilli()
+	changed := false
+	err := repo.data.mdb.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 先以取消状态插入占位记录，已存在时不做处理，再加锁读取旧记录以判断计数是否需要变化
+		// 避免记录不存在时加锁读取产生间隙锁，并发的首次关注互相等待而死锁或插入重复记录
+		err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&FollowRelation{
+			FollowerId:  followerId,
+			FolloweeId:  followeeId,
+			Status:      followStatusCanceled,
+			CreatedTime: now,
+			UpdatedTime: now,
+		}).Error
+		if err != nil {
+			return err
+		}
+		relation := &FollowRelation{}
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("follower_id=? and followee_id=?", followerId, followeeId).First(relation).Error
+		if err != nil {
+			return err
+		}
+		if relation.Status == followStatusActive {
+			return nil
+		}
+		err = tx.Model(relation).Updates(map[string]any{
+			"status":       followStatusActive,
+			"updated_time": now,
+		}).Error
+		if err != nil {
+			return err
+		}
+		changed = true
+		return incrFollowStatistic(tx, followerId, followeeId, 1, now)
+	})
+	return changed, err
+}
+
+func (repo *followRepo) Unfollow(ctx *gin.Context, followerId int64, followeeId int64) (bool, error) {
+	now := time.Now().UTC().UnixMilli()
+	changed := false
+	err := repo.data.mdb.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&FollowRelation{}).
+			Where("follower_id=? and followee_id=? and status=?", followerId, followeeId, followStatusActive).
+			Updates(map[string]any{
+				"status":       followStatusCanceled,
+				"updated_time": now,
+			})
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		changed = true
+		return incrFollowStatistic(tx, followerId, followeeId, -1, now)
+	})
+	return changed, err
+}
+
+// incrFollowStatistic 同时更新关注者的关注数与被关注者的粉丝数
+func incrFollowStatistic(tx *gorm.DB, followerId int64, followeeId int64, delta int64, now int64) error {
+	err := tx.Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]any{
+			"following_cnt": gorm.Expr("following_cnt + ?", delta),
+			"updated_time":  now,
+		}),
+	}).Create(&FollowStatistic{
+		UserId:       followerId,
+		FollowingCnt: max(delta, 0),
+		CreatedTime:  now,
+		UpdatedTime:  now,
+	}).Error
+	if err != nil {
+		return err
+	}
+	return tx.Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]any{
+			"follower_cnt": gorm.Expr("follower_cnt + ?", delta),
+			"updated_time": now,
+		}),
+	}).Create(&FollowStatistic{
+		UserId:      followeeId,
+		FollowerCnt: max(delta, 0),
+		CreatedTime: now,
+		UpdatedTime: now,
+	}).Error
+}
+
+func (repo *followRepo) Followed(ctx *gin.Context, followerId int64, followeeId int64) (bool, error) {
+	var cnt int64
+	err := repo.data.mdb.WithContext(ctx).Model(&FollowRelation{}).
+		Where("follower_id=? and followee_id=? and status=?", followerId, followeeId, followStatusActive).
+		Count(&cnt).Error
+	return cnt > 0, err
+}
+
+func (repo *followRepo) GetStatistic(ctx *gin.Context, userId int64) (*service.FollowStatistic, error) {
+	var rows []FollowStatistic
+	err := repo.data.mdb.WithContext(ctx).Where("user_id=?", userId).Limit(1).Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	stat := &service.FollowStatistic{UserId: userId}
+	if len(rows) > 0 {
+		stat.FollowerCnt = rows[0].FollowerCnt
+		stat.FollowingCnt = rows[0].FollowingCnt
+	}
+	return stat, nil
+}
+
+func (repo *followRepo) ListFollowers(ctx *gin.Context, userId int64, cursor service.ListCursor, limit int64) ([]*service.FollowRelation, error) {
+	var rows []followRow
+	query := repo.followQuery(ctx, "follower_id").
+		Where("follow_relations.followee_id=? and follow_relations.status=?", userId, followStatusActive)
+	err := withFollowCursor(query, cursor).
+		Order("follow_relations.updated_time desc, follow_relations.id desc").
+		Limit(int(limit)).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return toServiceFollowRelations(rows), nil
+}
+
+func (repo *followRepo) ListFollowees(ctx *gin.Context, userId int64, cursor service.ListCursor, limit int64) ([]*service.FollowRelation, error) {
+	var rows []followRow
+	query := repo.followQuery(ctx, "followee_id").
+		Where("follow_relations.follower_id=? and follow_relations.status=?", userId, followStatusActive)
+	err := withFollowCursor(query, cursor).
+		Order("follow_relations.updated_time desc, follow_relations.id desc").
+		Limit(int(limit)).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return toServiceFollowRelations(rows), nil
+}
+
+func (repo *followRepo) ListFollowerIds(ctx *gin.Context, userId int64, afterId int64, limit int64) ([]int64, error) {
+	var ids []int64
+	err := repo.data.mdb.WithContext(ctx).Model(&FollowRelation{}).
+		Where("followee_id=? and status=? and follower_id>?", userId, followStatusActive, afterId).
+		Order("follower_id").
+		Limit(int(limit)).
+		Pluck("follower_id", &ids).Error
+	return ids, err
+}
+
+func (repo *followRepo) ListPopularFollowees(ctx *gin.Context, userId int64, minFollowers int64) ([]int64, error) {
+	var ids []int64
+	err := repo.data.mdb.WithContext(ctx).Model(&FollowRelation{}).
+		Joins("JOIN follow_statistics ON follow_statistics.user_id = follow_relations.followee_id").
+		Where("follow_relations.follower_id=? and follow_relations.status=? and follow_statistics.follower_cnt>=?",
+			userId, followStatusActive, minFollowers).
+		Pluck("follow_relations.followee_id", &ids).Error
+	return ids, err
+}
+
+func (repo *followRepo) FilterFollowed(ctx *gin.Context, followerId int64, followeeIds []int64) ([]int64, error) {
+	if len(followeeIds) == 0 {
+		return []int64{}, nil
+	}
+	var ids []int64
+	err := repo.data.mdb.WithContext(ctx).Model(&FollowRelation{}).
+		Where("follower_id=? and followee_id in ? and status=?", followerId, followeeIds, followStatusActive).
+		Pluck("followee_id", &ids).Error
+	return ids, err
+}
+
+// followQuery 关联 userColumn 对应的用户，列表中展示其昵称与头像
+func (repo *followRepo) followQuery(ctx *gin.Context, userColumn string) *gorm.DB {
+	return repo.data.mdb.WithContext(ctx).Model(&FollowRelation{}).
+		Select("follow_relations.id, follow_relations.follower_id, follow_relations.followee_id, " +
+			"users.nick_name, users.avatar_url, follow_relations.updated_time").
+		Joins("LEFT JOIN users ON users.id = follow_relations." + userColumn)
+}
+
+func withFollowCursor(query *gorm.DB, cursor service.ListCursor) *gorm.DB {
+	if cursor.IsZero() {
+		return query
+	}
+	return query.Where("follow_relations.updated_time < ? or (follow_relations.updated_time = ? and follow_relations.id < ?)",
+		cursor.Value, cursor.Value, cursor.Id)
+}
+
+func toServiceFollowRelations(rows []followRow) []*service.FollowRelation {
+	return slice.Map[followRow, *service.FollowRelation](rows, func(idx int, src followRow) *service.FollowRelation {
+		return &service.FollowRelation{
+			Id:           src.Id,
+			FollowerId:   src.FollowerId,
+			FolloweeId:   src.FolloweeId,
+			NickName:     src.NickName,
+			AvatarUrl:    src.AvatarUrl,
+			FollowedTime: src.UpdatedTime,
+		}
+	})
+}
+
+func (cache *followCache) GetStatistic(ctx *gin.Context, userId int64) (*service.FollowStatistic, error) {
+	bs, err := cache.data.rdb.Get(ctx, followStatisticKey(userId)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, service.FollowStatisticNotInCacheErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	stat := &service.FollowStatistic{}
+	if err = json.Unmarshal(bs, stat); err != nil {
+		return nil, err
+	}
+	return stat, nil
+}
+
+func (cache *followCache) SetStatistic(ctx *gin.Context, stat *service.FollowStatistic) error {
+	// 访问者的关注状态不写入缓存
+	bs, err := json.Marshal(&service.FollowStatistic{
+		UserId:       stat.UserId,
+		FollowerCnt:  stat.FollowerCnt,
+		FollowingCnt: stat.FollowingCnt,
+	})
+	if err != nil {
+		return err
+	}
+	return cache.data.rdb.Set(ctx, followStatisticKey(stat.UserId), bs, followStatisticTTL).Err()
+}
+
+func (cache *followCache) DelStatistic(ctx *gin.Context, userIds ...int64) error {
+	if len(userIds) == 0 {
+		return nil
+	}
+	keys := slice.Map[int64, string](userIds, func(idx int, src int64) string {
+		return followStatisticKey(src)
+	})
+	return cache.data.rdb.Del(ctx, keys...).Err()
+}
+
+func followStatisticKey(userId int64) string {
+	return fmt.Sprintf("user:follow_stat:%d", userId)
+}
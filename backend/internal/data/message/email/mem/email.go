@@ -0,0 +1,42 @@
+package mem
+
+import (
+	"github.com/gin-gonic/gin"
+	"ibook/internal/service/message/email"
+	"sync"
+)
+
+// Message 一封已“发送”的邮件
+type Message struct {
+	TplId     string
+	Addresses []string
+	Args      []email.MsgArgs
+}
+
+// MemEmailRepo 将邮件保存在内存中，仅用于测试，不会输出邮件内容
+type MemEmailRepo struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+func NewMemEmailRepo() *MemEmailRepo {
+	return &MemEmailRepo{}
+}
+
+func (r *MemEmailRepo) SendMessage(ctx *gin.Context, tplId string, addresses []string, args []email.MsgArgs) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messages = append(r.messages, Message{
+		TplId:     tplId,
+		Addresses: append([]string(nil), addresses...),
+		Args:      append([]email.MsgArgs(nil), args...),
+	})
+	return nil
+}
+
+// Messages 返回已发送邮件的副本，按发送顺序排列
+func (r *MemEmailRepo) Messages() []Message {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Message(nil), r.messages...)
+}
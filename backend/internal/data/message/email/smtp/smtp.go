@@ -0,0 +1,169 @@
+package smtp
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"ibook/internal/conf"
+	"ibook/internal/service/message/email"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"text/template"
+	"time"
+)
+
+const defaultTimeout = 10 * time.Second
+
+type mailTemplate struct {
+	subject string
+	body    *template.Template
+}
+
+// templates 邮件模板，参数通过 {{.name}} 引用
+var templates = map[string]*mailTemplate{
+	email.TplResetPassword: {
+		subject: "iBook 重置密码验证码",
+		body: template.Must(template.New(email.TplResetPassword).Parse(
+			"你正在重置 iBook 账号的密码，验证码为：{{.code}}\r\n\r\n" +
+				"验证码 {{.minutes}} 分钟内有效。如果不是你本人操作，请忽略这封邮件，你的密码不会被修改。\r\n")),
+	},
+}
+
+type smtpEmailRepo struct {
+	conf    *conf.SMTP
+	from    *mail.Address
+	timeout time.Duration
+}
+
+func NewSMTPEmailRepo(c *conf.SMTP) (email.EmailRepo, error) {
+	fromAddr := c.From
+	if fromAddr == "" {
+		fromAddr = c.Username
+	}
+	from, err := mail.ParseAddress(fromAddr)
+	if err != nil {
+		return nil, fmt.Errorf("发件人地址不合法：%w", err)
+	}
+	timeout := time.Duration(c.TimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	return &smtpEmailRepo{conf: c, from: from, timeout: timeout}, nil
+}
+
+// SendMessage 每个收件人单独发送一封邮件，避免收件人之间互相可见
+func (r *smtpEmailRepo) SendMessage(ctx *gin.Context, tplId string, addresses []string, args []email.MsgArgs) error {
+	tpl, ok := templates[tplId]
+	if !ok {
+		return fmt.Errorf("%w: 未知的邮件模板 %s", email.EmailUnKnownErr, tplId)
+	}
+	data := make(map[string]string, len(args))
+	for _, arg := range args {
+		data[arg.Name] = arg.Value
+	}
+	body := &bytes.Buffer{}
+	if err := tpl.body.Execute(body, data); err != nil {
+		return fmt.Errorf("%w: 渲染邮件失败：%v", email.EmailUnKnownErr, err)
+	}
+	for _, address := range addresses {
+		to, err := mail.ParseAddress(address)
+		if err != nil {
+			return fmt.Errorf("%w: 收件人地址不合法：%v", email.EmailUnKnownErr, err)
+		}
+		msg := buildMessage(r.from, to, tpl.subject, body.Bytes(), time.Now())
+		if err = r.send(ctx, to.Address, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *smtpEmailRepo) send(ctx *gin.Context, to string, msg []byte) error {
+	addr := net.JoinHostPort(r.conf.Host, strconv.Itoa(r.conf.Port))
+	deadline := time.Now().Add(r.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	dialer := &net.Dialer{Deadline: deadline}
+	var conn net.Conn
+	var err error
+	if r.conf.Port == 465 {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, &tls.Config{ServerName: r.conf.Host})
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+	// 整个会话共用一个截止时间，避免服务端无响应时一直阻塞
+	if err = conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return err
+	}
+	client, err := smtp.NewClient(conn, r.conf.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+	if ok, _ := client.Extension("STARTTLS"); ok && r.conf.Port != 465 {
+		if err = client.StartTLS(&tls.Config{ServerName: r.conf.Host}); err != nil {
+			return err
+		}
+	}
+	if r.conf.Username != "" {
+		if ok, _ := client.Extension("AUTH"); ok {
+			// PlainAuth 只允许在 TLS 连接或本机地址上发送密码
+			if err = client.Auth(smtp.PlainAuth("", r.conf.Username, r.conf.Password, r.conf.Host)); err != nil {
+				return err
+			}
+		}
+	}
+	if err = client.Mail(r.from.Address); err != nil {
+		return err
+	}
+	if err = client.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err = w.Write(msg); err != nil {
+		w.Close()
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// buildMessage 生成纯文本邮件，主题按 RFC 2047 编码，正文使用 base64 编码以支持中文
+func buildMessage(from *mail.Address, to *mail.Address, subject string, body []byte, now time.Time) []byte {
+	buf := &bytes.Buffer{}
+	writeHeader := func(name, value string) {
+		buf.WriteString(name + ": " + value + "\r\n")
+	}
+	writeHeader("From", from.String())
+	writeHeader("To", to.String())
+	writeHeader("Subject", mime.BEncoding.Encode("utf-8", subject))
+	writeHeader("Date", now.Format(time.RFC1123Z))
+	writeHeader("MIME-Version", "1.0")
+	writeHeader("Content-Type", "text/plain; charset=utf-8")
+	writeHeader("Content-Transfer-Encoding", "base64")
+	buf.WriteString("\r\n")
+	encoded := base64.StdEncoding.EncodeToString(body)
+	// 每行不超过 76 个字符
+	for len(encoded) > 76 {
+		buf.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	buf.WriteString(encoded + "\r\n")
+	return buf.Bytes()
+}
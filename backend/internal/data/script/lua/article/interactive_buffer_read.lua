@@ -0,0 +1,25 @@
+-- 去重 key -> article:read_dedup:{articleId}:{viewer}
+local dedupKey = KEYS[1]
+-- 缓冲区 hashmap_key -> article:read_buffer
+local bufferKey = KEYS[2]
+-- hashmap_key -> article:interactive:{articleId}
+local key = KEYS[3]
+-- HyperLogLog -> article:readers:{articleId}
+local readersKey = KEYS[4]
+-- 缓冲区中的字段为文章 id
+local articleId = ARGV[1]
+-- 访问者标识，登录用户为 u:{userId}，否则为 ip:{ip}
+local viewer = ARGV[2]
+-- 去重窗口，毫秒
+local window = tonumber(ARGV[3])
+redis.call("PFADD", readersKey, viewer)
+-- 窗口内同一访问者重复阅读不计数
+local ok = redis.call("SET", dedupKey, 1, "NX", "PX", window)
+if not ok then
+    return 0
+end
+redis.call("HINCRBY", bufferKey, articleId, 1)
+if redis.call("EXISTS", key) == 1 then
+    redis.call("HINCRBY", key, "read_cnt", 1)
+end
+return 1
@@ -1,6 +1,6 @@
 -- hashmap_key -> article:{articleId}:{fieldName}
 local key = KEYS[1]
--- obj_key -> "read_cnt" / "collect_cnt" / "like_cnt"
+-- obj_key -> "read_cnt" / "collect_cnt" / "like_cnt" / "dislike_cnt"
 local cntKey = ARGV[1]
 -- +1 or -1
 local delta = tonumber(ARGV[2])
@@ -0,0 +1,19 @@
+-- hashmap_key -> article:interactive:{articleId}
+local key = KEYS[1]
+-- 尚未写入数据库的阅读计数 -> article:read_buffer 与 article:read_buffer:flushing
+local bufferKey = KEYS[2]
+local flushingKey = KEYS[3]
+-- 过期时间，毫秒
+local ttl = tonumber(ARGV[1])
+-- 已存在时说明其他请求已经加载过，并且可能已经累加了计数，不再覆盖
+local exists = redis.call("EXISTS", key)
+if exists == 1 then
+    return 0
+end
+-- 数据库中的阅读数不含缓冲区中的计数，加上后再写入缓存，避免刷写前展示的阅读数倒退
+local readCnt = tonumber(ARGV[2])
+    + tonumber(redis.call("HGET", bufferKey, ARGV[7]) or 0)
+    + tonumber(redis.call("HGET", flushingKey, ARGV[7]) or 0)
+redis.call("HSET", key, "read_cnt", readCnt, "like_cnt", ARGV[3], "collect_cnt", ARGV[4], "comment_cnt", ARGV[5], "dislike_cnt", ARGV[6])
+redis.call("PEXPIRE", key, ttl)
+return 1
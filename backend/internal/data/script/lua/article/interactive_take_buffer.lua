@@ -0,0 +1,20 @@
+-- 处理中 hashmap_key -> article:read_buffer:flushing
+local flushingKey = KEYS[1]
+-- 缓冲区 hashmap_key -> article:read_buffer
+local bufferKey = KEYS[2]
+-- 处理中计数的批次号 -> article:read_buffer:batch
+local batchKey = KEYS[3]
+-- 缓冲区移入处理中时使用的新批次号
+local batchId = ARGV[1]
+-- 上一轮的计数未处理完时继续处理，沿用原来的批次号
+if redis.call("EXISTS", flushingKey) == 1 then
+    redis.call("SET", batchKey, batchId, "NX")
+    return redis.call("GET", batchKey)
+end
+-- 缓冲区为空时 key 不存在，没有待写入的计数
+if redis.call("EXISTS", bufferKey) == 0 then
+    return ""
+end
+redis.call("RENAME", bufferKey, flushingKey)
+redis.call("SET", batchKey, batchId)
+return batchId
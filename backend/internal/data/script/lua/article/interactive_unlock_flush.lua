@@ -0,0 +1,8 @@
+-- lock_key -> article:read_buffer:lock
+local key = KEYS[1]
+-- 加锁时写入的随机值，只有持有者才能释放，避免锁过期后删掉其他副本的锁
+local token = ARGV[1]
+if redis.call("GET", key) == token then
+    return redis.call("DEL", key)
+end
+return 0
@@ -20,8 +20,13 @@ type User struct {
 	PhoneNumber sql.NullString `gorm:"unique"`
 	NickName    string         `gorm:"unique"`
 	Password    string
-	CreatedTime int64
-	UpdatedTime int64
+	Bio         string `gorm:"type:varchar(1024)"`
+	// Birthday 格式为 2006-01-02，未设置时为空
+	Birthday            string `gorm:"type:varchar(10)"`
+	AvatarUrl           string `gorm:"type:varchar(512)"`
+	NickNameUpdatedTime int64
+	CreatedTime         int64
+	UpdatedTime         int64
 }
 
 type userRepo struct {
@@ -70,13 +75,7 @@ func (ur *userRepo) FindUserByEmail(email string) (*service.User, error) {
 		}
 		return nil, res.Error
 	}
-	return &service.User{
-		Id:          u.Id,
-		Email:       u.Email.String,
-		PhoneNumber: u.PhoneNumber.String,
-		PassWord:    u.Password,
-		NickName:    u.NickName,
-	}, nil
+	return toServiceUser(u), nil
 }
 
 func (ur *userRepo) FindUserById(userId int64) (*service.User, error) {
@@ -88,13 +87,7 @@ func (ur *userRepo) FindUserById(userId int64) (*service.User, error) {
 		}
 		return nil, res.Error
 	}
-	return &service.User{
-		Id:          u.Id,
-		Email:       u.Email.String,
-		PhoneNumber: u.PhoneNumber.String,
-		PassWord:    u.Password,
-		NickName:    u.NickName,
-	}, nil
+	return toServiceUser(u), nil
 }
 
 func (ur *userRepo) FindUserByPhone(number string) (*service.User, error) {
@@ -106,13 +99,66 @@ func (ur *userRepo) FindUserByPhone(number string) (*service.User, error) {
 		}
 		return nil, res.Error
 	}
+	return toServiceUser(u), nil
+}
+
+func (ur *userRepo) UpdatePassword(ctx *gin.Context, userId int64, password string) error {
+	res := ur.db.mdb.WithContext(ctx).Model(&User{}).Where("id=?", userId).Updates(map[string]any{
+		"password":     password,
+		"updated_time": time.Now().UTC().UnixMilli(),
+	})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return service.UserNotExistsErr
+	}
+	return nil
+}
+
+func (ur *userRepo) UpdateProfile(ctx *gin.Context, userId int64, edit *service.UserProfileEdit) error {
+	now := time.Now().UTC().UnixMilli()
+	updates := map[string]any{
+		"updated_time": now,
+	}
+	if edit.NickName != nil {
+		updates["nick_name"] = *edit.NickName
+		updates["nick_name_updated_time"] = now
+	}
+	if edit.Bio != nil {
+		updates["bio"] = *edit.Bio
+	}
+	if edit.Birthday != nil {
+		updates["birthday"] = *edit.Birthday
+	}
+	if edit.AvatarUrl != nil {
+		updates["avatar_url"] = *edit.AvatarUrl
+	}
+	res := ur.db.mdb.WithContext(ctx).Model(&User{}).Where("id=?", userId).Updates(updates)
+	if res.Error != nil {
+		if errors.Is(res.Error, gorm.ErrDuplicatedKey) {
+			return service.NickNameExistsErr
+		}
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return service.UserNotExistsErr
+	}
+	return nil
+}
+
+func toServiceUser(u *User) *service.User {
 	return &service.User{
-		Id:          u.Id,
-		Email:       u.Email.String,
-		PhoneNumber: u.PhoneNumber.String,
-		PassWord:    u.Password,
-		NickName:    u.NickName,
-	}, nil
+		Id:                  u.Id,
+		Email:               u.Email.String,
+		PhoneNumber:         u.PhoneNumber.String,
+		PassWord:            u.Password,
+		NickName:            u.NickName,
+		Bio:                 u.Bio,
+		Birthday:            u.Birthday,
+		AvatarUrl:           u.AvatarUrl,
+		NickNameUpdatedTime: u.NickNameUpdatedTime,
+	}
 }
 
 type userCache struct {
@@ -154,3 +200,7 @@ func (u *userCache) SetUserById(ctx *gin.Context, user *service.User) error {
 	}
 	return nil
 }
+
+func (u *userCache) DelUserById(ctx *gin.Context, userId int64) error {
+	return u.rdb.Del(ctx, fmt.Sprintf("user:info:%d", userId)).Err()
+}
@@ -0,0 +1,45 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"ibook/internal/conf"
+	"ibook/internal/service"
+	"strconv"
+	"time"
+)
+
+type userTokenRepo struct {
+	rdb redis.Cmdable
+	// keep 吊销记录的保留时长，超过令牌有效期后旧令牌已自然过期，记录不再需要
+	keep time.Duration
+}
+
+func NewUserTokenRepo(rdb redis.Cmdable, secret *conf.Secret) service.UserTokenRepo {
+	keep := 24 * time.Hour
+	if secret != nil && secret.JwtConf != nil && secret.JwtConf.LifeDurationTime > 0 {
+		keep = time.Duration(secret.JwtConf.LifeDurationTime)*time.Second + time.Minute
+	}
+	return &userTokenRepo{rdb: rdb, keep: keep}
+}
+
+func (repo *userTokenRepo) RevokeTokens(ctx *gin.Context, userId int64, before int64) error {
+	return repo.rdb.Set(ctx, userTokenRevokedKey(userId), before, repo.keep).Err()
+}
+
+func (repo *userTokenRepo) RevokedBefore(ctx *gin.Context, userId int64) (int64, error) {
+	res, err := repo.rdb.Get(ctx, userTokenRevokedKey(userId)).Result()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(res, 10, 64)
+}
+
+func userTokenRevokedKey(userId int64) string {
+	return fmt.Sprintf("user:token_revoked:%d", userId)
+}
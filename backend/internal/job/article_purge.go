@@ -0,0 +1,53 @@
+package job
+
+import (
+	"github.com/gin-gonic/gin"
+	"ibook/internal/service"
+	"ibook/pkg/utils/logger"
+	"time"
+)
+
+const (
+	purgeInterval  = time.Hour
+	purgeBatchSize = 100
+)
+
+// ArticlePurgeJob 周期性永久清除回收站中超过保留期限的文章
+type ArticlePurgeJob struct {
+	*runner
+	svc    service.ArticleService
+	logger logger.Logger
+}
+
+func NewArticlePurgeJob(svc service.ArticleService, myLogger logger.Logger) *ArticlePurgeJob {
+	job := &ArticlePurgeJob{
+		svc:    svc,
+		logger: myLogger,
+	}
+	job.runner = newRunner("ArticlePurgeJob", purgeInterval, job.runOnce)
+	return job
+}
+
+func (job *ArticlePurgeJob) runOnce(ctx *gin.Context) {
+	total := 0
+	for ctx.Err() == nil {
+		done, err := job.svc.PurgeExpiredArticles(ctx, purgeBatchSize)
+		total += done
+		if err != nil {
+			job.logger.Error("[ArticlePurgeJob] 清除过期文章失败", logger.Field{
+				Key:   "详情",
+				Value: err,
+			})
+			break
+		}
+		if done < purgeBatchSize {
+			break
+		}
+	}
+	if total > 0 {
+		job.logger.Info("[ArticlePurgeJob] 已清除过期文章", logger.Field{
+			Key:   "数量",
+			Value: total,
+		})
+	}
+}
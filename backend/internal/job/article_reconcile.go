@@ -0,0 +1,59 @@
+package job
+
+import (
+	"github.com/gin-gonic/gin"
+	"ibook/internal/conf"
+	"ibook/internal/service"
+	"ibook/pkg/utils/logger"
+	"time"
+)
+
+const reconcileInterval = 6 * time.Hour
+
+// ArticleReconcileJob 周期性比对作者库与读者库，按配置决定是否自动修复
+type ArticleReconcileJob struct {
+	*runner
+	svc    service.ArticleService
+	repair bool
+	logger logger.Logger
+}
+
+func NewArticleReconcileJob(svc service.ArticleService, aConf *conf.Article, myLogger logger.Logger) *ArticleReconcileJob {
+	job := &ArticleReconcileJob{
+		svc:    svc,
+		repair: aConf != nil && aConf.ReconcileRepair,
+		logger: myLogger,
+	}
+	job.runner = newRunner("ArticleReconcileJob", reconcileInterval, job.runOnce)
+	return job
+}
+
+func (job *ArticleReconcileJob) runOnce(ctx *gin.Context) {
+	report, err := job.svc.ReconcileArticles(ctx, job.repair, lockTTL(reconcileInterval))
+	if err != nil {
+		job.logger.Error("[ArticleReconcileJob] 比对作者库与读者库失败", logger.Field{
+			Key:   "详情",
+			Value: err,
+		})
+		return
+	}
+	// 本轮已由其他副本执行
+	if report == nil {
+		return
+	}
+	fields := []logger.Field{{
+		Key:   "汇总",
+		Value: report.Summary(),
+	}}
+	for _, item := range report.Items {
+		fields = append(fields, logger.Field{
+			Key:   "不一致",
+			Value: item,
+		})
+	}
+	if report.Mismatched > 0 {
+		job.logger.Warn("[ArticleReconcileJob] 作者库与读者库存在不一致", fields...)
+	} else {
+		job.logger.Info("[ArticleReconcileJob] 作者库与读者库一致", fields...)
+	}
+}
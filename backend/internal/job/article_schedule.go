@@ -0,0 +1,46 @@
+package job
+
+import (
+	"github.com/gin-gonic/gin"
+	"ibook/internal/service"
+	"ibook/pkg/utils/logger"
+	"time"
+)
+
+const (
+	scheduleInterval  = 5 * time.Second
+	scheduleBatchSize = 100
+)
+
+// ArticleScheduleJob 周期性执行到期的定时发表/撤回计划，由 service 层保证每个计划只执行一次
+type ArticleScheduleJob struct {
+	*runner
+	svc    service.ArticleService
+	logger logger.Logger
+}
+
+func NewArticleScheduleJob(svc service.ArticleService, myLogger logger.Logger) *ArticleScheduleJob {
+	job := &ArticleScheduleJob{
+		svc:    svc,
+		logger: myLogger,
+	}
+	job.runner = newRunner("ArticleScheduleJob", scheduleInterval, job.runOnce)
+	return job
+}
+
+func (job *ArticleScheduleJob) runOnce(ctx *gin.Context) {
+	for ctx.Err() == nil {
+		done, err := job.svc.ExecuteDueSchedules(ctx, scheduleBatchSize)
+		if err != nil {
+			job.logger.Error("[ArticleScheduleJob] 执行定时计划失败", logger.Field{
+				Key:   "详情",
+				Value: err,
+			})
+			return
+		}
+		// 本批次未处理满时说明已没有积压，等待下一个周期
+		if done < scheduleBatchSize {
+			return
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package job
+
+import (
+	"github.com/gin-gonic/gin"
+	"ibook/internal/service"
+	"ibook/pkg/utils/logger"
+	"time"
+)
+
+const hotRankInterval = 10 * time.Minute
+
+// HotRankJob 周期性全量计算热榜，两次计算之间的互动由 service 层增量更新
+type HotRankJob struct {
+	*runner
+	svc    service.ArticleService
+	logger logger.Logger
+}
+
+func NewHotRankJob(svc service.ArticleService, myLogger logger.Logger) *HotRankJob {
+	job := &HotRankJob{
+		svc:    svc,
+		logger: myLogger,
+	}
+	job.runner = newRunner("HotRankJob", hotRankInterval, job.runOnce)
+	// 启动时先计算一次，避免热榜为空
+	job.runOnStart = true
+	return job
+}
+
+func (job *HotRankJob) runOnce(ctx *gin.Context) {
+	cnt, err := job.svc.RecomputeHotScores(ctx, lockTTL(hotRankInterval))
+	if err != nil {
+		job.logger.Error("[HotRankJob] 计算热榜失败", logger.Field{
+			Key:   "详情",
+			Value: err,
+		})
+		return
+	}
+	if cnt >= 0 {
+		job.logger.Info("[HotRankJob] 热榜计算完成", logger.Field{
+			Key:   "上榜文章数",
+			Value: cnt,
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package job
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/google/wire"
+	"net/http"
+	"time"
+)
+
+// JobProviderSet is job providers.
+var JobProviderSet = wire.NewSet(NewArticleScheduleJob, NewSearchIndexJob, NewHotRankJob, NewArticlePurgeJob, NewOutboxRelayJob,
+	NewArticleReconcileJob, NewReadFlushJob, NewJobs)
+
+// Job 随服务启动的后台任务
+type Job interface {
+	Name() string
+	Start()
+	// Stop 取消正在执行的一轮并等待其退出
+	Stop()
+}
+
+func NewJobs(scheduleJob *ArticleScheduleJob, searchJob *SearchIndexJob, hotJob *HotRankJob, purgeJob *ArticlePurgeJob,
+	relayJob *OutboxRelayJob, reconcileJob *ArticleReconcileJob, readFlushJob *ReadFlushJob) []Job {
+	return []Job{scheduleJob, searchJob, hotJob, purgeJob, relayJob, reconcileJob, readFlushJob}
+}
+
+// stopTimeout 退出前收尾逻辑的最长执行时间
+const stopTimeout = 10 * time.Second
+
+// lockTTL 每个副本都会运行全部任务，任务本身要么是幂等的，要么通过锁保证同一轮只有一个副本执行
+// 这类锁不主动释放，有效期略短于执行周期，使各副本的下一轮都有机会抢到
+func lockTTL(interval time.Duration) time.Duration {
+	return interval - interval/20
+}
+
+// runner 按固定周期执行 run，各任务嵌入 runner 并只提供每一轮的执行逻辑
+type runner struct {
+	name     string
+	interval time.Duration
+	run      func(ctx *gin.Context)
+	// runOnStart 启动时立即执行一轮，不等待第一个周期
+	runOnStart bool
+	// onStop 不为 nil 时在退出前执行一次，使用新的上下文，不受 Stop 取消的影响
+	onStop func(ctx *gin.Context)
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func newRunner(name string, interval time.Duration, run func(ctx *gin.Context)) *runner {
+	return &runner{name: name, interval: interval, run: run}
+}
+
+func (r *runner) Name() string {
+	return r.name
+}
+
+func (r *runner) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	r.done = make(chan struct{})
+	go func() {
+		defer close(r.done)
+		if r.runOnStart {
+			r.run(newJobContext(ctx))
+		}
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				r.run(newJobContext(ctx))
+			}
+		}
+	}()
+}
+
+func (r *runner) Stop() {
+	r.cancel()
+	<-r.done
+	if r.onStop != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), stopTimeout)
+		defer cancel()
+		r.onStop(newJobContext(ctx))
+	}
+}
+
+// jobEngine 只用于构造后台任务的 gin.Context，开启 ContextWithFallback 后 Done、Err 等方法转发到 Request 的上下文
+var jobEngine = func() *gin.Engine {
+	engine := gin.New()
+	engine.ContextWithFallback = true
+	return engine
+}()
+
+// newJobContext 后台任务没有请求上下文，构造随 ctx 取消的 gin.Context 调用 service，
+// 使 Stop 时进行中的数据库与 Redis 操作能及时退出
+func newJobContext(ctx context.Context) *gin.Context {
+	c := gin.CreateTestContextOnly(nil, jobEngine)
+	c.Request = (&http.Request{}).WithContext(ctx)
+	return c
+}
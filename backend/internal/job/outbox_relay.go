@@ -0,0 +1,45 @@
+package job
+
+import (
+	"github.com/gin-gonic/gin"
+	"ibook/internal/service"
+	"ibook/pkg/utils/logger"
+	"time"
+)
+
+const (
+	outboxRelayInterval  = time.Second
+	outboxRelayBatchSize = 100
+)
+
+// OutboxRelayJob 周期性将发件箱中的事件应用到读者库与缓存，发表时立即应用失败的事件由该任务重试
+type OutboxRelayJob struct {
+	*runner
+	svc    service.ArticleService
+	logger logger.Logger
+}
+
+func NewOutboxRelayJob(svc service.ArticleService, myLogger logger.Logger) *OutboxRelayJob {
+	job := &OutboxRelayJob{
+		svc:    svc,
+		logger: myLogger,
+	}
+	job.runner = newRunner("OutboxRelayJob", outboxRelayInterval, job.runOnce)
+	return job
+}
+
+func (job *OutboxRelayJob) runOnce(ctx *gin.Context) {
+	for ctx.Err() == nil {
+		done, err := job.svc.RelayOutboxEvents(ctx, 0, outboxRelayBatchSize)
+		if err != nil {
+			job.logger.Error("[OutboxRelayJob] 查询待应用事件失败", logger.Field{
+				Key:   "详情",
+				Value: err,
+			})
+			return
+		}
+		if done < outboxRelayBatchSize {
+			return
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package job
+
+import (
+	"github.com/gin-gonic/gin"
+	"ibook/internal/conf"
+	"ibook/internal/service"
+	"ibook/pkg/utils/logger"
+	"time"
+)
+
+const (
+	defaultReadFlushInterval  = 5 * time.Second
+	defaultReadFlushBatchSize = 500
+	// readFlushLockTTL 单轮刷写最多持有锁的时长，service 只在前半段开始新的批次
+	readFlushLockTTL = time.Minute
+)
+
+// ReadFlushJob 周期性将缓冲区中的阅读计数批量写入数据库，退出前再刷写一次
+type ReadFlushJob struct {
+	*runner
+	svc       service.ArticleService
+	batchSize int64
+	logger    logger.Logger
+}
+
+func NewReadFlushJob(svc service.ArticleService, aConf *conf.Article, myLogger logger.Logger) *ReadFlushJob {
+	job := &ReadFlushJob{
+		svc:       svc,
+		batchSize: defaultReadFlushBatchSize,
+		logger:    myLogger,
+	}
+	interval := defaultReadFlushInterval
+	if aConf != nil && aConf.ReadFlushIntervalSeconds > 0 {
+		interval = time.Duration(aConf.ReadFlushIntervalSeconds) * time.Second
+	}
+	if aConf != nil && aConf.ReadFlushBatchSize > 0 {
+		job.batchSize = aConf.ReadFlushBatchSize
+	}
+	job.runner = newRunner("ReadFlushJob", interval, job.runOnce)
+	job.onStop = job.runOnce
+	return job
+}
+
+func (job *ReadFlushJob) runOnce(ctx *gin.Context) {
+	cnt, err := job.svc.FlushReadCounts(ctx, job.batchSize, readFlushLockTTL)
+	if err != nil {
+		job.logger.Error("[ReadFlushJob] 刷写阅读计数失败", logger.Field{
+			Key:   "详情",
+			Value: err,
+		})
+		return
+	}
+	if cnt > 0 {
+		job.logger.Debug("[ReadFlushJob] 已刷写阅读计数", logger.Field{
+			Key:   "文章数",
+			Value: cnt,
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package job
+
+import (
+	"github.com/gin-gonic/gin"
+	"ibook/internal/service"
+	"ibook/pkg/utils/logger"
+	"time"
+)
+
+const (
+	searchCatchUpInterval  = 10 * time.Second
+	searchSnapshotInterval = 5 * time.Minute
+)
+
+// SearchIndexJob 启动时加载全文索引，之后定期从读者库增量同步并保存索引快照，退出前再保存一次快照
+type SearchIndexJob struct {
+	*runner
+	svc    service.SearchService
+	logger logger.Logger
+	// ready 与 lastSnapshot 只在 runner 的 goroutine 中读写
+	ready        bool
+	lastSnapshot time.Time
+}
+
+func NewSearchIndexJob(svc service.SearchService, myLogger logger.Logger) *SearchIndexJob {
+	job := &SearchIndexJob{
+		svc:    svc,
+		logger: myLogger,
+	}
+	job.runner = newRunner("SearchIndexJob", searchCatchUpInterval, job.runOnce)
+	job.runOnStart = true
+	job.onStop = func(ctx *gin.Context) {
+		if job.ready {
+			job.save(ctx)
+		}
+	}
+	return job
+}
+
+func (job *SearchIndexJob) runOnce(ctx *gin.Context) {
+	// 初始化失败时搜索接口返回服务不可用，在下一轮重试
+	if !job.ready {
+		job.ready = job.init(ctx)
+		job.lastSnapshot = time.Now()
+		return
+	}
+	if _, err := job.svc.CatchUp(ctx); err != nil {
+		job.logger.Warn("[SearchIndexJob] 增量同步搜索索引失败", logger.Field{
+			Key:   "详情",
+			Value: err,
+		})
+	}
+	if time.Since(job.lastSnapshot) >= searchSnapshotInterval {
+		job.save(ctx)
+		job.lastSnapshot = time.Now()
+	}
+}
+
+func (job *SearchIndexJob) init(ctx *gin.Context) bool {
+	if err := job.svc.Init(ctx); err != nil {
+		job.logger.Error("[SearchIndexJob] 初始化搜索索引失败", logger.Field{
+			Key:   "详情",
+			Value: err,
+		})
+		return false
+	}
+	return true
+}
+
+func (job *SearchIndexJob) save(ctx *gin.Context) {
+	if err := job.svc.SaveSnapshot(ctx); err != nil {
+		job.logger.Warn("[SearchIndexJob] 保存搜索索引快照失败", logger.Field{
+			Key:   "详情",
+			Value: err,
+		})
+	}
+}
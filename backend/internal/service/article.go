@@ -4,83 +4,193 @@ import (
 	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"golang.org/x/sync/singleflight"
 	mylogger "ibook/pkg/utils/logger"
+	"time"
 )
 
 var (
-	ArticleUnKnownErr = errors.New("文章服务未知错误")
+	ArticleUnKnownErr             = errors.New("文章服务未知错误")
+	ArticleNotExistsErr           = errors.New("文章不存在或未发表")
+	CollectFolderAlreadyExistsErr = errors.New("同名收藏夹已存在")
+	CollectFolderNotExistsErr     = errors.New("收藏夹不存在")
+	CollectFolderForbiddenErr     = errors.New("无权访问此收藏夹")
+	ArticleVersionConflictErr     = errors.New("文章已在别处被修改")
+	InteractiveNotInCacheErr      = errors.New("互动计数未被缓存")
 )
 
+// AnyVersion 写入作者库时不校验版本号，用于恢复历史版本等以本次写入为准的场景
+const AnyVersion int64 = -1
+
+// ArticleConflictError 编辑时版本号冲突，Current 为服务端当前的草稿
+type ArticleConflictError struct {
+	Current *Article
+}
+
+func (e *ArticleConflictError) Error() string {
+	return ArticleVersionConflictErr.Error()
+}
+
+func (e *ArticleConflictError) Unwrap() error {
+	return ArticleVersionConflictErr
+}
+
 type ArticleAuthorRepo interface {
-	CreateArticle(ctx *gin.Context, article *ArticleAuthor) (bool, error)
-	UpdateArticle(ctx *gin.Context, article *ArticleAuthor) (bool, error)
+	// CreateArticle 与文章在同一事务中写入 kind 类型的历史版本
+	CreateArticle(ctx *gin.Context, article *ArticleAuthor, kind RevisionKind) (bool, error)
+	// UpdateArticle 返回是否实际写入，内容未变化时不写入；实际写入时在同一事务中写入 kind 类型的历史版本，
+	// kind 为 RevisionKindUnknown 时不写入
+	UpdateArticle(ctx *gin.Context, article *ArticleAuthor, kind RevisionKind) (bool, error)
 	UpdateStatusById(ctx *gin.Context, articleId int64, authorId int64, status uint8) error
 	GetArticleById(ctx *gin.Context, id int64, userId int64) (*Article, error)
 }
 
 type ArticleReaderRepo interface {
-	UpsertArticle(ctx *gin.Context, article *ArticleReader) error
-	UpdateStatusById(ctx *gin.Context, articleId int64, authorId int64, status uint8) error
-	ListAll(ctx *gin.Context, offset int64, limit int64) ([]*Article, error)
-	ListById(ctx *gin.Context, userId int64, offset int64, limit int64) ([]*Article, error)
+	GetPubArticleById(ctx *gin.Context, articleId int64) (*Article, error)
+	ListAll(ctx *gin.Context, cursor ListCursor, limit int64) ([]*Article, error)
+	ListById(ctx *gin.Context, userId int64, cursor ListCursor, limit int64) ([]*Article, error)
+	GetPubArticlesByIds(ctx *gin.Context, ids []int64) ([]*Article, error)
+	ListByFilter(ctx *gin.Context, filter ArticleListFilter, cursor ListCursor, limit int64) ([]*Article, error)
 }
 
 type ArticleSyncRepo interface {
+	// Sync 与 SyncUpdateStatus 在同一事务中写入作者库与发件箱事件，读者库由 relay 异步更新
 	Sync(ctx *gin.Context, articleA *ArticleAuthor, articleR *ArticleReader) error
 	SyncUpdateStatus(ctx *gin.Context, articleId int64, authorId int64, status uint8) error
-	UpsertLikeInfo(ctx *gin.Context, userId int64, articleId int64) error
-	CancelLikeInfo(ctx *gin.Context, userId int64, articleId int64) error
 }
 
 type ArticleInteractiveRepo interface {
-	IncrReadCount(ctx *gin.Context, articleId int64) error
-	IncrLikeCnt(ctx *gin.Context, articleId int64) error
-	DecrLikeCnt(ctx *gin.Context, articleId int64) error
+	GetInteractive(ctx *gin.Context, articleId int64) (*Interactive, error)
+	// GetInteractives 按 articleIds 的顺序返回，没有互动记录的文章计数为 0
+	GetInteractives(ctx *gin.Context, articleIds []int64) ([]*Interactive, error)
+	// IncrReadCounts 批量累加阅读计数，key 为文章 id，同一批次号下已写入的文章不再累加
+	IncrReadCounts(ctx *gin.Context, batchId string, counts map[int64]int64) error
 }
 
 type ArticleInteractiveCache interface {
-	IncrReadCountInCache(ctx *gin.Context, articleId int64) error
-	IncrLikeCountInCache(ctx *gin.Context, articleId int64) error
-	DecrLikeCountInCache(ctx *gin.Context, articleId int64) error
+	// GetInteractive 未命中时返回 InteractiveNotInCacheErr
+	GetInteractive(ctx *gin.Context, articleId int64) (*Interactive, error)
+	// GetInteractives 只返回命中缓存的文章
+	GetInteractives(ctx *gin.Context, articleIds []int64) (map[int64]*Interactive, error)
+	// SetInteractives 写入从数据库加载的计数，已缓存的文章不会被覆盖
+	SetInteractives(ctx *gin.Context, inters []*Interactive) error
+	// BufferReadCount 去重窗口内首次阅读时将计数写入缓冲区，由 FlushReadCounts 批量写入数据库
+	// viewer 同时记入独立读者，返回本次阅读是否被计数
+	BufferReadCount(ctx *gin.Context, articleId int64, viewer string) (bool, error)
+	GetUniqueReaderCounts(ctx *gin.Context, articleIds []int64) (map[int64]int64, error)
+	// TakeBufferedReadCounts 取出最多 limit 篇文章的待写入计数及其批次号，写入后需要 Ack，未 Ack 的计数下一次会以同一批次号再次取出
+	TakeBufferedReadCounts(ctx *gin.Context, limit int64) (string, map[int64]int64, error)
+	AckBufferedReadCounts(ctx *gin.Context, articleIds []int64) error
+	// TryLockReadFlush 多副本之间同一时间只有一个副本刷写阅读计数，抢到锁时返回释放锁用的 token，否则返回空字符串
+	TryLockReadFlush(ctx *gin.Context, ttl time.Duration) (string, error)
+	// UnlockReadFlush 只在锁仍由 token 持有时释放，锁已过期并被其他副本抢到时不做处理
+	UnlockReadFlush(ctx *gin.Context, token string) error
+	// IncrReactionCountInCache 同时累加点赞数与踩数，缓存不存在时不做处理
+	IncrReactionCountInCache(ctx *gin.Context, articleId int64, likeDelta int64, dislikeDelta int64) error
+	IncrCollectCountInCache(ctx *gin.Context, articleId int64) error
+	DecrCollectCountInCache(ctx *gin.Context, articleId int64) error
+	IncrCommentCountInCache(ctx *gin.Context, articleId int64, delta int64) error
+}
+
+type ArticleCollectRepo interface {
+	CreateFolder(ctx *gin.Context, folder *CollectFolder) error
+	GetFolderById(ctx *gin.Context, folderId int64) (*CollectFolder, error)
+	ListFolders(ctx *gin.Context, userId int64, onlyPublic bool) ([]*CollectFolder, error)
+	ListFolderArticles(ctx *gin.Context, folderId int64, offset int64, limit int64) ([]*Article, error)
+	UpsertCollectInfo(ctx *gin.Context, userId int64, articleId int64, folderId int64) (bool, error)
+	CancelCollectInfo(ctx *gin.Context, userId int64, articleId int64) (bool, error)
+	Collected(ctx *gin.Context, userId int64, articleId int64) (bool, error)
 }
 
 type ArticleService interface {
 	EditArticle(ctx *gin.Context, article *Article) error
-	PublishArticle(ctx *gin.Context, article *Article) error
+	PublishArticle(ctx *gin.Context, article *Article, schedule PublishSchedule) error
 	WithDrawArticle(ctx *gin.Context, articleId, userId int64) error
 	GetArticleDetail(ctx *gin.Context, articleId int64, userId int64) (*Article, error)
-	ListPubArticles(ctx *gin.Context, userId int64, offset int64, limit int64) ([]*Article, error)
-	IncrReadCount(ctx *gin.Context, articleId int64) error
-	LikeArticle(ctx *gin.Context, userId int64, articleId int64) error
-	CancelLikeArticle(ctx *gin.Context, userId int64, articleId int64) error
+	GetPubArticleDetail(ctx *gin.Context, articleId int64, viewerId int64) (*Article, *Interactive, error)
+	ListPubArticles(ctx *gin.Context, filter ArticleListFilter, cursor ListCursor, limit int64) ([]*Article, ListCursor, bool, error)
+	ListFeed(ctx *gin.Context, userId int64, cursor ListCursor, limit int64) ([]*Article, ListCursor, bool, error)
+	IncrReadCount(ctx *gin.Context, articleId int64, viewer string) error
+	ListAuthorArticleStats(ctx *gin.Context, authorId int64, cursor ListCursor, limit int64) ([]*Article, ListCursor, bool, error)
+	FlushReadCounts(ctx *gin.Context, batchSize int64, lockTTL time.Duration) (int, error)
+	ReactArticle(ctx *gin.Context, userId int64, articleId int64, status ReactionStatus) (ReactionStatus, error)
+	ListLikedArticles(ctx *gin.Context, userId int64, cursor ListCursor, limit int64) ([]*LikedArticle, ListCursor, bool, error)
+	ListArticleLikers(ctx *gin.Context, articleId int64, cursor ListCursor, limit int64) ([]*LikeRecord, ListCursor, bool, error)
+	CollectArticle(ctx *gin.Context, userId int64, articleId int64, folderId int64) error
+	CancelCollectArticle(ctx *gin.Context, userId int64, articleId int64) error
+	CreateCollectFolder(ctx *gin.Context, folder *CollectFolder) error
+	ListCollectFolders(ctx *gin.Context, ownerId int64, viewerId int64) ([]*CollectFolder, error)
+	ListCollectFolderArticles(ctx *gin.Context, folderId int64, viewerId int64, offset int64, limit int64) ([]*Article, error)
+	ListRevisions(ctx *gin.Context, articleId int64, authorId int64, offset int64, limit int64) ([]*ArticleRevision, error)
+	GetRevision(ctx *gin.Context, revisionId int64, authorId int64) (*ArticleRevision, error)
+	DiffRevisions(ctx *gin.Context, fromId int64, toId int64, authorId int64, mode DiffMode) (*RevisionDiff, error)
+	RestoreRevision(ctx *gin.Context, revisionId int64, authorId int64, version int64) (*Article, error)
+	ListSchedules(ctx *gin.Context, authorId int64, articleId int64) ([]*ArticleSchedule, error)
+	UpdateSchedule(ctx *gin.Context, scheduleId int64, authorId int64, executeAt int64) error
+	CancelSchedule(ctx *gin.Context, scheduleId int64, authorId int64) error
+	ExecuteDueSchedules(ctx *gin.Context, limit int64) (int, error)
+	ListTagCounts(ctx *gin.Context, limit int64) ([]*TagCount, error)
+	ListHotArticles(ctx *gin.Context, offset int64, limit int64) ([]*Article, bool, error)
+	RecomputeHotScores(ctx *gin.Context, lockTTL time.Duration) (int, error)
+	DeleteArticle(ctx *gin.Context, articleId int64, authorId int64) error
+	ListRecycleBin(ctx *gin.Context, authorId int64, offset int64, limit int64) ([]*DeletedArticle, error)
+	RestoreDeletedArticle(ctx *gin.Context, articleId int64, authorId int64) error
+	PurgeExpiredArticles(ctx *gin.Context, limit int64) (int, error)
+	RelayOutboxEvents(ctx *gin.Context, articleId int64, limit int64) (int, error)
+	RequeueDeadOutboxEvents(ctx *gin.Context) (int64, error)
+	ReconcileArticles(ctx *gin.Context, repair bool, lockTTL time.Duration) (*ReconcileReport, error)
 }
 
 type articleService struct {
-	ar     ArticleAuthorRepo
-	rr     ArticleReaderRepo
-	sr     ArticleSyncRepo
-	air    ArticleInteractiveRepo
-	aic    ArticleInteractiveCache
-	logger mylogger.Logger
+	ar  ArticleAuthorRepo
+	rr  ArticleReaderRepo
+	sr  ArticleSyncRepo
+	air ArticleInteractiveRepo
+	aic ArticleInteractiveCache
+	cr  ArticleCollectRepo
+	rvr ArticleRevisionRepo
+	scr ArticleScheduleRepo
+	tr  ArticleTagRepo
+	sch ArticleSearchRepo
+	hr  ArticleHotRepo
+	rcr ArticleRecycleRepo
+	obr ArticleOutboxRepo
+	rcc ArticleReconcileRepo
+	lr  ArticleLikeRepo
+	fr  FollowRepo
+	fdr FeedRepo
+	// interGroup 合并对同一批文章互动计数的并发回源
+	interGroup singleflight.Group
+	logger     mylogger.Logger
 }
 
-func NewArticleService(ar ArticleAuthorRepo, rr ArticleReaderRepo, sr ArticleSyncRepo, air ArticleInteractiveRepo, logger mylogger.Logger) ArticleService {
-	return &articleService{ar: ar, rr: rr, sr: sr, air: air, logger: logger}
+func NewArticleService(ar ArticleAuthorRepo, rr ArticleReaderRepo, sr ArticleSyncRepo, air ArticleInteractiveRepo,
+	aic ArticleInteractiveCache, cr ArticleCollectRepo, rvr ArticleRevisionRepo, scr ArticleScheduleRepo, tr ArticleTagRepo,
+	sch ArticleSearchRepo, hr ArticleHotRepo, rcr ArticleRecycleRepo, obr ArticleOutboxRepo,
+	rcc ArticleReconcileRepo, lr ArticleLikeRepo, fr FollowRepo, fdr FeedRepo, logger mylogger.Logger) ArticleService {
+	return &articleService{ar: ar, rr: rr, sr: sr, air: air, aic: aic, cr: cr, rvr: rvr, scr: scr, tr: tr, sch: sch, hr: hr, rcr: rcr, obr: obr, rcc: rcc, lr: lr, fr: fr, fdr: fdr, logger: logger}
 }
 
 func (service *articleService) EditArticle(ctx *gin.Context, article *Article) error {
 	l := mylogger.TagCtxLogger(ctx, service.logger, "EditArticle")
+	if err := normalizeTaxonomy(article); err != nil {
+		return err
+	}
 
 	articleA := &ArticleAuthor{Article{
-		Id:      article.Id,
-		Title:   article.Title,
-		Content: article.Content,
-		Author:  article.Author,
+		Id:       article.Id,
+		Title:    article.Title,
+		Content:  article.Content,
+		Format:   article.Format,
+		Category: article.Category,
+		Tags:     article.Tags,
+		Author:   article.Author,
+		Version:  article.Version,
 	}}
 	if article.Id <= 0 {
 		// 创建新的
 		articleA.Status = ArticleStatusUnpublished
-		ok, err := service.ar.CreateArticle(ctx, articleA)
+		ok, err := service.ar.CreateArticle(ctx, articleA, RevisionKindSave)
 		if err != nil || !ok {
 			l.Warn("新建文章时出现错误", mylogger.Field{
 				Key:   "错误详情",
@@ -89,82 +199,216 @@ func (service *articleService) EditArticle(ctx *gin.Context, article *Article) e
 			return err
 		}
 		article.Id = articleA.Id
+		article.Version = articleA.Version
 		return nil
 	} else {
 		// 更新已有的
 		articleA.Status = ArticleStatusUnpublished
-		ok, err := service.ar.UpdateArticle(ctx, articleA)
-		if err != nil || !ok {
+		_, err := service.ar.UpdateArticle(ctx, articleA, RevisionKindSave)
+		if errors.Is(err, ArticleVersionConflictErr) {
+			return service.conflictError(ctx, article)
+		}
+		if err != nil {
 			l.Warn("更新文章时出现错误", mylogger.Field{
 				Key:   "错误详情",
 				Value: err,
 			})
 			return err
 		}
+		// 自动保存时内容常常没有变化，此时不写入，也不产生历史版本
+		article.Version = articleA.Version
 		return nil
 	}
 }
 
-func (service *articleService) PublishArticle(ctx *gin.Context, article *Article) error {
+// PublishArticle 发表文章。指定了未来的发表时间时先保存为草稿，到期后由定时任务发表；
+// 指定了撤回时间时到期后自动撤回
+func (service *articleService) PublishArticle(ctx *gin.Context, article *Article, schedule PublishSchedule) error {
+	l := mylogger.TagCtxLogger(ctx, service.logger, "PublishArticle")
+	publishNow, err := checkPublishSchedule(schedule)
+	if err != nil {
+		return err
+	}
+	if publishNow {
+		if err = service.publish(ctx, article); err != nil {
+			return err
+		}
+		// 已立即发表，之前设置的定时发表不再需要
+		if err = service.scr.CancelPendingSchedule(ctx, article.Id, article.Author.Id, ScheduleActionPublish); err != nil {
+			l.Warn("取消定时发表计划失败", mylogger.Field{
+				Key:   "详情",
+				Value: err,
+			})
+		}
+	} else {
+		if err = service.EditArticle(ctx, article); err != nil {
+			return err
+		}
+		err = service.scr.UpsertPendingSchedule(ctx, &ArticleSchedule{
+			ArticleId: article.Id,
+			AuthorId:  article.Author.Id,
+			Action:    ScheduleActionPublish,
+			ExecuteAt: schedule.PublishAt,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if schedule.ExpireAt > 0 {
+		return service.scr.UpsertPendingSchedule(ctx, &ArticleSchedule{
+			ArticleId: article.Id,
+			AuthorId:  article.Author.Id,
+			Action:    ScheduleActionWithdraw,
+			ExecuteAt: schedule.ExpireAt,
+		})
+	}
+	return nil
+}
+
+func (service *articleService) publish(ctx *gin.Context, article *Article) error {
+	if err := normalizeTaxonomy(article); err != nil {
+		return err
+	}
+	articleR, err := toReaderArticle(article)
+	if err != nil {
+		return err
+	}
 	articleA := &ArticleAuthor{Article{
-		Id:      article.Id,
-		Title:   article.Title,
-		Content: article.Content,
+		Id:       article.Id,
+		Title:    article.Title,
+		Content:  article.Content,
+		Format:   article.Format,
+		Category: article.Category,
+		Tags:     article.Tags,
 		Author: Author{
 			Id:   article.Author.Id,
 			Name: article.Author.Name,
 		},
-		Status: ArticleStatusPublished,
-	}}
-	articleR := &ArticleReader{Article{
-		Id:      article.Id,
-		Title:   article.Title,
-		Content: article.Content,
-		Author:  article.Author,
 		Status:  ArticleStatusPublished,
+		Version: article.Version,
 	}}
-	successFlag := false
-	retryTimes := 3
-	var err error
-	for !successFlag && retryTimes > 0 {
-		err = service.sr.Sync(ctx, articleA, articleR)
-		if err != nil {
-			service.logger.Error("[ArticleService-Publish] 未成功同步双库数据：", mylogger.Field{
-				Key:   "同步出错",
-				Value: err,
-			})
-			retryTimes--
-		} else {
-			successFlag = true
-		}
+	// 作者库、历史版本与发件箱事件原子写入，读者库的更新失败时由 relay 重试
+	err = service.sr.Sync(ctx, articleA, articleR)
+	if errors.Is(err, ArticleVersionConflictErr) {
+		return service.conflictError(ctx, article)
 	}
-	if successFlag == false {
+	if err != nil {
+		service.logger.Error("[ArticleService-Publish] 写入作者库失败：", mylogger.Field{
+			Key:   "详情",
+			Value: err,
+		})
 		return err
 	}
+	service.relayArticle(ctx, articleA.Id)
 	article.Id = articleA.Id
+	article.Version = articleA.Version
+	// 推送到粉丝收件箱需要分批写入，不阻塞发表请求，请求结束后 gin.Context 会被复用，因此传入副本
+	go service.fanoutFeed(ctx.Copy(), articleA.Id, articleA.Author.Id)
+	// 索引更新失败时由搜索索引的增量同步兜底
+	if err = service.sch.IndexArticle(ctx, &articleR.Article); err != nil {
+		service.logger.Warn("[ArticleService-Publish] 更新搜索索引失败", mylogger.Field{
+			Key:   "详情",
+			Value: err,
+		})
+	}
 	return nil
 }
 
+// toReaderArticle 生成发表到读者库的文章，发表时渲染正文并生成摘要，读者端不再重复计算
+func toReaderArticle(article *Article) (*ArticleReader, error) {
+	html, err := renderContent(article.Content, article.Format)
+	if err != nil {
+		return nil, fmt.Errorf("渲染文章正文失败：%w", err)
+	}
+	return &ArticleReader{Article{
+		Id:       article.Id,
+		Title:    article.Title,
+		Abstract: genAbstract(article.Content, article.Format),
+		Content:  article.Content,
+		Format:   article.Format,
+		Category: article.Category,
+		HTML:     html,
+		Author:   article.Author,
+		Status:   ArticleStatusPublished,
+	}}, nil
+}
+
 func (service *articleService) WithDrawArticle(ctx *gin.Context, articleId, authorId int64) error {
-	return service.sr.SyncUpdateStatus(ctx, articleId, authorId, ArticleStatusPrivate)
+	err := service.withdraw(ctx, articleId, authorId)
+	if err != nil {
+		return err
+	}
+	// 已手动撤回，之前设置的定时撤回不再需要
+	if err = service.scr.CancelPendingSchedule(ctx, articleId, authorId, ScheduleActionWithdraw); err != nil {
+		l := mylogger.TagCtxLogger(ctx, service.logger, "WithDrawArticle")
+		l.Warn("取消定时撤回计划失败", mylogger.Field{
+			Key:   "详情",
+			Value: err,
+		})
+	}
+	return nil
 }
 
-func (service *articleService) ListPubArticles(ctx *gin.Context, authorId int64, offset int64, limit int64) ([]*Article, error) {
-	if authorId <= 0 {
+func (service *articleService) withdraw(ctx *gin.Context, articleId, authorId int64) error {
+	err := service.sr.SyncUpdateStatus(ctx, articleId, authorId, ArticleStatusPrivate)
+	if err != nil {
+		return err
+	}
+	service.relayArticle(ctx, articleId)
+	if err = service.sch.RemoveArticle(ctx, articleId); err != nil {
+		service.logger.Warn("[ArticleService-Withdraw] 更新搜索索引失败", mylogger.Field{
+			Key:   "详情",
+			Value: err,
+		})
+	}
+	if err = service.hr.RemoveHotArticle(ctx, articleId); err != nil {
+		service.logger.Warn("[ArticleService-Withdraw] 移出热榜失败", mylogger.Field{
+			Key:   "详情",
+			Value: err,
+		})
+	}
+	return nil
+}
+
+// ListPubArticles 返回本页文章、下一页的游标以及是否还有更多文章
+func (service *articleService) ListPubArticles(ctx *gin.Context, filter ArticleListFilter, cursor ListCursor, limit int64) ([]*Article, ListCursor, bool, error) {
+	var articles []*Article
+	var err error
+	// 多取一篇用于判断是否还有下一页
+	if filter.hasTaxonomy() {
+		// 按标签、分类筛选
+		articles, err = service.rr.ListByFilter(ctx, filter, cursor, limit+1)
+	} else if filter.AuthorId <= 0 {
 		// 获取全部文章列表
-		articles, err := service.rr.ListAll(ctx, offset, limit)
-		if err != nil {
-			// 出错
-		}
-		return articles, nil
+		articles, err = service.rr.ListAll(ctx, cursor, limit+1)
 	} else {
 		// 获取指定作者 id 的文章列表
-		articles, err := service.rr.ListById(ctx, authorId, offset, limit)
-		if err != nil {
-			// 出错
-		}
-		return articles, nil
+		articles, err = service.rr.ListById(ctx, filter.AuthorId, cursor, limit+1)
+	}
+	if err != nil {
+		return nil, ListCursor{}, false, err
 	}
+	hasMore := int64(len(articles)) > limit
+	if hasMore {
+		articles = articles[:limit]
+	}
+	service.fillTags(ctx, articles)
+	service.fillInteractives(ctx, articles)
+	next := ListCursor{}
+	if len(articles) > 0 {
+		last := articles[len(articles)-1]
+		next = ListCursor{Value: last.UpdatedTime, Id: last.Id}
+	}
+	return articles, next, hasMore, nil
+}
+
+// conflictError 获取服务端当前的草稿，随冲突错误一起返回给调用方
+func (service *articleService) conflictError(ctx *gin.Context, article *Article) error {
+	current, err := service.ar.GetArticleById(ctx, article.Id, article.Author.Id)
+	if err != nil {
+		return fmt.Errorf("%w：获取当前草稿失败：%v", ArticleVersionConflictErr, err)
+	}
+	return &ArticleConflictError{Current: current}
 }
 
 func (service *articleService) GetArticleDetail(ctx *gin.Context, articleId int64, userId int64) (*Article, error) {
@@ -176,38 +420,158 @@ func (service *articleService) GetArticleDetail(ctx *gin.Context, articleId int6
 	return article, nil
 }
 
-func (service *articleService) IncrReadCount(ctx *gin.Context, articleId int64) error {
-	err := service.air.IncrReadCount(ctx, articleId)
+func (service *articleService) GetPubArticleDetail(ctx *gin.Context, articleId int64, viewerId int64) (*Article, *Interactive, error) {
+	l := mylogger.TagCtxLogger(ctx, service.logger, "GetPubArticleDetail")
+	article, err := service.rr.GetPubArticleById(ctx, articleId)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
-	return service.aic.IncrReadCountInCache(ctx, articleId)
+	service.fillTags(ctx, []*Article{article})
+	// 计数与访问者状态获取失败时降级为默认值，不影响文章本身的展示
+	inter, err := service.getInteractive(ctx, articleId)
+	if err != nil {
+		l.Warn("获取文章互动计数失败", mylogger.Field{
+			Key:   "详情",
+			Value: err,
+		})
+		inter = &Interactive{ArticleId: articleId}
+	}
+	if readers, err := service.aic.GetUniqueReaderCounts(ctx, []int64{articleId}); err != nil {
+		l.Warn("获取独立读者数失败", mylogger.Field{
+			Key:   "详情",
+			Value: err,
+		})
+	} else {
+		inter.UniqueReaderCnt = readers[articleId]
+	}
+	if viewerId > 0 {
+		if inter.Reaction, err = service.lr.GetReaction(ctx, viewerId, articleId); err != nil {
+			l.Warn("获取点赞状态失败", mylogger.Field{
+				Key:   "详情",
+				Value: err,
+			})
+		}
+		inter.Liked = inter.Reaction == ReactionLike
+		if inter.Collected, err = service.cr.Collected(ctx, viewerId, articleId); err != nil {
+			l.Warn("获取收藏状态失败", mylogger.Field{
+				Key:   "详情",
+				Value: err,
+			})
+		}
+	}
+	return article, inter, nil
 }
 
-func (service *articleService) LikeArticle(ctx *gin.Context, userId int64, articleId int64) error {
-	// 1. 在点赞记录表中插入记录，并更新文章表中的点赞计数
-	err := service.sr.UpsertLikeInfo(ctx, userId, articleId)
+// IncrReadCount 阅读计数先写入缓冲区，数据库中的计数由 FlushReadCounts 定时批量更新
+// viewer 为访问者标识，去重窗口内同一访问者的重复阅读只记入独立读者，不增加阅读数
+func (service *articleService) IncrReadCount(ctx *gin.Context, articleId int64, viewer string) error {
+	counted, err := service.aic.BufferReadCount(ctx, articleId, viewer)
 	if err != nil {
 		return err
 	}
-	// 2. 在缓存中更新点赞计数
-	err = service.aic.IncrLikeCountInCache(ctx, articleId)
-	if err != nil {
-		return err
+	if counted {
+		service.incrHotScore(ctx, articleId, hotWeightRead)
 	}
 	return nil
 }
 
-func (service *articleService) CancelLikeArticle(ctx *gin.Context, userId int64, articleId int64) error {
-	// 1. 在点赞记录表中改变记录状态，并更新文章表中的点赞计数
-	err := service.sr.CancelLikeInfo(ctx, userId, articleId)
+// FlushReadCounts 将缓冲区中的阅读计数分批写入数据库，返回写入的文章数，已由其他副本刷写时返回 -1
+// 计数写入数据库后才从缓冲区删除，两步之间失败时该批计数会以同一批次号再次取出，由数据库按批次号跳过已写入的文章
+func (service *articleService) FlushReadCounts(ctx *gin.Context, batchSize int64, lockTTL time.Duration) (int, error) {
+	token, err := service.aic.TryLockReadFlush(ctx, lockTTL)
+	if err != nil {
+		return 0, err
+	}
+	if token == "" {
+		return -1, nil
+	}
+	defer func() {
+		if err := service.aic.UnlockReadFlush(ctx, token); err != nil {
+			service.logger.Warn("[ArticleService-FlushReadCounts] 释放刷写锁失败", mylogger.Field{
+				Key:   "详情",
+				Value: err,
+			})
+		}
+	}()
+	// 只在锁的前半段有效期内开始新的批次，避免锁过期后与其他副本同时刷写
+	deadline := time.Now().Add(lockTTL / 2)
+	total := 0
+	for time.Now().Before(deadline) {
+		batchId, counts, err := service.aic.TakeBufferedReadCounts(ctx, batchSize)
+		if err != nil {
+			return total, err
+		}
+		if len(counts) == 0 {
+			break
+		}
+		if err = service.air.IncrReadCounts(ctx, batchId, counts); err != nil {
+			return total, err
+		}
+		ids := make([]int64, 0, len(counts))
+		for id := range counts {
+			ids = append(ids, id)
+		}
+		if err = service.aic.AckBufferedReadCounts(ctx, ids); err != nil {
+			return total, err
+		}
+		total += len(counts)
+	}
+	return total, nil
+}
+
+func (service *articleService) CollectArticle(ctx *gin.Context, userId int64, articleId int64, folderId int64) error {
+	// 1. 写入收藏记录，并更新文章表中的收藏计数
+	changed, err := service.cr.UpsertCollectInfo(ctx, userId, articleId, folderId)
 	if err != nil {
 		return err
 	}
-	// 2. 在缓存中更新点赞计数
-	err = service.aic.DecrLikeCountInCache(ctx, articleId)
+	// 重复收藏或仅更换收藏夹时计数不变
+	if !changed {
+		return nil
+	}
+	service.incrHotScore(ctx, articleId, hotWeightCollect)
+	// 2. 在缓存中更新收藏计数
+	return service.aic.IncrCollectCountInCache(ctx, articleId)
+}
+
+func (service *articleService) CancelCollectArticle(ctx *gin.Context, userId int64, articleId int64) error {
+	// 1. 在收藏记录表中改变记录状态，并更新文章表中的收藏计数
+	changed, err := service.cr.CancelCollectInfo(ctx, userId, articleId)
 	if err != nil {
 		return err
 	}
-	return nil
+	if !changed {
+		return nil
+	}
+	service.incrHotScore(ctx, articleId, -hotWeightCollect)
+	// 2. 在缓存中更新收藏计数
+	return service.aic.DecrCollectCountInCache(ctx, articleId)
+}
+
+func (service *articleService) CreateCollectFolder(ctx *gin.Context, folder *CollectFolder) error {
+	if folder.Visibility != CollectFolderPublic {
+		folder.Visibility = CollectFolderPrivate
+	}
+	return service.cr.CreateFolder(ctx, folder)
+}
+
+func (service *articleService) ListCollectFolders(ctx *gin.Context, ownerId int64, viewerId int64) ([]*CollectFolder, error) {
+	// 非本人只能看到公开的收藏夹
+	return service.cr.ListFolders(ctx, ownerId, ownerId != viewerId)
+}
+
+func (service *articleService) ListCollectFolderArticles(ctx *gin.Context, folderId int64, viewerId int64, offset int64, limit int64) ([]*Article, error) {
+	folder, err := service.cr.GetFolderById(ctx, folderId)
+	if err != nil {
+		return nil, err
+	}
+	if folder.UserId != viewerId && folder.Visibility != CollectFolderPublic {
+		return nil, CollectFolderForbiddenErr
+	}
+	articles, err := service.cr.ListFolderArticles(ctx, folderId, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	service.fillInteractives(ctx, articles)
+	return articles, nil
 }
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"github.com/gin-gonic/gin"
+	mylogger "ibook/pkg/utils/logger"
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	// hotRankSize 热榜保留的文章数量
+	hotRankSize = 1000
+	// hotScanBatch 全量计算时每批扫描的文章数量
+	hotScanBatch = 500
+	// hotListRetries 分页获取热榜时遇到残留文章后的最大重试次数
+	hotListRetries = 3
+
+	// 各类互动在热度中的权重
+	hotWeightRead    = 1
+	hotWeightLike    = 5
+	hotWeightCollect = 10
+	// hotGravity 时间衰减指数，越大旧文章下沉越快
+	hotGravity = 1.5
+)
+
+// HotCandidate 参与热度计算的已发表文章及其互动计数
+type HotCandidate struct {
+	ArticleId   int64
+	CreatedTime int64
+	ReadCnt     int64
+	LikeCnt     int64
+	CollectCnt  int64
+}
+
+type HotScore struct {
+	ArticleId int64
+	Score     float64
+}
+
+type ArticleHotRepo interface {
+	// ScanHotCandidates 按 id 升序分批获取 afterId 之后的已发表文章
+	ScanHotCandidates(ctx *gin.Context, afterId int64, limit int64) ([]*HotCandidate, error)
+	// ReplaceHotScores 用全量计算的结果整体替换热榜
+	ReplaceHotScores(ctx *gin.Context, scores []HotScore) error
+	// IncrHotScore 增加已在热榜中的文章的分数，不在榜中的文章等待下一次全量计算
+	IncrHotScore(ctx *gin.Context, articleId int64, delta float64) error
+	RemoveHotArticle(ctx *gin.Context, articleId int64) error
+	ListHotArticleIds(ctx *gin.Context, offset int64, limit int64) ([]int64, error)
+	// TryLockRecompute 多副本之间抢占本轮全量计算，ttl 内只有一个副本能抢到
+	TryLockRecompute(ctx *gin.Context, ttl time.Duration) (bool, error)
+}
+
+// hotScore 热度 = 加权互动数 / (发表小时数 + 2) ^ gravity
+func hotScore(weight float64, createdTime int64, now time.Time) float64 {
+	return weight / hotDecay(createdTime, now)
+}
+
+func hotDecay(createdTime int64, now time.Time) float64 {
+	ageHours := now.Sub(time.UnixMilli(createdTime)).Hours()
+	if ageHours < 0 {
+		ageHours = 0
+	}
+	return math.Pow(ageHours+2, hotGravity)
+}
+
+func (c *HotCandidate) weight() float64 {
+	// 加一使没有互动的新文章也能按发表时间排序
+	return float64(c.ReadCnt*hotWeightRead+c.LikeCnt*hotWeightLike+c.CollectCnt*hotWeightCollect) + 1
+}
+
+// ListHotArticles 按热度从高到低分页获取已发表文章，同时返回热榜中是否还有下一页
+func (service *articleService) ListHotArticles(ctx *gin.Context, offset int64, limit int64) ([]*Article, bool, error) {
+	var articles []*Article
+	// 热榜在两次全量计算之间可能残留已撤回或删除的文章，GetPubArticlesByIds 会将其跳过，导致本页不足 limit 篇
+	// 发现残留时将其移出热榜后重新获取，使本页与之后的偏移量都不再包含这些文章
+	for attempt := 0; attempt < hotListRetries; attempt++ {
+		// 多取一个 id 用于判断是否还有下一页
+		ids, err := service.hr.ListHotArticleIds(ctx, offset, limit+1)
+		if err != nil {
+			return nil, false, err
+		}
+		articles, err = service.rr.GetPubArticlesByIds(ctx, ids)
+		if err != nil {
+			return nil, false, err
+		}
+		if len(articles) == len(ids) || !service.removeStaleHotArticles(ctx, ids, articles) {
+			break
+		}
+	}
+	hasMore := int64(len(articles)) > limit
+	if hasMore {
+		articles = articles[:limit]
+	}
+	service.fillTags(ctx, articles)
+	service.fillInteractives(ctx, articles)
+	return articles, hasMore, nil
+}
+
+// removeStaleHotArticles 将 ids 中未能获取到的文章移出热榜，返回是否全部移除成功
+func (service *articleService) removeStaleHotArticles(ctx *gin.Context, ids []int64, articles []*Article) bool {
+	found := make(map[int64]bool, len(articles))
+	for _, article := range articles {
+		found[article.Id] = true
+	}
+	for _, id := range ids {
+		if found[id] {
+			continue
+		}
+		if err := service.hr.RemoveHotArticle(ctx, id); err != nil {
+			service.logger.Warn("[ArticleService-ListHotArticles] 移出热榜失败", mylogger.Field{
+				Key:   "详情",
+				Value: err,
+			})
+			return false
+		}
+	}
+	return true
+}
+
+// RecomputeHotScores 根据互动计数全量计算热榜，返回上榜文章数，本轮已由其他副本计算时返回 -1
+func (service *articleService) RecomputeHotScores(ctx *gin.Context, lockTTL time.Duration) (int, error) {
+	locked, err := service.hr.TryLockRecompute(ctx, lockTTL)
+	if err != nil {
+		return 0, err
+	}
+	if !locked {
+		return -1, nil
+	}
+	now := time.Now()
+	var scores []HotScore
+	var afterId int64
+	for {
+		candidates, err := service.hr.ScanHotCandidates(ctx, afterId, hotScanBatch)
+		if err != nil {
+			return 0, err
+		}
+		for _, c := range candidates {
+			scores = append(scores, HotScore{ArticleId: c.ArticleId, Score: hotScore(c.weight(), c.CreatedTime, now)})
+		}
+		// 每批结束后只保留分数最高的部分，避免文章数量多时占用过多内存
+		if len(scores) > 2*hotRankSize {
+			scores = topHotScores(scores, hotRankSize)
+		}
+		if len(candidates) < hotScanBatch {
+			break
+		}
+		afterId = candidates[len(candidates)-1].ArticleId
+	}
+	scores = topHotScores(scores, hotRankSize)
+	if err = service.hr.ReplaceHotScores(ctx, scores); err != nil {
+		return 0, err
+	}
+	return len(scores), nil
+}
+
+func topHotScores(scores []HotScore, n int) []HotScore {
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].Score != scores[j].Score {
+			return scores[i].Score > scores[j].Score
+		}
+		return scores[i].ArticleId > scores[j].ArticleId
+	})
+	if len(scores) > n {
+		scores = scores[:n]
+	}
+	return scores
+}
+
+// incrHotScore 互动发生时按当前的时间衰减增量更新热度，衰减随时间产生的偏差由下一次全量计算修正
+func (service *articleService) incrHotScore(ctx *gin.Context, articleId int64, weight float64) {
+	l := mylogger.TagCtxLogger(ctx, service.logger, "incrHotScore")
+	article, err := service.rr.GetPubArticleById(ctx, articleId)
+	if err != nil {
+		l.Warn("获取文章失败，跳过热度更新", mylogger.Field{
+			Key:   "详情",
+			Value: err,
+		})
+		return
+	}
+	if err = service.hr.IncrHotScore(ctx, articleId, weight/hotDecay(article.CreatedTime, time.Now())); err != nil {
+		l.Warn("更新文章热度失败", mylogger.Field{
+			Key:   "详情",
+			Value: err,
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"github.com/gin-gonic/gin"
+	"ibook/pkg/utils/bskit/slice"
+	mylogger "ibook/pkg/utils/logger"
+	"strconv"
+	"strings"
+)
+
+// getInteractive 先查缓存，未命中时从数据库加载并写回缓存
+func (service *articleService) getInteractive(ctx *gin.Context, articleId int64) (*Interactive, error) {
+	inters, err := service.getInteractives(ctx, []int64{articleId})
+	if err != nil {
+		return nil, err
+	}
+	return inters[articleId], nil
+}
+
+// getInteractives 批量获取互动计数，缓存未命中的文章合并为一次数据库查询
+// 同一进程内对同一批文章的并发加载只会查询一次数据库，避免缓存过期时大量请求同时回源
+func (service *articleService) getInteractives(ctx *gin.Context, articleIds []int64) (map[int64]*Interactive, error) {
+	res, err := service.aic.GetInteractives(ctx, articleIds)
+	if err != nil {
+		// 缓存异常时直接回源，不影响计数的展示
+		service.logger.Warn("[ArticleService-getInteractives] 查询计数缓存失败", mylogger.Field{
+			Key:   "详情",
+			Value: err,
+		})
+		res = make(map[int64]*Interactive, len(articleIds))
+	}
+	var misses []int64
+	for _, id := range articleIds {
+		if _, ok := res[id]; !ok {
+			misses = append(misses, id)
+		}
+	}
+	if len(misses) == 0 {
+		return res, nil
+	}
+	key := strings.Join(slice.Map[int64, string](misses, func(idx int, src int64) string {
+		return strconv.FormatInt(src, 10)
+	}), ",")
+	val, err, _ := service.interGroup.Do(key, func() (any, error) {
+		inters, err := service.air.GetInteractives(ctx, misses)
+		if err != nil {
+			return nil, err
+		}
+		if err = service.aic.SetInteractives(ctx, inters); err != nil {
+			service.logger.Warn("[ArticleService-getInteractives] 写入计数缓存失败", mylogger.Field{
+				Key:   "详情",
+				Value: err,
+			})
+		}
+		return inters, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	// 共享的结果会被多个请求读取，复制后再返回，避免调用方填充访问者状态时互相影响
+	for _, inter := range val.([]*Interactive) {
+		cp := *inter
+		res[inter.ArticleId] = &cp
+	}
+	return res, nil
+}
+
+// fillInteractives 批量填充列表中文章的互动计数，获取失败时不影响文章本身的展示
+func (service *articleService) fillInteractives(ctx *gin.Context, articles []*Article) {
+	if len(articles) == 0 {
+		return
+	}
+	ids := slice.Map[*Article, int64](articles, func(idx int, src *Article) int64 {
+		return src.Id
+	})
+	inters, err := service.getInteractives(ctx, ids)
+	if err != nil {
+		l := mylogger.TagCtxLogger(ctx, service.logger, "fillInteractives")
+		l.Warn("获取文章互动计数失败", mylogger.Field{
+			Key:   "详情",
+			Value: err,
+		})
+		return
+	}
+	for _, article := range articles {
+		article.Interactive = inters[article.Id]
+	}
+}
+
+// ListAuthorArticleStats 作者已发表文章的阅读、独立读者与互动统计，分页方式与文章列表相同
+func (service *articleService) ListAuthorArticleStats(ctx *gin.Context, authorId int64, cursor ListCursor, limit int64) ([]*Article, ListCursor, bool, error) {
+	articles, next, hasMore, err := service.ListPubArticles(ctx, ArticleListFilter{AuthorId: authorId}, cursor, limit)
+	if err != nil {
+		return nil, ListCursor{}, false, err
+	}
+	ids := slice.Map[*Article, int64](articles, func(idx int, src *Article) int64 {
+		return src.Id
+	})
+	readers, err := service.aic.GetUniqueReaderCounts(ctx, ids)
+	if err != nil {
+		return nil, ListCursor{}, false, err
+	}
+	for _, article := range articles {
+		// 计数获取失败时 fillInteractives 不会填充，统计接口按 0 返回
+		if article.Interactive == nil {
+			article.Interactive = &Interactive{ArticleId: article.Id}
+		}
+		article.Interactive.UniqueReaderCnt = readers[article.Id]
+	}
+	return articles, next, hasMore, nil
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"ibook/pkg/utils/bskit/slice"
+	mylogger "ibook/pkg/utils/logger"
+)
+
+var ReactionInvalidErr = errors.New("不支持的表态")
+
+// ReactionStatus 用户对文章的表态，存储在点赞记录的 status 字段中
+// 取值与原有的点赞状态兼容：0 为取消点赞，1 为点赞
+type ReactionStatus uint8
+
+const (
+	ReactionNormal ReactionStatus = iota
+	ReactionLike
+	ReactionDislike
+)
+
+func (status ReactionStatus) String() string {
+	switch status {
+	case ReactionLike:
+		return "like"
+	case ReactionDislike:
+		return "dislike"
+	default:
+		return "normal"
+	}
+}
+
+func ParseReactionStatus(s string) (ReactionStatus, error) {
+	switch s {
+	case "like":
+		return ReactionLike, nil
+	case "normal":
+		return ReactionNormal, nil
+	case "dislike":
+		return ReactionDislike, nil
+	default:
+		return ReactionNormal, ReactionInvalidErr
+	}
+}
+
+// LikeRecord 有效的点赞记录，LikedTime 为最近一次点赞的时间
+type LikeRecord struct {
+	Id        int64
+	UserId    int64
+	NickName  string
+	ArticleId int64
+	LikedTime int64
+}
+
+// LikedArticle 用户点赞过的文章
+type LikedArticle struct {
+	Article
+	LikedTime int64
+}
+
+type ArticleLikeRepo interface {
+	// SetReaction 将用户对文章的表态改为 status，返回修改前的表态，表态发生变化时在同一事务中更新点赞数与踩数
+	// 改为点赞或踩时文章必须处于发表状态，否则返回 ArticleNotExistsErr
+	SetReaction(ctx *gin.Context, userId int64, articleId int64, status ReactionStatus) (ReactionStatus, error)
+	// GetReaction 没有记录时返回 ReactionNormal
+	GetReaction(ctx *gin.Context, userId int64, articleId int64) (ReactionStatus, error)
+	// ListUserLikes 按点赞时间倒序获取用户点赞过的已发表文章
+	ListUserLikes(ctx *gin.Context, userId int64, cursor ListCursor, limit int64) ([]*LikeRecord, error)
+	// ListArticleLikers 按点赞时间倒序获取文章的点赞用户，NickName 为用户昵称
+	ListArticleLikers(ctx *gin.Context, articleId int64, cursor ListCursor, limit int64) ([]*LikeRecord, error)
+}
+
+// ReactArticle 修改用户对文章的表态，返回修改前的表态，重复提交相同的表态时计数不变
+func (service *articleService) ReactArticle(ctx *gin.Context, userId int64, articleId int64, status ReactionStatus) (ReactionStatus, error) {
+	if status > ReactionDislike {
+		return ReactionNormal, ReactionInvalidErr
+	}
+	// 1. 在点赞记录表中更新表态，并更新文章表中的点赞数与踩数
+	prev, err := service.lr.SetReaction(ctx, userId, articleId, status)
+	if err != nil {
+		return prev, err
+	}
+	if prev == status {
+		return prev, nil
+	}
+	likeDelta, dislikeDelta := ReactionCountDelta(prev, status)
+	if likeDelta != 0 {
+		service.incrHotScore(ctx, articleId, hotWeightLike*float64(likeDelta))
+	}
+	// 2. 在缓存中更新计数，数据库已更新成功，缓存失败只记录日志
+	if err = service.aic.IncrReactionCountInCache(ctx, articleId, likeDelta, dislikeDelta); err != nil {
+		l := mylogger.TagCtxLogger(ctx, service.logger, "ReactArticle")
+		l.Warn("更新点赞计数缓存失败", mylogger.Field{
+			Key:   "详情",
+			Value: err,
+		})
+	}
+	return prev, nil
+}
+
+// ReactionCountDelta 表态从 prev 变为 cur 时点赞数与踩数的变化量
+func ReactionCountDelta(prev, cur ReactionStatus) (int64, int64) {
+	delta := func(target ReactionStatus) int64 {
+		var d int64
+		if cur == target {
+			d++
+		}
+		if prev == target {
+			d--
+		}
+		return d
+	}
+	return delta(ReactionLike), delta(ReactionDislike)
+}
+
+// ListLikedArticles 分页获取用户点赞过的文章，游标为点赞时间与点赞记录 id
+func (service *articleService) ListLikedArticles(ctx *gin.Context, userId int64, cursor ListCursor, limit int64) ([]*LikedArticle, ListCursor, bool, error) {
+	// 多取一条用于判断是否还有下一页
+	records, err := service.lr.ListUserLikes(ctx, userId, cursor, limit+1)
+	if err != nil {
+		return nil, ListCursor{}, false, err
+	}
+	records, next, hasMore := pageLikeRecords(records, limit)
+	ids := slice.Map[*LikeRecord, int64](records, func(idx int, src *LikeRecord) int64 {
+		return src.ArticleId
+	})
+	articles, err := service.rr.GetPubArticlesByIds(ctx, ids)
+	if err != nil {
+		return nil, ListCursor{}, false, err
+	}
+	service.fillInteractives(ctx, articles)
+	articleMap := make(map[int64]*Article, len(articles))
+	for _, article := range articles {
+		articleMap[article.Id] = article
+	}
+	res := make([]*LikedArticle, 0, len(records))
+	for _, record := range records {
+		// 查询记录之后文章可能恰好被撤回，此时跳过
+		if article, ok := articleMap[record.ArticleId]; ok {
+			res = append(res, &LikedArticle{Article: *article, LikedTime: record.LikedTime})
+		}
+	}
+	return res, next, hasMore, nil
+}
+
+// ListArticleLikers 分页获取已发表文章的点赞用户
+func (service *articleService) ListArticleLikers(ctx *gin.Context, articleId int64, cursor ListCursor, limit int64) ([]*LikeRecord, ListCursor, bool, error) {
+	if _, err := service.rr.GetPubArticleById(ctx, articleId); err != nil {
+		return nil, ListCursor{}, false, err
+	}
+	records, err := service.lr.ListArticleLikers(ctx, articleId, cursor, limit+1)
+	if err != nil {
+		return nil, ListCursor{}, false, err
+	}
+	records, next, hasMore := pageLikeRecords(records, limit)
+	return records, next, hasMore, nil
+}
+
+func pageLikeRecords(records []*LikeRecord, limit int64) ([]*LikeRecord, ListCursor, bool) {
+	hasMore := int64(len(records)) > limit
+	if hasMore {
+		records = records[:limit]
+	}
+	next := ListCursor{}
+	if hasMore {
+		last := records[len(records)-1]
+		next = ListCursor{Value: last.LikedTime, Id: last.Id}
+	}
+	return records, next, hasMore
+}
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestReactionCountDelta(t *testing.T) {
+	testCases := []struct {
+		name             string
+		prev             ReactionStatus
+		cur              ReactionStatus
+		wantLikeDelta    int64
+		wantDislikeDelta int64
+	}{
+		{name: "点赞", prev: ReactionNormal, cur: ReactionLike, wantLikeDelta: 1},
+		{name: "踩", prev: ReactionNormal, cur: ReactionDislike, wantDislikeDelta: 1},
+		{name: "取消点赞", prev: ReactionLike, cur: ReactionNormal, wantLikeDelta: -1},
+		{name: "取消踩", prev: ReactionDislike, cur: ReactionNormal, wantDislikeDelta: -1},
+		{name: "点赞改为踩", prev: ReactionLike, cur: ReactionDislike, wantLikeDelta: -1, wantDislikeDelta: 1},
+		{name: "踩改为点赞", prev: ReactionDislike, cur: ReactionLike, wantLikeDelta: 1, wantDislikeDelta: -1},
+		{name: "重复点赞", prev: ReactionLike, cur: ReactionLike},
+		{name: "重复取消", prev: ReactionNormal, cur: ReactionNormal},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			likeDelta, dislikeDelta := ReactionCountDelta(tc.prev, tc.cur)
+			assert.Equal(t, tc.wantLikeDelta, likeDelta)
+			assert.Equal(t, tc.wantDislikeDelta, dislikeDelta)
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"github.com/gin-gonic/gin"
+	mylogger "ibook/pkg/utils/logger"
+	"time"
+)
+
+const (
+	// outboxLease 单个事件应用的租约时长，超时未完成的事件会被其他副本重新抢占
+	outboxLease = 30 * time.Second
+	// outboxMaxAttempts 应用失败的最大尝试次数，超过后移入死信，需要人工处理后重新投递
+	outboxMaxAttempts = 8
+	outboxBaseDelay   = time.Second
+	outboxMaxDelay    = 10 * time.Minute
+)
+
+type OutboxEventType uint8
+
+const (
+	OutboxEventUnknown OutboxEventType = iota
+	// OutboxEventPublish 将发表时的文章快照写入读者库
+	OutboxEventPublish
+	// OutboxEventStatus 同步作者库的文章状态到读者库
+	OutboxEventStatus
+)
+
+type OutboxStatus uint8
+
+const (
+	OutboxStatusUnknown OutboxStatus = iota
+	OutboxStatusPending
+	OutboxStatusRunning
+	OutboxStatusDone
+	// OutboxStatusDead 多次应用失败的死信事件
+	OutboxStatusDead
+	// OutboxStatusCanceled 文章被删除后不再需要应用的事件
+	OutboxStatusCanceled
+)
+
+// OutboxEvent 与作者库修改在同一事务中写入的事件，由 relay 异步应用到读者库与缓存
+type OutboxEvent struct {
+	Id        int64
+	ArticleId int64
+	Type      OutboxEventType
+	Status    OutboxStatus
+	Attempts  int64
+	LastErr   string
+	Version   int64
+}
+
+type ArticleOutboxRepo interface {
+	// ListDueEvents 获取待应用的事件，同一篇文章只返回最早的一个未完成事件以保证按顺序应用
+	// articleId 大于 0 时只获取该文章的事件
+	ListDueEvents(ctx *gin.Context, articleId int64, now int64, limit int64) ([]*OutboxEvent, error)
+	ClaimEvent(ctx *gin.Context, event *OutboxEvent, now int64, leaseUntil int64) (bool, error)
+	// ApplyEvent 将事件应用到读者库与缓存，重复应用同一事件或应用已过时的事件不会产生副作用
+	ApplyEvent(ctx *gin.Context, event *OutboxEvent) error
+	// FinishEvent 记录应用结果，status 为 pending 时表示稍后在 retryAt 重试
+	FinishEvent(ctx *gin.Context, event *OutboxEvent, status OutboxStatus, lastErr string, retryAt int64) error
+	// RequeueDeadEvents 将死信事件重新投递，返回重新投递的数量
+	RequeueDeadEvents(ctx *gin.Context) (int64, error)
+}
+
+// RelayOutboxEvents 应用一批到期的事件，返回成功应用的数量
+func (service *articleService) RelayOutboxEvents(ctx *gin.Context, articleId int64, limit int64) (int, error) {
+	l := mylogger.TagCtxLogger(ctx, service.logger, "RelayOutboxEvents")
+	now := time.Now().UnixMilli()
+	events, err := service.obr.ListDueEvents(ctx, articleId, now, limit)
+	if err != nil {
+		return 0, err
+	}
+	done := 0
+	for _, event := range events {
+		claimed, err := service.obr.ClaimEvent(ctx, event, now, now+outboxLease.Milliseconds())
+		if err != nil {
+			l.Warn("抢占发件箱事件失败", mylogger.Field{
+				Key:   "详情",
+				Value: err,
+			}, mylogger.Field{
+				Key:   "eventId",
+				Value: event.Id,
+			})
+			continue
+		}
+		if !claimed {
+			continue
+		}
+		status, lastErr, retryAt := OutboxStatusDone, "", int64(0)
+		if err = service.obr.ApplyEvent(ctx, event); err != nil {
+			l.Warn("应用发件箱事件失败", mylogger.Field{
+				Key:   "详情",
+				Value: err,
+			}, mylogger.Field{
+				Key:   "eventId",
+				Value: event.Id,
+			})
+			lastErr = err.Error()
+			status, retryAt = outboxRetryPolicy(event.Attempts, time.Now())
+		} else {
+			done++
+		}
+		if err = service.obr.FinishEvent(ctx, event, status, lastErr, retryAt); err != nil {
+			// 租约过期后事件会被重新应用，ApplyEvent 是幂等的
+			l.Error("记录发件箱事件结果失败", mylogger.Field{
+				Key:   "详情",
+				Value: err,
+			}, mylogger.Field{
+				Key:   "eventId",
+				Value: event.Id,
+			})
+		}
+	}
+	return done, nil
+}
+
+func (service *articleService) RequeueDeadOutboxEvents(ctx *gin.Context) (int64, error) {
+	return service.obr.RequeueDeadEvents(ctx)
+}
+
+// relayArticle 写入作者库后立即尝试应用该文章的事件，失败的事件留给 relay 任务重试
+func (service *articleService) relayArticle(ctx *gin.Context, articleId int64) {
+	if _, err := service.RelayOutboxEvents(ctx, articleId, 1); err != nil {
+		service.logger.Warn("[ArticleService] 立即应用发件箱事件失败，等待后台重试", mylogger.Field{
+			Key:   "详情",
+			Value: err,
+		})
+	}
+}
+
+// outboxRetryPolicy 应用失败后事件的状态与下次重试的毫秒时间戳，达到最大尝试次数后移入死信
+func outboxRetryPolicy(attempts int64, now time.Time) (OutboxStatus, int64) {
+	if attempts >= outboxMaxAttempts {
+		return OutboxStatusDead, 0
+	}
+	return OutboxStatusPending, now.Add(outboxRetryDelay(attempts)).UnixMilli()
+}
+
+// outboxRetryDelay 指数退避，attempts 为已尝试的次数
+func outboxRetryDelay(attempts int64) time.Duration {
+	delay := outboxBaseDelay << attempts
+	if delay <= 0 || delay > outboxMaxDelay {
+		return outboxMaxDelay
+	}
+	return delay
+}
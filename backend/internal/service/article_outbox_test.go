@@ -0,0 +1,49 @@
+package service
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestOutboxRetryDelay(t *testing.T) {
+	testCases := []struct {
+		name     string
+		attempts int64
+		want     time.Duration
+	}{
+		{name: "尚未尝试", attempts: 0, want: time.Second},
+		{name: "每次失败翻倍", attempts: 3, want: 8 * time.Second},
+		{name: "未达到上限", attempts: 9, want: 512 * time.Second},
+		{name: "超过上限时取上限", attempts: 10, want: outboxMaxDelay},
+		{name: "移位溢出时取上限", attempts: 64, want: outboxMaxDelay},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, outboxRetryDelay(tc.attempts))
+		})
+	}
+}
+
+func TestOutboxRetryPolicy(t *testing.T) {
+	now := time.UnixMilli(1_700_000_000_000)
+	testCases := []struct {
+		name        string
+		attempts    int64
+		wantStatus  OutboxStatus
+		wantRetryAt int64
+	}{
+		{name: "第一次失败", attempts: 1, wantStatus: OutboxStatusPending, wantRetryAt: now.Add(2 * time.Second).UnixMilli()},
+		{name: "最后一次重试", attempts: outboxMaxAttempts - 1, wantStatus: OutboxStatusPending,
+			wantRetryAt: now.Add(outboxRetryDelay(outboxMaxAttempts - 1)).UnixMilli()},
+		{name: "达到最大尝试次数", attempts: outboxMaxAttempts, wantStatus: OutboxStatusDead},
+		{name: "超过最大尝试次数", attempts: outboxMaxAttempts + 3, wantStatus: OutboxStatusDead},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			status, retryAt := outboxRetryPolicy(tc.attempts, now)
+			assert.Equal(t, tc.wantStatus, status)
+			assert.Equal(t, tc.wantRetryAt, retryAt)
+		})
+	}
+}
@@ -0,0 +1,263 @@
+package service
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	mylogger "ibook/pkg/utils/logger"
+	"strings"
+	"time"
+)
+
+const (
+	// reconcileBatch 每批比对的文章数量
+	reconcileBatch = 500
+	// reconcileMaxItems 报告中最多保留的不一致明细数量，其余只计入统计
+	reconcileMaxItems = 100
+)
+
+type ReconcileIssue uint8
+
+const (
+	ReconcileIssueUnknown ReconcileIssue = iota
+	// ReconcileIssueReaderMissing 作者库中已发表的文章在读者库中不存在
+	ReconcileIssueReaderMissing
+	// ReconcileIssueReaderOrphan 读者库中的文章在作者库中不存在或已删除
+	ReconcileIssueReaderOrphan
+	ReconcileIssueStatus
+	ReconcileIssueTitle
+	ReconcileIssueContent
+)
+
+func (issue ReconcileIssue) String() string {
+	switch issue {
+	case ReconcileIssueReaderMissing:
+		return "reader_missing"
+	case ReconcileIssueReaderOrphan:
+		return "reader_orphan"
+	case ReconcileIssueStatus:
+		return "status"
+	case ReconcileIssueTitle:
+		return "title"
+	case ReconcileIssueContent:
+		return "content"
+	default:
+		return "unknown"
+	}
+}
+
+// ReconcileRow 同一 id 在作者库与读者库中的对比数据，正文只比较哈希
+type ReconcileRow struct {
+	ArticleId int64
+	AuthorId  int64
+
+	AuthorExists      bool
+	AuthorDeleted     bool
+	AuthorStatus      ArticleStatus
+	AuthorTitle       string
+	AuthorContentHash string
+	AuthorVersion     int64
+
+	ReaderExists      bool
+	ReaderStatus      ArticleStatus
+	ReaderTitle       string
+	ReaderContentHash string
+
+	// Syncing 存在未完成（含死信）的发件箱事件，读者库尚未同步完成
+	Syncing bool
+}
+
+type ReconcileItem struct {
+	ArticleId int64
+	AuthorId  int64
+	Issues    []ReconcileIssue
+	Repaired  bool
+	RepairErr string
+}
+
+// ReconcileReport 一次比对的汇总结果
+type ReconcileReport struct {
+	DryRun     bool
+	Scanned    int64
+	Syncing    int64
+	Mismatched int64
+	Repaired   int64
+	Failed     int64
+	IssueCnt   map[ReconcileIssue]int64
+	// Items 不一致的明细，最多保留 reconcileMaxItems 条
+	Items       []*ReconcileItem
+	StartedTime int64
+	Duration    time.Duration
+}
+
+type ArticleReconcileRepo interface {
+	// ScanReconcileRows 按 id 升序分批获取 afterId 之后在任一库中存在的文章，返回本批扫描到的最大 id
+	ScanReconcileRows(ctx *gin.Context, afterId int64, limit int64) ([]*ReconcileRow, int64, error)
+	// EnqueueRepair 作者库中文章的版本仍为 version 时写入修复事件，articleR 为 nil 时只同步状态
+	EnqueueRepair(ctx *gin.Context, articleId int64, version int64, articleR *ArticleReader) error
+	// RemoveOrphanReader 作者库中文章已删除时将读者库中的文章设为私有，不存在时从读者库中移除该文章
+	RemoveOrphanReader(ctx *gin.Context, articleId int64) error
+	// TryLockReconcile 多副本之间抢占本轮比对，ttl 内只有一个副本能抢到
+	TryLockReconcile(ctx *gin.Context, ttl time.Duration) (bool, error)
+}
+
+// ReconcileArticles 分批比对作者库与读者库，repair 为 false 时只生成报告
+// lockTTL 大于 0 时先抢占本轮比对，已由其他副本执行时返回 nil
+func (service *articleService) ReconcileArticles(ctx *gin.Context, repair bool, lockTTL time.Duration) (*ReconcileReport, error) {
+	if lockTTL > 0 {
+		locked, err := service.rcc.TryLockReconcile(ctx, lockTTL)
+		if err != nil {
+			return nil, err
+		}
+		if !locked {
+			return nil, nil
+		}
+	}
+	start := time.Now()
+	report := &ReconcileReport{
+		DryRun:      !repair,
+		IssueCnt:    make(map[ReconcileIssue]int64),
+		StartedTime: start.UnixMilli(),
+	}
+	var afterId int64
+	for {
+		rows, maxId, err := service.rcc.ScanReconcileRows(ctx, afterId, reconcileBatch)
+		if err != nil {
+			return report, err
+		}
+		for _, row := range rows {
+			report.Scanned++
+			// 发件箱事件尚未应用完时两库本就不一致，交给 relay 处理
+			if row.Syncing {
+				report.Syncing++
+				continue
+			}
+			issues := reconcileIssues(row)
+			if len(issues) == 0 {
+				continue
+			}
+			report.Mismatched++
+			for _, issue := range issues {
+				report.IssueCnt[issue]++
+			}
+			item := &ReconcileItem{ArticleId: row.ArticleId, AuthorId: row.AuthorId, Issues: issues}
+			if repair {
+				if err = service.repairArticle(ctx, row, issues); err != nil {
+					report.Failed++
+					item.RepairErr = err.Error()
+				} else {
+					report.Repaired++
+					item.Repaired = true
+				}
+			}
+			if len(report.Items) < reconcileMaxItems {
+				report.Items = append(report.Items, item)
+			}
+		}
+		if maxId <= afterId {
+			break
+		}
+		afterId = maxId
+	}
+	report.Duration = time.Since(start)
+	return report, nil
+}
+
+// reconcileIssues 以作者库为准判断读者库是否一致
+// 作者库中的草稿（未发表状态）可能是发表后又编辑过的内容，不与读者库比较
+func reconcileIssues(row *ReconcileRow) []ReconcileIssue {
+	if !row.AuthorExists || row.AuthorDeleted {
+		// 回收站中的文章在读者库中保留为私有状态，直到彻底删除
+		if row.ReaderExists && !(row.AuthorDeleted && row.ReaderStatus == ArticleStatusPrivate) {
+			return []ReconcileIssue{ReconcileIssueReaderOrphan}
+		}
+		return nil
+	}
+	var issues []ReconcileIssue
+	switch row.AuthorStatus {
+	case ArticleStatusPublished:
+		if !row.ReaderExists {
+			return []ReconcileIssue{ReconcileIssueReaderMissing}
+		}
+		if row.ReaderStatus != ArticleStatusPublished {
+			issues = append(issues, ReconcileIssueStatus)
+		}
+		if row.ReaderTitle != row.AuthorTitle {
+			issues = append(issues, ReconcileIssueTitle)
+		}
+		if row.ReaderContentHash != row.AuthorContentHash {
+			issues = append(issues, ReconcileIssueContent)
+		}
+	case ArticleStatusPrivate:
+		// 从未发表过就撤回的文章在读者库中不存在
+		if row.ReaderExists && row.ReaderStatus != ArticleStatusPrivate {
+			issues = append(issues, ReconcileIssueStatus)
+		}
+	}
+	return issues
+}
+
+// repairArticle 通过发件箱修复读者库，与发表、撤回走同样的应用流程
+func (service *articleService) repairArticle(ctx *gin.Context, row *ReconcileRow, issues []ReconcileIssue) error {
+	if issues[0] == ReconcileIssueReaderOrphan {
+		if err := service.rcc.RemoveOrphanReader(ctx, row.ArticleId); err != nil {
+			return err
+		}
+		service.afterReaderRemoved(ctx, row.ArticleId)
+		return nil
+	}
+	var articleR *ArticleReader
+	if row.AuthorStatus == ArticleStatusPublished {
+		article, err := service.ar.GetArticleById(ctx, row.ArticleId, row.AuthorId)
+		if err != nil {
+			return err
+		}
+		if article.Version != row.AuthorVersion {
+			return ArticleVersionConflictErr
+		}
+		if articleR, err = toReaderArticle(article); err != nil {
+			return err
+		}
+	}
+	// 比对之后作者又修改了文章时返回版本冲突，新的修改会自行同步
+	if err := service.rcc.EnqueueRepair(ctx, row.ArticleId, row.AuthorVersion, articleR); err != nil {
+		return err
+	}
+	service.relayArticle(ctx, row.ArticleId)
+	if row.AuthorStatus == ArticleStatusPrivate {
+		service.afterReaderRemoved(ctx, row.ArticleId)
+	}
+	return nil
+}
+
+// afterReaderRemoved 文章不再对读者可见后，从搜索索引与热榜中移除
+func (service *articleService) afterReaderRemoved(ctx *gin.Context, articleId int64) {
+	l := mylogger.TagCtxLogger(ctx, service.logger, "ReconcileArticles")
+	if err := service.sch.RemoveArticle(ctx, articleId); err != nil {
+		l.Warn("更新搜索索引失败", mylogger.Field{
+			Key:   "详情",
+			Value: err,
+		})
+	}
+	if err := service.hr.RemoveHotArticle(ctx, articleId); err != nil {
+		l.Warn("移出热榜失败", mylogger.Field{
+			Key:   "详情",
+			Value: err,
+		})
+	}
+}
+
+// Summary 生成一行汇总，用于日志与命令行输出
+func (r *ReconcileReport) Summary() string {
+	mode := "repair"
+	if r.DryRun {
+		mode = "dry-run"
+	}
+	issues := make([]string, 0, len(r.IssueCnt))
+	for issue := ReconcileIssueReaderMissing; issue <= ReconcileIssueContent; issue++ {
+		if cnt := r.IssueCnt[issue]; cnt > 0 {
+			issues = append(issues, fmt.Sprintf("%s=%d", issue, cnt))
+		}
+	}
+	return fmt.Sprintf("[%s] 扫描 %d 篇，同步中 %d 篇，不一致 %d 篇（%s），已修复 %d 篇，修复失败 %d 篇，耗时 %s",
+		mode, r.Scanned, r.Syncing, r.Mismatched, strings.Join(issues, ", "), r.Repaired, r.Failed, r.Duration.Round(time.Millisecond))
+}
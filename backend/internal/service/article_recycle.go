@@ -0,0 +1,78 @@
+package service
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	mylogger "ibook/pkg/utils/logger"
+)
+
+var ArticleNotInRecycleBinErr = errors.New("文章不在回收站中或已超过可恢复期限")
+
+// DeletedArticle 回收站中的文章，PurgeTime 之后将被永久清除
+type DeletedArticle struct {
+	Article
+	DeletedTime int64
+	PurgeTime   int64
+}
+
+type ArticleRecycleRepo interface {
+	// DeleteArticle 在作者库中软删除文章，将读者库中的文章设为私有并移除缓存
+	DeleteArticle(ctx *gin.Context, articleId int64, authorId int64) error
+	ListDeletedArticles(ctx *gin.Context, authorId int64, offset int64, limit int64) ([]*DeletedArticle, error)
+	// RestoreArticle 将保留期限内的文章恢复为草稿
+	RestoreArticle(ctx *gin.Context, articleId int64, authorId int64) error
+	ListExpiredArticles(ctx *gin.Context, limit int64) ([]int64, error)
+	// PurgeArticle 永久删除文章及其互动、评论、历史版本等数据
+	PurgeArticle(ctx *gin.Context, articleId int64) error
+}
+
+func (service *articleService) DeleteArticle(ctx *gin.Context, articleId int64, authorId int64) error {
+	l := mylogger.TagCtxLogger(ctx, service.logger, "DeleteArticle")
+	if err := service.rcr.DeleteArticle(ctx, articleId, authorId); err != nil {
+		return err
+	}
+	// 以下清理失败时只记录日志：搜索结果与热榜在展示时会跳过读者库中不存在的文章
+	if err := service.sch.RemoveArticle(ctx, articleId); err != nil {
+		l.Warn("更新搜索索引失败", mylogger.Field{
+			Key:   "详情",
+			Value: err,
+		})
+	}
+	if err := service.hr.RemoveHotArticle(ctx, articleId); err != nil {
+		l.Warn("移出热榜失败", mylogger.Field{
+			Key:   "详情",
+			Value: err,
+		})
+	}
+	for _, action := range []ScheduleAction{ScheduleActionPublish, ScheduleActionWithdraw} {
+		if err := service.scr.CancelPendingSchedule(ctx, articleId, authorId, action); err != nil {
+			l.Warn("取消定时计划失败", mylogger.Field{
+				Key:   "详情",
+				Value: err,
+			})
+		}
+	}
+	return nil
+}
+
+func (service *articleService) ListRecycleBin(ctx *gin.Context, authorId int64, offset int64, limit int64) ([]*DeletedArticle, error) {
+	return service.rcr.ListDeletedArticles(ctx, authorId, offset, limit)
+}
+
+func (service *articleService) RestoreDeletedArticle(ctx *gin.Context, articleId int64, authorId int64) error {
+	return service.rcr.RestoreArticle(ctx, articleId, authorId)
+}
+
+// PurgeExpiredArticles 永久清除一批超过保留期限的文章，返回清除的数量
+func (service *articleService) PurgeExpiredArticles(ctx *gin.Context, limit int64) (int, error) {
+	ids, err := service.rcr.ListExpiredArticles(ctx, limit)
+	if err != nil {
+		return 0, err
+	}
+	for i, id := range ids {
+		if err = service.rcr.PurgeArticle(ctx, id); err != nil {
+			return i, err
+		}
+	}
+	return len(ids), nil
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"ibook/pkg/utils/textdiff"
+)
+
+var (
+	ArticleRevisionNotExistsErr = errors.New("历史版本不存在")
+	ArticleRevisionMismatchErr  = errors.New("两个历史版本不属于同一篇文章")
+)
+
+type RevisionKind uint8
+
+const (
+	RevisionKindUnknown RevisionKind = iota
+	RevisionKindSave
+	RevisionKindPublish
+	RevisionKindRestore
+)
+
+type DiffMode string
+
+const (
+	DiffModeLine DiffMode = "line"
+	DiffModeWord DiffMode = "word"
+)
+
+type ArticleRevision struct {
+	Id          int64
+	ArticleId   int64
+	AuthorId    int64
+	Title       string
+	Content     string
+	Format      ContentFormat
+	Kind        RevisionKind
+	CreatedTime int64
+}
+
+// RevisionDiff 两个历史版本之间标题与正文的差异
+type RevisionDiff struct {
+	From    *ArticleRevision
+	To      *ArticleRevision
+	Title   []textdiff.Op
+	Content []textdiff.Op
+}
+
+type ArticleRevisionRepo interface {
+	ListRevisions(ctx *gin.Context, articleId int64, authorId int64, offset int64, limit int64) ([]*ArticleRevision, error)
+	GetRevision(ctx *gin.Context, revisionId int64, authorId int64) (*ArticleRevision, error)
+}
+
+func (service *articleService) ListRevisions(ctx *gin.Context, articleId int64, authorId int64, offset int64, limit int64) ([]*ArticleRevision, error) {
+	return service.rvr.ListRevisions(ctx, articleId, authorId, offset, limit)
+}
+
+func (service *articleService) GetRevision(ctx *gin.Context, revisionId int64, authorId int64) (*ArticleRevision, error) {
+	return service.rvr.GetRevision(ctx, revisionId, authorId)
+}
+
+func (service *articleService) DiffRevisions(ctx *gin.Context, fromId int64, toId int64, authorId int64, mode DiffMode) (*RevisionDiff, error) {
+	from, err := service.rvr.GetRevision(ctx, fromId, authorId)
+	if err != nil {
+		return nil, err
+	}
+	to, err := service.rvr.GetRevision(ctx, toId, authorId)
+	if err != nil {
+		return nil, err
+	}
+	if from.ArticleId != to.ArticleId {
+		return nil, ArticleRevisionMismatchErr
+	}
+	split := textdiff.Lines
+	if mode == DiffModeWord {
+		split = textdiff.Words
+	}
+	return &RevisionDiff{
+		From: from,
+		To:   to,
+		// 标题较短，始终按词比较
+		Title:   textdiff.Diff(textdiff.Words(from.Title), textdiff.Words(to.Title)),
+		Content: textdiff.Diff(split(from.Content), split(to.Content)),
+	}, nil
+}
+
+// RestoreRevision 将历史版本恢复为当前草稿，已发表的内容不受影响，需要重新发表
+// version 为客户端当前草稿的版本号，与服务端不一致时返回 ArticleConflictError
+func (service *articleService) RestoreRevision(ctx *gin.Context, revisionId int64, authorId int64, version int64) (*Article, error) {
+	rev, err := service.rvr.GetRevision(ctx, revisionId, authorId)
+	if err != nil {
+		return nil, err
+	}
+	// 历史版本只记录标题与正文，分类与标签保持当前草稿的设置
+	current, err := service.ar.GetArticleById(ctx, rev.ArticleId, authorId)
+	if err != nil {
+		return nil, err
+	}
+	articleA := &ArticleAuthor{Article{
+		Id:       rev.ArticleId,
+		Title:    rev.Title,
+		Content:  rev.Content,
+		Format:   rev.Format,
+		Category: current.Category,
+		Author:   Author{Id: authorId},
+		Status:   ArticleStatusUnpublished,
+		Version:  version,
+	}}
+	_, err = service.ar.UpdateArticle(ctx, articleA, RevisionKindRestore)
+	if errors.Is(err, ArticleVersionConflictErr) {
+		return nil, service.conflictError(ctx, &articleA.Article)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &articleA.Article, nil
+}
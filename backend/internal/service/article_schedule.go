@@ -0,0 +1,191 @@
+package service
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	mylogger "ibook/pkg/utils/logger"
+	"time"
+)
+
+var (
+	ArticleScheduleNotExistsErr = errors.New("定时计划不存在或已执行")
+	ScheduleTimeInvalidErr      = errors.New("定时时间不合法")
+)
+
+const (
+	// scheduleLease 单次执行的租约时长，超时未完成的计划会被其他副本重新抢占
+	scheduleLease = time.Minute
+	// scheduleMaxAttempts 执行失败的最大尝试次数，超过后标记为失败
+	scheduleMaxAttempts = 3
+	scheduleRetryDelay  = 30 * time.Second
+)
+
+type ScheduleAction uint8
+
+const (
+	ScheduleActionUnknown ScheduleAction = iota
+	ScheduleActionPublish
+	ScheduleActionWithdraw
+)
+
+type ScheduleStatus uint8
+
+const (
+	ScheduleStatusUnknown ScheduleStatus = iota
+	ScheduleStatusPending
+	ScheduleStatusRunning
+	ScheduleStatusDone
+	ScheduleStatusCanceled
+	ScheduleStatusFailed
+)
+
+type ArticleSchedule struct {
+	Id          int64
+	ArticleId   int64
+	AuthorId    int64
+	Action      ScheduleAction
+	ExecuteAt   int64
+	Status      ScheduleStatus
+	Attempts    int64
+	LastErr     string
+	Version     int64
+	CreatedTime int64
+	UpdatedTime int64
+}
+
+// PublishSchedule 发表文章时的定时选项，时间均为毫秒时间戳
+// PublishAt 为零或早于当前时间时立即发表，ExpireAt 为零时不自动撤回
+type PublishSchedule struct {
+	PublishAt int64
+	ExpireAt  int64
+}
+
+type ArticleScheduleRepo interface {
+	UpsertPendingSchedule(ctx *gin.Context, schedule *ArticleSchedule) error
+	CancelPendingSchedule(ctx *gin.Context, articleId int64, authorId int64, action ScheduleAction) error
+	GetSchedule(ctx *gin.Context, scheduleId int64, authorId int64) (*ArticleSchedule, error)
+	ListPendingSchedules(ctx *gin.Context, authorId int64, articleId int64) ([]*ArticleSchedule, error)
+	UpdateScheduleTime(ctx *gin.Context, scheduleId int64, authorId int64, executeAt int64) error
+	CancelSchedule(ctx *gin.Context, scheduleId int64, authorId int64) error
+	ListDueSchedules(ctx *gin.Context, now int64, limit int64) ([]*ArticleSchedule, error)
+	ClaimSchedule(ctx *gin.Context, schedule *ArticleSchedule, now int64, leaseUntil int64) (bool, error)
+	FinishSchedule(ctx *gin.Context, schedule *ArticleSchedule, status ScheduleStatus, lastErr string, retryAt int64) error
+}
+
+func (service *articleService) ListSchedules(ctx *gin.Context, authorId int64, articleId int64) ([]*ArticleSchedule, error) {
+	return service.scr.ListPendingSchedules(ctx, authorId, articleId)
+}
+
+// UpdateSchedule 修改待执行计划的时间，需保证同一篇文章的定时发表早于定时撤回
+func (service *articleService) UpdateSchedule(ctx *gin.Context, scheduleId int64, authorId int64, executeAt int64) error {
+	if executeAt <= time.Now().UnixMilli() {
+		return ScheduleTimeInvalidErr
+	}
+	schedule, err := service.scr.GetSchedule(ctx, scheduleId, authorId)
+	if err != nil {
+		return err
+	}
+	if schedule.Status != ScheduleStatusPending {
+		return ArticleScheduleNotExistsErr
+	}
+	others, err := service.scr.ListPendingSchedules(ctx, authorId, schedule.ArticleId)
+	if err != nil {
+		return err
+	}
+	for _, other := range others {
+		if other.Id == schedule.Id {
+			continue
+		}
+		if schedule.Action == ScheduleActionPublish && other.Action == ScheduleActionWithdraw && executeAt >= other.ExecuteAt ||
+			schedule.Action == ScheduleActionWithdraw && other.Action == ScheduleActionPublish && executeAt <= other.ExecuteAt {
+			return ScheduleTimeInvalidErr
+		}
+	}
+	return service.scr.UpdateScheduleTime(ctx, scheduleId, authorId, executeAt)
+}
+
+func (service *articleService) CancelSchedule(ctx *gin.Context, scheduleId int64, authorId int64) error {
+	return service.scr.CancelSchedule(ctx, scheduleId, authorId)
+}
+
+// ExecuteDueSchedules 执行到期的定时计划，返回本次成功执行的数量
+func (service *articleService) ExecuteDueSchedules(ctx *gin.Context, limit int64) (int, error) {
+	l := mylogger.TagCtxLogger(ctx, service.logger, "ExecuteDueSchedules")
+	now := time.Now().UnixMilli()
+	schedules, err := service.scr.ListDueSchedules(ctx, now, limit)
+	if err != nil {
+		return 0, err
+	}
+	done := 0
+	for _, schedule := range schedules {
+		claimed, err := service.scr.ClaimSchedule(ctx, schedule, now, now+scheduleLease.Milliseconds())
+		if err != nil {
+			l.Warn("抢占定时计划失败", mylogger.Field{
+				Key:   "详情",
+				Value: err,
+			}, mylogger.Field{
+				Key:   "scheduleId",
+				Value: schedule.Id,
+			})
+			continue
+		}
+		if !claimed {
+			continue
+		}
+		status, lastErr, retryAt := ScheduleStatusDone, "", int64(0)
+		if err = service.executeSchedule(ctx, schedule); err != nil {
+			l.Warn("执行定时计划失败", mylogger.Field{
+				Key:   "详情",
+				Value: err,
+			}, mylogger.Field{
+				Key:   "scheduleId",
+				Value: schedule.Id,
+			})
+			lastErr = err.Error()
+			status = ScheduleStatusFailed
+			if schedule.Attempts < scheduleMaxAttempts {
+				status = ScheduleStatusPending
+				retryAt = time.Now().Add(scheduleRetryDelay).UnixMilli()
+			}
+		} else {
+			done++
+		}
+		if err = service.scr.FinishSchedule(ctx, schedule, status, lastErr, retryAt); err != nil {
+			// 租约过期后计划会被重新执行，发表与撤回都是幂等的
+			l.Error("记录定时计划执行结果失败", mylogger.Field{
+				Key:   "详情",
+				Value: err,
+			}, mylogger.Field{
+				Key:   "scheduleId",
+				Value: schedule.Id,
+			})
+		}
+	}
+	return done, nil
+}
+
+func (service *articleService) executeSchedule(ctx *gin.Context, schedule *ArticleSchedule) error {
+	switch schedule.Action {
+	case ScheduleActionPublish:
+		// 定时发表的是到期时草稿的最新内容
+		article, err := service.ar.GetArticleById(ctx, schedule.ArticleId, schedule.AuthorId)
+		if err != nil {
+			return err
+		}
+		return service.publish(ctx, article)
+	case ScheduleActionWithdraw:
+		return service.withdraw(ctx, schedule.ArticleId, schedule.AuthorId)
+	default:
+		return errors.New("未知的定时计划类型")
+	}
+}
+
+// checkPublishSchedule 校验定时选项，返回是否需要立即发表
+func checkPublishSchedule(schedule PublishSchedule) (bool, error) {
+	now := time.Now().UnixMilli()
+	publishNow := schedule.PublishAt <= now
+	if schedule.ExpireAt > 0 && (schedule.ExpireAt <= now || !publishNow && schedule.ExpireAt <= schedule.PublishAt) {
+		return false, ScheduleTimeInvalidErr
+	}
+	return publishNow, nil
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"ibook/pkg/utils/bskit/slice"
+	mylogger "ibook/pkg/utils/logger"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	ArticleTagInvalidErr      = errors.New("文章标签不合法或数量超出限制")
+	ArticleCategoryInvalidErr = errors.New("文章分类不合法")
+)
+
+const (
+	maxTagsPerArticle = 5
+	maxTagLength      = 32
+	maxCategoryLength = 32
+)
+
+// ArticleListFilter 已发表文章列表的筛选条件，零值表示不筛选
+type ArticleListFilter struct {
+	AuthorId int64
+	Tag      string
+	Category string
+	// AuthorIds 关注流中拉取多个作者的文章，只在 ArticleReaderRepo.ListByFilter 中生效
+	AuthorIds []int64
+}
+
+func (f ArticleListFilter) hasTaxonomy() bool {
+	return f.Tag != "" || f.Category != ""
+}
+
+type TagCount struct {
+	Name       string
+	ArticleCnt int64
+}
+
+type ArticleTagRepo interface {
+	ListTagsByArticleIds(ctx *gin.Context, ids []int64) (map[int64][]string, error)
+	ListTagCounts(ctx *gin.Context, limit int64) ([]*TagCount, error)
+}
+
+func (service *articleService) ListTagCounts(ctx *gin.Context, limit int64) ([]*TagCount, error) {
+	return service.tr.ListTagCounts(ctx, limit)
+}
+
+// NormalizeTag 去除首尾空白并将英文字母转为小写，使同一标签只对应一条记录
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// normalizeTaxonomy 规范化并校验文章的标签与分类，Tags 为 nil 时表示不修改标签
+func normalizeTaxonomy(article *Article) error {
+	article.Category = strings.TrimSpace(article.Category)
+	if utf8.RuneCountInString(article.Category) > maxCategoryLength {
+		return ArticleCategoryInvalidErr
+	}
+	if article.Tags == nil {
+		return nil
+	}
+	tags := make([]string, 0, len(article.Tags))
+	seen := make(map[string]struct{}, len(article.Tags))
+	for _, tag := range article.Tags {
+		tag = NormalizeTag(tag)
+		if tag == "" {
+			continue
+		}
+		if utf8.RuneCountInString(tag) > maxTagLength {
+			return ArticleTagInvalidErr
+		}
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+		seen[tag] = struct{}{}
+		tags = append(tags, tag)
+	}
+	if len(tags) > maxTagsPerArticle {
+		return ArticleTagInvalidErr
+	}
+	article.Tags = tags
+	return nil
+}
+
+// fillTags 批量填充读者库文章的标签，查询失败时不影响文章本身的展示
+func (service *articleService) fillTags(ctx *gin.Context, articles []*Article) {
+	if len(articles) == 0 {
+		return
+	}
+	ids := slice.Map[*Article, int64](articles, func(idx int, src *Article) int64 {
+		return src.Id
+	})
+	tagMap, err := service.tr.ListTagsByArticleIds(ctx, ids)
+	if err != nil {
+		l := mylogger.TagCtxLogger(ctx, service.logger, "fillTags")
+		l.Warn("获取文章标签失败", mylogger.Field{
+			Key:   "详情",
+			Value: err,
+		})
+		return
+	}
+	for _, article := range articles {
+		article.Tags = tagMap[article.Id]
+	}
+}
@@ -0,0 +1,292 @@
+package service
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	mylogger "ibook/pkg/utils/logger"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	CommentNotExistsErr = errors.New("评论不存在")
+	CommentForbiddenErr = errors.New("无权删除此评论")
+	CommentInvalidErr   = errors.New("评论内容为空或超出长度限制")
+)
+
+const (
+	maxCommentLength = 1000
+	// replyPreviewSize 一级评论列表中每条评论附带的回复数量
+	replyPreviewSize = 3
+	// commentFirstPageSize 缓存的评论首页条数
+	commentFirstPageSize = 20
+)
+
+type CommentSort uint8
+
+const (
+	CommentSortTime CommentSort = iota
+	CommentSortLike
+)
+
+// Comment 评论分为两级：RootId 为 0 的一级评论，以及挂在一级评论下的回复
+// 回复可以回复一级评论或同一楼内的其他回复，ParentId 与 ReplyToUid 记录被回复的评论及其作者
+type Comment struct {
+	Id          int64
+	ArticleId   int64
+	UserId      int64
+	UserName    string
+	RootId      int64
+	ParentId    int64
+	ReplyToUid  int64
+	Content     string
+	LikeCnt     int64
+	ReplyCnt    int64
+	CreatedTime int64
+	Liked       bool
+	// Replies 一级评论附带的最早几条回复
+	Replies []*Comment
+}
+
+// cursor 返回以该评论为末尾时下一页的游标
+func (c *Comment) cursor(sort CommentSort) ListCursor {
+	if sort == CommentSortLike {
+		return ListCursor{Value: c.LikeCnt, Id: c.Id}
+	}
+	return ListCursor{Value: c.CreatedTime, Id: c.Id}
+}
+
+type CommentRepo interface {
+	// CreateComment 校验文章与被回复的评论，写入评论并更新评论计数，成功后回填 Id、RootId 与 ReplyToUid
+	CreateComment(ctx *gin.Context, comment *Comment) error
+	// GetCommentById 返回评论以及所属文章的作者 id
+	GetCommentById(ctx *gin.Context, commentId int64) (*Comment, int64, error)
+	// DeleteComment 删除评论，一级评论会连同其下的回复一起删除，返回删除的评论数
+	DeleteComment(ctx *gin.Context, comment *Comment) (int64, error)
+	ListRootComments(ctx *gin.Context, articleId int64, sort CommentSort, cursor ListCursor, limit int64) ([]*Comment, error)
+	// ListReplies 按时间正序获取一级评论下的回复
+	ListReplies(ctx *gin.Context, rootId int64, cursor ListCursor, limit int64) ([]*Comment, error)
+	// ListReplyPreviews 批量获取每条一级评论下最早的 n 条回复
+	ListReplyPreviews(ctx *gin.Context, rootIds []int64, n int) (map[int64][]*Comment, error)
+	// UpsertCommentLike 与 CancelCommentLike 返回点赞状态是否发生了变化
+	UpsertCommentLike(ctx *gin.Context, userId int64, commentId int64) (bool, error)
+	CancelCommentLike(ctx *gin.Context, userId int64, commentId int64) (bool, error)
+	LikedComments(ctx *gin.Context, userId int64, commentIds []int64) (map[int64]bool, error)
+}
+
+// CommentCache 缓存文章一级评论的首页，点赞状态因人而异，不进入缓存
+type CommentCache interface {
+	GetFirstPage(ctx *gin.Context, articleId int64, sort CommentSort) ([]*Comment, error)
+	SetFirstPage(ctx *gin.Context, articleId int64, sort CommentSort, comments []*Comment) error
+	DelFirstPage(ctx *gin.Context, articleId int64) error
+}
+
+type CommentService interface {
+	CreateComment(ctx *gin.Context, comment *Comment) error
+	// DeleteComment 评论者本人与文章作者可以删除评论
+	DeleteComment(ctx *gin.Context, commentId int64, operatorId int64) error
+	// ListComments 返回本页一级评论、下一页的游标以及是否还有更多评论
+	ListComments(ctx *gin.Context, articleId int64, viewerId int64, sort CommentSort, cursor ListCursor, limit int64) ([]*Comment, ListCursor, bool, error)
+	ListReplies(ctx *gin.Context, rootId int64, viewerId int64, cursor ListCursor, limit int64) ([]*Comment, ListCursor, bool, error)
+	LikeComment(ctx *gin.Context, userId int64, commentId int64) error
+	CancelLikeComment(ctx *gin.Context, userId int64, commentId int64) error
+}
+
+type commentService struct {
+	cr     CommentRepo
+	cc     CommentCache
+	aic    ArticleInteractiveCache
+	logger mylogger.Logger
+}
+
+func NewCommentService(cr CommentRepo, cc CommentCache, aic ArticleInteractiveCache, logger mylogger.Logger) CommentService {
+	return &commentService{cr: cr, cc: cc, aic: aic, logger: logger}
+}
+
+func (service *commentService) CreateComment(ctx *gin.Context, comment *Comment) error {
+	comment.Content = strings.TrimSpace(comment.Content)
+	if comment.Content == "" || utf8.RuneCountInString(comment.Content) > maxCommentLength {
+		return CommentInvalidErr
+	}
+	if err := service.cr.CreateComment(ctx, comment); err != nil {
+		return err
+	}
+	service.afterCommentsChanged(ctx, comment.ArticleId, 1)
+	return nil
+}
+
+func (service *commentService) DeleteComment(ctx *gin.Context, commentId int64, operatorId int64) error {
+	comment, authorId, err := service.cr.GetCommentById(ctx, commentId)
+	if err != nil {
+		return err
+	}
+	if comment.UserId != operatorId && authorId != operatorId {
+		return CommentForbiddenErr
+	}
+	deleted, err := service.cr.DeleteComment(ctx, comment)
+	if err != nil {
+		return err
+	}
+	if deleted > 0 {
+		service.afterCommentsChanged(ctx, comment.ArticleId, -deleted)
+	}
+	return nil
+}
+
+// afterCommentsChanged 评论增删或点赞后更新计数缓存并删除评论首页缓存，失败只记录日志，由缓存过期兜底
+func (service *commentService) afterCommentsChanged(ctx *gin.Context, articleId int64, delta int64) {
+	l := mylogger.TagCtxLogger(ctx, service.logger, "CommentService")
+	if delta != 0 {
+		if err := service.aic.IncrCommentCountInCache(ctx, articleId, delta); err != nil {
+			l.Warn("更新评论计数缓存失败", mylogger.Field{
+				Key:   "详情",
+				Value: err,
+			})
+		}
+	}
+	if err := service.cc.DelFirstPage(ctx, articleId); err != nil {
+		l.Warn("删除评论首页缓存失败", mylogger.Field{
+			Key:   "详情",
+			Value: err,
+		})
+	}
+}
+
+func (service *commentService) ListComments(ctx *gin.Context, articleId int64, viewerId int64, sort CommentSort, cursor ListCursor, limit int64) ([]*Comment, ListCursor, bool, error) {
+	l := mylogger.TagCtxLogger(ctx, service.logger, "ListComments")
+	var comments []*Comment
+	var err error
+	// 首页优先读缓存，缓存中多存一条用于判断是否还有下一页
+	cacheable := cursor.IsZero() && limit <= commentFirstPageSize
+	if cacheable {
+		comments, err = service.cc.GetFirstPage(ctx, articleId, sort)
+		if err != nil {
+			comments = nil
+		}
+	}
+	if comments == nil {
+		fetch := limit + 1
+		if cacheable {
+			fetch = commentFirstPageSize + 1
+		}
+		comments, err = service.cr.ListRootComments(ctx, articleId, sort, cursor, fetch)
+		if err != nil {
+			return nil, ListCursor{}, false, err
+		}
+		if err = service.fillReplyPreviews(ctx, comments); err != nil {
+			return nil, ListCursor{}, false, err
+		}
+		if cacheable {
+			if err = service.cc.SetFirstPage(ctx, articleId, sort, comments); err != nil {
+				l.Warn("写入评论首页缓存失败", mylogger.Field{
+					Key:   "详情",
+					Value: err,
+				})
+			}
+		}
+	}
+	hasMore := int64(len(comments)) > limit
+	if hasMore {
+		comments = comments[:limit]
+	}
+	var next ListCursor
+	if hasMore {
+		next = comments[len(comments)-1].cursor(sort)
+	}
+	service.fillLiked(ctx, viewerId, comments)
+	return comments, next, hasMore, nil
+}
+
+func (service *commentService) ListReplies(ctx *gin.Context, rootId int64, viewerId int64, cursor ListCursor, limit int64) ([]*Comment, ListCursor, bool, error) {
+	replies, err := service.cr.ListReplies(ctx, rootId, cursor, limit+1)
+	if err != nil {
+		return nil, ListCursor{}, false, err
+	}
+	hasMore := int64(len(replies)) > limit
+	if hasMore {
+		replies = replies[:limit]
+	}
+	var next ListCursor
+	if hasMore {
+		next = replies[len(replies)-1].cursor(CommentSortTime)
+	}
+	service.fillLiked(ctx, viewerId, replies)
+	return replies, next, hasMore, nil
+}
+
+func (service *commentService) LikeComment(ctx *gin.Context, userId int64, commentId int64) error {
+	comment, _, err := service.cr.GetCommentById(ctx, commentId)
+	if err != nil {
+		return err
+	}
+	changed, err := service.cr.UpsertCommentLike(ctx, userId, commentId)
+	if err != nil {
+		return err
+	}
+	if changed {
+		service.afterCommentsChanged(ctx, comment.ArticleId, 0)
+	}
+	return nil
+}
+
+func (service *commentService) CancelLikeComment(ctx *gin.Context, userId int64, commentId int64) error {
+	comment, _, err := service.cr.GetCommentById(ctx, commentId)
+	if err != nil {
+		return err
+	}
+	changed, err := service.cr.CancelCommentLike(ctx, userId, commentId)
+	if err != nil {
+		return err
+	}
+	if changed {
+		service.afterCommentsChanged(ctx, comment.ArticleId, 0)
+	}
+	return nil
+}
+
+func (service *commentService) fillReplyPreviews(ctx *gin.Context, comments []*Comment) error {
+	rootIds := make([]int64, 0, len(comments))
+	for _, c := range comments {
+		if c.ReplyCnt > 0 {
+			rootIds = append(rootIds, c.Id)
+		}
+	}
+	if len(rootIds) == 0 {
+		return nil
+	}
+	previews, err := service.cr.ListReplyPreviews(ctx, rootIds, replyPreviewSize)
+	if err != nil {
+		return err
+	}
+	for _, c := range comments {
+		c.Replies = previews[c.Id]
+	}
+	return nil
+}
+
+// fillLiked 填充访问者对评论及其附带回复的点赞状态，查询失败时按未点赞处理
+func (service *commentService) fillLiked(ctx *gin.Context, viewerId int64, comments []*Comment) {
+	if viewerId <= 0 || len(comments) == 0 {
+		return
+	}
+	var all []*Comment
+	for _, c := range comments {
+		all = append(all, c)
+		all = append(all, c.Replies...)
+	}
+	ids := make([]int64, 0, len(all))
+	for _, c := range all {
+		ids = append(ids, c.Id)
+	}
+	liked, err := service.cr.LikedComments(ctx, viewerId, ids)
+	if err != nil {
+		service.logger.Warn("[CommentService] 获取评论点赞状态失败", mylogger.Field{
+			Key:   "详情",
+			Value: err,
+		})
+		return
+	}
+	for _, c := range all {
+		c.Liked = liked[c.Id]
+	}
+}
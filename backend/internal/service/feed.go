@@ -0,0 +1,180 @@
+package service
+
+import (
+	"github.com/gin-gonic/gin"
+	"ibook/pkg/utils/bskit/slice"
+	mylogger "ibook/pkg/utils/logger"
+	"sort"
+	"time"
+)
+
+const (
+	// feedPushMaxFollowers 粉丝数达到该值的作者发表文章时不再推送到粉丝的收件箱，由粉丝读取关注流时拉取
+	feedPushMaxFollowers = 5000
+	// feedFanoutBatch 推送时每批写入的收件箱数量
+	feedFanoutBatch = 500
+)
+
+// FeedItem 关注流中的一篇文章，PublishedTime 为最近一次发表的时间
+type FeedItem struct {
+	ArticleId     int64
+	AuthorId      int64
+	PublishedTime int64
+}
+
+type FeedRepo interface {
+	// PushInbox 将文章写入多个用户的收件箱，同一篇文章重复写入时只更新发表时间，收件箱只保留最新的一部分文章
+	PushInbox(ctx *gin.Context, userIds []int64, item *FeedItem) error
+	// ListInbox 按发表时间与文章 id 倒序获取收件箱中游标之后的文章
+	ListInbox(ctx *gin.Context, userId int64, cursor ListCursor, limit int64) ([]*FeedItem, error)
+}
+
+// fanoutFeed 将新发表的文章推送到作者粉丝的收件箱，粉丝较多的作者跳过推送，由粉丝读取时拉取
+// 发表时在单独的 goroutine 中执行，推送失败只记录日志，不影响文章发表
+func (service *articleService) fanoutFeed(ctx *gin.Context, articleId int64, authorId int64) {
+	l := mylogger.TagCtxLogger(ctx, service.logger, "fanoutFeed")
+	stat, err := service.fr.GetStatistic(ctx, authorId)
+	if err != nil {
+		l.Warn("获取作者粉丝数失败，跳过推送", mylogger.Field{
+			Key:   "详情",
+			Value: err,
+		})
+		return
+	}
+	if stat.FollowerCnt == 0 || stat.FollowerCnt >= feedPushMaxFollowers {
+		return
+	}
+	item := &FeedItem{
+		ArticleId:     articleId,
+		AuthorId:      authorId,
+		PublishedTime: time.Now().UTC().UnixMilli(),
+	}
+	var afterId int64
+	for {
+		followerIds, err := service.fr.ListFollowerIds(ctx, authorId, afterId, feedFanoutBatch)
+		if err != nil {
+			l.Warn("获取粉丝列表失败，推送中止", mylogger.Field{
+				Key:   "详情",
+				Value: err,
+			})
+			return
+		}
+		if len(followerIds) == 0 {
+			return
+		}
+		if err = service.fdr.PushInbox(ctx, followerIds, item); err != nil {
+			l.Warn("推送到收件箱失败", mylogger.Field{
+				Key:   "详情",
+				Value: err,
+			})
+		}
+		if len(followerIds) < feedFanoutBatch {
+			return
+		}
+		afterId = followerIds[len(followerIds)-1]
+	}
+}
+
+// ListFeed 按发表时间倒序获取关注的作者发表的文章，游标为发表时间与文章 id
+// 普通作者的文章来自收件箱，粉丝较多的作者的文章在读取时从读者库拉取，两者合并后返回
+func (service *articleService) ListFeed(ctx *gin.Context, userId int64, cursor ListCursor, limit int64) ([]*Article, ListCursor, bool, error) {
+	// 1. 两个来源都多取一条用于判断是否还有下一页
+	inbox, err := service.fdr.ListInbox(ctx, userId, cursor, limit+1)
+	if err != nil {
+		return nil, ListCursor{}, false, err
+	}
+	popularIds, err := service.fr.ListPopularFollowees(ctx, userId, feedPushMaxFollowers)
+	if err != nil {
+		return nil, ListCursor{}, false, err
+	}
+	var pulled []*Article
+	if len(popularIds) > 0 {
+		pulled, err = service.rr.ListByFilter(ctx, ArticleListFilter{AuthorIds: popularIds}, cursor, limit+1)
+		if err != nil {
+			return nil, ListCursor{}, false, err
+		}
+	}
+	// 2. 收件箱中的文章可能已被撤回，或者作者已被取消关注，这些文章在合并时跳过
+	// 作者粉丝数增长到推送上限后，其之前推送的文章仍留在收件箱中，同时也会被拉取，
+	// 两个来源中的位置不同，翻页时会重复出现。因此粉丝较多的作者的文章只以拉取的位置为准，跳过收件箱中的记录
+	popular := make(map[int64]bool, len(popularIds))
+	for _, id := range popularIds {
+		popular[id] = true
+	}
+	authorIds := slice.Map[*FeedItem, int64](inbox, func(idx int, src *FeedItem) int64 {
+		return src.AuthorId
+	})
+	followedIds, err := service.fr.FilterFollowed(ctx, userId, authorIds)
+	if err != nil {
+		return nil, ListCursor{}, false, err
+	}
+	followed := make(map[int64]bool, len(followedIds))
+	for _, id := range followedIds {
+		followed[id] = true
+	}
+	inboxIds := slice.FilterMap[*FeedItem, int64](inbox, func(idx int, src *FeedItem) (int64, bool) {
+		return src.ArticleId, followed[src.AuthorId] && !popular[src.AuthorId]
+	})
+	inboxArticles, err := service.rr.GetPubArticlesByIds(ctx, inboxIds)
+	if err != nil {
+		return nil, ListCursor{}, false, err
+	}
+	articleMap := make(map[int64]*Article, len(inboxArticles)+len(pulled))
+	for _, article := range inboxArticles {
+		articleMap[article.Id] = article
+	}
+	// 3. 合并两个来源，每篇文章只有一个位置
+	candidates := make([]ListCursor, 0, len(inbox)+len(pulled))
+	for _, item := range inbox {
+		if popular[item.AuthorId] {
+			continue
+		}
+		candidates = append(candidates, ListCursor{Value: item.PublishedTime, Id: item.ArticleId})
+	}
+	for _, article := range pulled {
+		articleMap[article.Id] = article
+		candidates = append(candidates, ListCursor{Value: article.UpdatedTime, Id: article.Id})
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[j].olderThan(candidates[i])
+	})
+	// 某个来源还有更多文章时，比它最后一条更早的文章可能还没有取到，本页只能合并到该位置为止
+	sourceMore := false
+	var boundary ListCursor
+	if int64(len(inbox)) > limit {
+		sourceMore = true
+		last := inbox[len(inbox)-1]
+		boundary = ListCursor{Value: last.PublishedTime, Id: last.ArticleId}
+	}
+	if int64(len(pulled)) > limit {
+		last := pulled[len(pulled)-1]
+		if pos := (ListCursor{Value: last.UpdatedTime, Id: last.Id}); !sourceMore || boundary.olderThan(pos) {
+			boundary = pos
+		}
+		sourceMore = true
+	}
+	articles := make([]*Article, 0, limit)
+	seen := make(map[int64]bool, len(candidates))
+	next := ListCursor{}
+	hasMore := sourceMore
+	for _, pos := range candidates {
+		if int64(len(articles)) >= limit || (sourceMore && pos.olderThan(boundary)) {
+			hasMore = true
+			break
+		}
+		next = pos
+		if seen[pos.Id] {
+			continue
+		}
+		seen[pos.Id] = true
+		if article, ok := articleMap[pos.Id]; ok {
+			articles = append(articles, article)
+		}
+	}
+	if !hasMore {
+		next = ListCursor{}
+	}
+	service.fillTags(ctx, articles)
+	service.fillInteractives(ctx, articles)
+	return articles, next, hasMore, nil
+}
@@ -0,0 +1,162 @@
+package service_test
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"ibook/internal/service"
+	usvcmocks "ibook/internal/service/mocks"
+	"ibook/pkg/utils/logger"
+	"testing"
+)
+
+func TestListFeed(t *testing.T) {
+	const userId int64 = 100
+	testCases := []struct {
+		name  string
+		limit int64
+		// 收件箱与拉取的文章都按位置倒序给出，条数已包含多取的一条
+		inbox      []*service.FeedItem
+		popularIds []int64
+		pulled     []*service.Article
+		followed   []int64
+		// published 读者库中处于发表状态的收件箱文章
+		published []int64
+
+		wantIds     []int64
+		wantNext    service.ListCursor
+		wantHasMore bool
+	}{
+		{
+			name:  "合并收件箱与拉取的文章",
+			limit: 10,
+			inbox: []*service.FeedItem{
+				{ArticleId: 3, AuthorId: 1, PublishedTime: 300},
+				{ArticleId: 1, AuthorId: 1, PublishedTime: 100},
+			},
+			popularIds:  []int64{2},
+			pulled:      []*service.Article{{Id: 2, Author: service.Author{Id: 2}, UpdatedTime: 200}},
+			followed:    []int64{1},
+			published:   []int64{1, 3},
+			wantIds:     []int64{3, 2, 1},
+			wantHasMore: false,
+		},
+		{
+			name:  "粉丝较多的作者只以拉取的位置为准",
+			limit: 1,
+			inbox: []*service.FeedItem{
+				{ArticleId: 5, AuthorId: 2, PublishedTime: 500},
+				{ArticleId: 1, AuthorId: 1, PublishedTime: 100},
+			},
+			popularIds:  []int64{2},
+			pulled:      []*service.Article{{Id: 5, Author: service.Author{Id: 2}, UpdatedTime: 150}},
+			followed:    []int64{1, 2},
+			published:   []int64{1, 5},
+			wantIds:     []int64{5},
+			wantNext:    service.ListCursor{Value: 150, Id: 5},
+			wantHasMore: true,
+		},
+		{
+			name:  "取满一页时返回最后一篇的位置",
+			limit: 2,
+			inbox: []*service.FeedItem{
+				{ArticleId: 4, AuthorId: 1, PublishedTime: 400},
+				{ArticleId: 3, AuthorId: 1, PublishedTime: 300},
+			},
+			popularIds: []int64{2},
+			pulled: []*service.Article{
+				{Id: 7, Author: service.Author{Id: 2}, UpdatedTime: 350},
+				{Id: 6, Author: service.Author{Id: 2}, UpdatedTime: 250},
+			},
+			followed:    []int64{1},
+			published:   []int64{3, 4},
+			wantIds:     []int64{4, 7},
+			wantNext:    service.ListCursor{Value: 350, Id: 7},
+			wantHasMore: true,
+		},
+		{
+			name:  "收件箱还有更多时只合并到其最后一条",
+			limit: 2,
+			inbox: []*service.FeedItem{
+				{ArticleId: 1, AuthorId: 1, PublishedTime: 300},
+				{ArticleId: 2, AuthorId: 3, PublishedTime: 200},
+				{ArticleId: 3, AuthorId: 3, PublishedTime: 150},
+			},
+			popularIds:  []int64{2},
+			pulled:      []*service.Article{{Id: 9, Author: service.Author{Id: 2}, UpdatedTime: 120}},
+			followed:    []int64{1},
+			published:   []int64{1, 2, 3},
+			wantIds:     []int64{1},
+			wantNext:    service.ListCursor{Value: 150, Id: 3},
+			wantHasMore: true,
+		},
+		{
+			name:  "跳过已撤回的文章",
+			limit: 10,
+			inbox: []*service.FeedItem{
+				{ArticleId: 2, AuthorId: 1, PublishedTime: 200},
+				{ArticleId: 1, AuthorId: 1, PublishedTime: 100},
+			},
+			followed:    []int64{1},
+			published:   []int64{1},
+			wantIds:     []int64{1},
+			wantHasMore: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			fdr := usvcmocks.NewMockFeedRepo(ctrl)
+			fr := usvcmocks.NewMockFollowRepo(ctrl)
+			rr := usvcmocks.NewMockArticleReaderRepo(ctrl)
+			tr := usvcmocks.NewMockArticleTagRepo(ctrl)
+			aic := usvcmocks.NewMockArticleInteractiveCache(ctrl)
+
+			fdr.EXPECT().ListInbox(gomock.Any(), userId, service.ListCursor{}, tc.limit+1).Return(tc.inbox, nil)
+			fr.EXPECT().ListPopularFollowees(gomock.Any(), userId, gomock.Any()).Return(tc.popularIds, nil)
+			if len(tc.popularIds) > 0 {
+				rr.EXPECT().ListByFilter(gomock.Any(), service.ArticleListFilter{AuthorIds: tc.popularIds}, service.ListCursor{}, tc.limit+1).
+					Return(tc.pulled, nil)
+			}
+			fr.EXPECT().FilterFollowed(gomock.Any(), userId, gomock.Any()).Return(tc.followed, nil)
+			published := make(map[int64]bool, len(tc.published))
+			for _, id := range tc.published {
+				published[id] = true
+			}
+			rr.EXPECT().GetPubArticlesByIds(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(ctx *gin.Context, ids []int64) ([]*service.Article, error) {
+					var res []*service.Article
+					for _, id := range ids {
+						if published[id] {
+							res = append(res, &service.Article{Id: id})
+						}
+					}
+					return res, nil
+				})
+			tr.EXPECT().ListTagsByArticleIds(gomock.Any(), gomock.Any()).Return(map[int64][]string{}, nil).AnyTimes()
+			aic.EXPECT().GetInteractives(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(ctx *gin.Context, ids []int64) (map[int64]*service.Interactive, error) {
+					res := make(map[int64]*service.Interactive, len(ids))
+					for _, id := range ids {
+						res[id] = &service.Interactive{ArticleId: id}
+					}
+					return res, nil
+				}).AnyTimes()
+
+			svc := service.NewArticleService(nil, rr, nil, nil, aic, nil, nil, nil, tr, nil, nil, nil, nil,
+				nil, nil, fr, fdr, logger.NewZapLogger())
+			articles, next, hasMore, err := svc.ListFeed(&gin.Context{}, userId, service.ListCursor{}, tc.limit)
+			require.NoError(t, err)
+			ids := make([]int64, 0, len(articles))
+			for _, article := range articles {
+				ids = append(ids, article.Id)
+			}
+			assert.Equal(t, tc.wantIds, ids)
+			assert.Equal(t, tc.wantNext, next)
+			assert.Equal(t, tc.wantHasMore, hasMore)
+		})
+	}
+}
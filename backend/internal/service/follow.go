@@ -0,0 +1,133 @@
+package service
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	FollowSelfErr                = errors.New("不能关注自己")
+	FollowStatisticNotInCacheErr = errors.New("关注计数未被缓存")
+)
+
+// FollowRelation 有效的关注关系，NickName 与 AvatarUrl 为列表中展示的那一方用户的资料
+type FollowRelation struct {
+	Id           int64
+	FollowerId   int64
+	FolloweeId   int64
+	NickName     string
+	AvatarUrl    string
+	FollowedTime int64
+}
+
+// FollowStatistic 用户的粉丝数与关注数，Followed 为访问者是否关注了该用户
+type FollowStatistic struct {
+	UserId       int64
+	FollowerCnt  int64
+	FollowingCnt int64
+	Followed     bool
+}
+
+type FollowRepo interface {
+	// Follow 返回关注关系是否发生了变化，已关注时不重复计数，关注数与粉丝数在同一事务中更新
+	Follow(ctx *gin.Context, followerId int64, followeeId int64) (bool, error)
+	// Unfollow 返回关注关系是否发生了变化，未关注时计数不变
+	Unfollow(ctx *gin.Context, followerId int64, followeeId int64) (bool, error)
+	Followed(ctx *gin.Context, followerId int64, followeeId int64) (bool, error)
+	// GetStatistic 没有记录时计数为 0
+	GetStatistic(ctx *gin.Context, userId int64) (*FollowStatistic, error)
+	// ListFollowers 按关注时间倒序获取关注了 userId 的用户
+	ListFollowers(ctx *gin.Context, userId int64, cursor ListCursor, limit int64) ([]*FollowRelation, error)
+	// ListFollowees 按关注时间倒序获取 userId 关注的用户
+	ListFollowees(ctx *gin.Context, userId int64, cursor ListCursor, limit int64) ([]*FollowRelation, error)
+	// ListFollowerIds 按用户 id 升序获取 userId 的粉丝 id，afterId 为上一批的最后一个粉丝 id
+	ListFollowerIds(ctx *gin.Context, userId int64, afterId int64, limit int64) ([]int64, error)
+	// ListPopularFollowees 获取 userId 关注的用户中粉丝数不少于 minFollowers 的用户 id
+	ListPopularFollowees(ctx *gin.Context, userId int64, minFollowers int64) ([]int64, error)
+	// FilterFollowed 返回 followeeIds 中 followerId 仍在关注的用户 id
+	FilterFollowed(ctx *gin.Context, followerId int64, followeeIds []int64) ([]int64, error)
+}
+
+type FollowCache interface {
+	// GetStatistic 未命中时返回 FollowStatisticNotInCacheErr
+	GetStatistic(ctx *gin.Context, userId int64) (*FollowStatistic, error)
+	SetStatistic(ctx *gin.Context, stat *FollowStatistic) error
+	DelStatistic(ctx *gin.Context, userIds ...int64) error
+}
+
+func (s *userService) Follow(ctx *gin.Context, followerId int64, followeeId int64) error {
+	if followerId == followeeId {
+		return FollowSelfErr
+	}
+	if _, err := s.ur.FindUserById(followeeId); err != nil {
+		return err
+	}
+	changed, err := s.fr.Follow(ctx, followerId, followeeId)
+	if err != nil || !changed {
+		return err
+	}
+	// 计数已在数据库中更新，删除缓存失败时旧计数最多保留到缓存过期
+	_ = s.fc.DelStatistic(ctx, followerId, followeeId)
+	return nil
+}
+
+func (s *userService) Unfollow(ctx *gin.Context, followerId int64, followeeId int64) error {
+	changed, err := s.fr.Unfollow(ctx, followerId, followeeId)
+	if err != nil || !changed {
+		return err
+	}
+	_ = s.fc.DelStatistic(ctx, followerId, followeeId)
+	return nil
+}
+
+// ListFollowers 分页获取粉丝列表，游标为关注时间与关注记录 id
+func (s *userService) ListFollowers(ctx *gin.Context, userId int64, cursor ListCursor, limit int64) ([]*FollowRelation, ListCursor, bool, error) {
+	// 多取一条用于判断是否还有下一页
+	relations, err := s.fr.ListFollowers(ctx, userId, cursor, limit+1)
+	if err != nil {
+		return nil, ListCursor{}, false, err
+	}
+	relations, next, hasMore := pageFollowRelations(relations, limit)
+	return relations, next, hasMore, nil
+}
+
+// ListFollowees 分页获取关注列表，游标为关注时间与关注记录 id
+func (s *userService) ListFollowees(ctx *gin.Context, userId int64, cursor ListCursor, limit int64) ([]*FollowRelation, ListCursor, bool, error) {
+	relations, err := s.fr.ListFollowees(ctx, userId, cursor, limit+1)
+	if err != nil {
+		return nil, ListCursor{}, false, err
+	}
+	relations, next, hasMore := pageFollowRelations(relations, limit)
+	return relations, next, hasMore, nil
+}
+
+// getFollowStatistic 先查缓存，未命中时从数据库加载并写回缓存，viewerId 为 0 时不查询关注状态
+func (s *userService) getFollowStatistic(ctx *gin.Context, userId int64, viewerId int64) (*FollowStatistic, error) {
+	stat, err := s.fc.GetStatistic(ctx, userId)
+	if err != nil {
+		stat, err = s.fr.GetStatistic(ctx, userId)
+		if err != nil {
+			return nil, err
+		}
+		_ = s.fc.SetStatistic(ctx, stat)
+	}
+	if viewerId > 0 && viewerId != userId {
+		if stat.Followed, err = s.fr.Followed(ctx, viewerId, userId); err != nil {
+			return nil, err
+		}
+	}
+	return stat, nil
+}
+
+func pageFollowRelations(relations []*FollowRelation, limit int64) ([]*FollowRelation, ListCursor, bool) {
+	hasMore := int64(len(relations)) > limit
+	if hasMore {
+		relations = relations[:limit]
+	}
+	next := ListCursor{}
+	if hasMore {
+		last := relations[len(relations)-1]
+		next = ListCursor{Value: last.FollowedTime, Id: last.Id}
+	}
+	return relations, next, hasMore
+}
@@ -0,0 +1,23 @@
+package email
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	EmailSendTooManyErr = errors.New("邮件发送太频繁")
+	EmailUnKnownErr     = errors.New("邮件服务未知错误")
+)
+
+// TplResetPassword 重置密码验证码邮件，参数为 code 与 minutes（验证码有效分钟数）
+const TplResetPassword = "reset_password"
+
+type EmailRepo interface {
+	SendMessage(ctx *gin.Context, tplId string, addresses []string, args []MsgArgs) error
+}
+
+type MsgArgs struct {
+	Name  string
+	Value string
+}
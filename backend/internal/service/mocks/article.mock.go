@@ -0,0 +1,1220 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/service/article.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./internal/service/article.go -package=usvcmocks -destination=./internal/service/mocks/article.mock.go
+//
+
+// Package usvcmocks is a generated GoMock package.
+package usvcmocks
+
+import (
+	service "ibook/internal/service"
+	reflect "reflect"
+	time "time"
+
+	gin "github.com/gin-gonic/gin"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockArticleAuthorRepo is a mock of ArticleAuthorRepo interface.
+type MockArticleAuthorRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockArticleAuthorRepoMockRecorder
+}
+
+// MockArticleAuthorRepoMockRecorder is the mock recorder for MockArticleAuthorRepo.
+type MockArticleAuthorRepoMockRecorder struct {
+	mock *MockArticleAuthorRepo
+}
+
+// NewMockArticleAuthorRepo creates a new mock instance.
+func NewMockArticleAuthorRepo(ctrl *gomock.Controller) *MockArticleAuthorRepo {
+	mock := &MockArticleAuthorRepo{ctrl: ctrl}
+	mock.recorder = &MockArticleAuthorRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockArticleAuthorRepo) EXPECT() *MockArticleAuthorRepoMockRecorder {
+	return m.recorder
+}
+
+// CreateArticle mocks base method.
+func (m *MockArticleAuthorRepo) CreateArticle(ctx *gin.Context, article *service.ArticleAuthor, kind service.RevisionKind) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateArticle", ctx, article, kind)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateArticle indicates an expected call of CreateArticle.
+func (mr *MockArticleAuthorRepoMockRecorder) CreateArticle(ctx, article, kind any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateArticle", reflect.TypeOf((*MockArticleAuthorRepo)(nil).CreateArticle), ctx, article, kind)
+}
+
+// GetArticleById mocks base method.
+func (m *MockArticleAuthorRepo) GetArticleById(ctx *gin.Context, id, userId int64) (*service.Article, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetArticleById", ctx, id, userId)
+	ret0, _ := ret[0].(*service.Article)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetArticleById indicates an expected call of GetArticleById.
+func (mr *MockArticleAuthorRepoMockRecorder) GetArticleById(ctx, id, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetArticleById", reflect.TypeOf((*MockArticleAuthorRepo)(nil).GetArticleById), ctx, id, userId)
+}
+
+// UpdateArticle mocks base method.
+func (m *MockArticleAuthorRepo) UpdateArticle(ctx *gin.Context, article *service.ArticleAuthor, kind service.RevisionKind) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateArticle", ctx, article, kind)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateArticle indicates an expected call of UpdateArticle.
+func (mr *MockArticleAuthorRepoMockRecorder) UpdateArticle(ctx, article, kind any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateArticle", reflect.TypeOf((*MockArticleAuthorRepo)(nil).UpdateArticle), ctx, article, kind)
+}
+
+// UpdateStatusById mocks base method.
+func (m *MockArticleAuthorRepo) UpdateStatusById(ctx *gin.Context, articleId, authorId int64, status uint8) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateStatusById", ctx, articleId, authorId, status)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateStatusById indicates an expected call of UpdateStatusById.
+func (mr *MockArticleAuthorRepoMockRecorder) UpdateStatusById(ctx, articleId, authorId, status any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatusById", reflect.TypeOf((*MockArticleAuthorRepo)(nil).UpdateStatusById), ctx, articleId, authorId, status)
+}
+
+// MockArticleReaderRepo is a mock of ArticleReaderRepo interface.
+type MockArticleReaderRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockArticleReaderRepoMockRecorder
+}
+
+// MockArticleReaderRepoMockRecorder is the mock recorder for MockArticleReaderRepo.
+type MockArticleReaderRepoMockRecorder struct {
+	mock *MockArticleReaderRepo
+}
+
+// NewMockArticleReaderRepo creates a new mock instance.
+func NewMockArticleReaderRepo(ctrl *gomock.Controller) *MockArticleReaderRepo {
+	mock := &MockArticleReaderRepo{ctrl: ctrl}
+	mock.recorder = &MockArticleReaderRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockArticleReaderRepo) EXPECT() *MockArticleReaderRepoMockRecorder {
+	return m.recorder
+}
+
+// GetPubArticleById mocks base method.
+func (m *MockArticleReaderRepo) GetPubArticleById(ctx *gin.Context, articleId int64) (*service.Article, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPubArticleById", ctx, articleId)
+	ret0, _ := ret[0].(*service.Article)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPubArticleById indicates an expected call of GetPubArticleById.
+func (mr *MockArticleReaderRepoMockRecorder) GetPubArticleById(ctx, articleId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPubArticleById", reflect.TypeOf((*MockArticleReaderRepo)(nil).GetPubArticleById), ctx, articleId)
+}
+
+// GetPubArticlesByIds mocks base method.
+func (m *MockArticleReaderRepo) GetPubArticlesByIds(ctx *gin.Context, ids []int64) ([]*service.Article, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPubArticlesByIds", ctx, ids)
+	ret0, _ := ret[0].([]*service.Article)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPubArticlesByIds indicates an expected call of GetPubArticlesByIds.
+func (mr *MockArticleReaderRepoMockRecorder) GetPubArticlesByIds(ctx, ids any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPubArticlesByIds", reflect.TypeOf((*MockArticleReaderRepo)(nil).GetPubArticlesByIds), ctx, ids)
+}
+
+// ListAll mocks base method.
+func (m *MockArticleReaderRepo) ListAll(ctx *gin.Context, cursor service.ListCursor, limit int64) ([]*service.Article, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAll", ctx, cursor, limit)
+	ret0, _ := ret[0].([]*service.Article)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAll indicates an expected call of ListAll.
+func (mr *MockArticleReaderRepoMockRecorder) ListAll(ctx, cursor, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAll", reflect.TypeOf((*MockArticleReaderRepo)(nil).ListAll), ctx, cursor, limit)
+}
+
+// ListByFilter mocks base method.
+func (m *MockArticleReaderRepo) ListByFilter(ctx *gin.Context, filter service.ArticleListFilter, cursor service.ListCursor, limit int64) ([]*service.Article, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByFilter", ctx, filter, cursor, limit)
+	ret0, _ := ret[0].([]*service.Article)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByFilter indicates an expected call of ListByFilter.
+func (mr *MockArticleReaderRepoMockRecorder) ListByFilter(ctx, filter, cursor, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByFilter", reflect.TypeOf((*MockArticleReaderRepo)(nil).ListByFilter), ctx, filter, cursor, limit)
+}
+
+// ListById mocks base method.
+func (m *MockArticleReaderRepo) ListById(ctx *gin.Context, userId int64, cursor service.ListCursor, limit int64) ([]*service.Article, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListById", ctx, userId, cursor, limit)
+	ret0, _ := ret[0].([]*service.Article)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListById indicates an expected call of ListById.
+func (mr *MockArticleReaderRepoMockRecorder) ListById(ctx, userId, cursor, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListById", reflect.TypeOf((*MockArticleReaderRepo)(nil).ListById), ctx, userId, cursor, limit)
+}
+
+// MockArticleSyncRepo is a mock of ArticleSyncRepo interface.
+type MockArticleSyncRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockArticleSyncRepoMockRecorder
+}
+
+// MockArticleSyncRepoMockRecorder is the mock recorder for MockArticleSyncRepo.
+type MockArticleSyncRepoMockRecorder struct {
+	mock *MockArticleSyncRepo
+}
+
+// NewMockArticleSyncRepo creates a new mock instance.
+func NewMockArticleSyncRepo(ctrl *gomock.Controller) *MockArticleSyncRepo {
+	mock := &MockArticleSyncRepo{ctrl: ctrl}
+	mock.recorder = &MockArticleSyncRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockArticleSyncRepo) EXPECT() *MockArticleSyncRepoMockRecorder {
+	return m.recorder
+}
+
+// Sync mocks base method.
+func (m *MockArticleSyncRepo) Sync(ctx *gin.Context, articleA *service.ArticleAuthor, articleR *service.ArticleReader) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Sync", ctx, articleA, articleR)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Sync indicates an expected call of Sync.
+func (mr *MockArticleSyncRepoMockRecorder) Sync(ctx, articleA, articleR any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sync", reflect.TypeOf((*MockArticleSyncRepo)(nil).Sync), ctx, articleA, articleR)
+}
+
+// SyncUpdateStatus mocks base method.
+func (m *MockArticleSyncRepo) SyncUpdateStatus(ctx *gin.Context, articleId, authorId int64, status uint8) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SyncUpdateStatus", ctx, articleId, authorId, status)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SyncUpdateStatus indicates an expected call of SyncUpdateStatus.
+func (mr *MockArticleSyncRepoMockRecorder) SyncUpdateStatus(ctx, articleId, authorId, status any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncUpdateStatus", reflect.TypeOf((*MockArticleSyncRepo)(nil).SyncUpdateStatus), ctx, articleId, authorId, status)
+}
+
+// MockArticleInteractiveRepo is a mock of ArticleInteractiveRepo interface.
+type MockArticleInteractiveRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockArticleInteractiveRepoMockRecorder
+}
+
+// MockArticleInteractiveRepoMockRecorder is the mock recorder for MockArticleInteractiveRepo.
+type MockArticleInteractiveRepoMockRecorder struct {
+	mock *MockArticleInteractiveRepo
+}
+
+// NewMockArticleInteractiveRepo creates a new mock instance.
+func NewMockArticleInteractiveRepo(ctrl *gomock.Controller) *MockArticleInteractiveRepo {
+	mock := &MockArticleInteractiveRepo{ctrl: ctrl}
+	mock.recorder = &MockArticleInteractiveRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockArticleInteractiveRepo) EXPECT() *MockArticleInteractiveRepoMockRecorder {
+	return m.recorder
+}
+
+// GetInteractive mocks base method.
+func (m *MockArticleInteractiveRepo) GetInteractive(ctx *gin.Context, articleId int64) (*service.Interactive, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInteractive", ctx, articleId)
+	ret0, _ := ret[0].(*service.Interactive)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInteractive indicates an expected call of GetInteractive.
+func (mr *MockArticleInteractiveRepoMockRecorder) GetInteractive(ctx, articleId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInteractive", reflect.TypeOf((*MockArticleInteractiveRepo)(nil).GetInteractive), ctx, articleId)
+}
+
+// GetInteractives mocks base method.
+func (m *MockArticleInteractiveRepo) GetInteractives(ctx *gin.Context, articleIds []int64) ([]*service.Interactive, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInteractives", ctx, articleIds)
+	ret0, _ := ret[0].([]*service.Interactive)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInteractives indicates an expected call of GetInteractives.
+func (mr *MockArticleInteractiveRepoMockRecorder) GetInteractives(ctx, articleIds any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInteractives", reflect.TypeOf((*MockArticleInteractiveRepo)(nil).GetInteractives), ctx, articleIds)
+}
+
+// IncrReadCounts mocks base method.
+func (m *MockArticleInteractiveRepo) IncrReadCounts(ctx *gin.Context, batchId string, counts map[int64]int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrReadCounts", ctx, batchId, counts)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IncrReadCounts indicates an expected call of IncrReadCounts.
+func (mr *MockArticleInteractiveRepoMockRecorder) IncrReadCounts(ctx, batchId, counts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrReadCounts", reflect.TypeOf((*MockArticleInteractiveRepo)(nil).IncrReadCounts), ctx, batchId, counts)
+}
+
+// MockArticleInteractiveCache is a mock of ArticleInteractiveCache interface.
+type MockArticleInteractiveCache struct {
+	ctrl     *gomock.Controller
+	recorder *MockArticleInteractiveCacheMockRecorder
+}
+
+// MockArticleInteractiveCacheMockRecorder is the mock recorder for MockArticleInteractiveCache.
+type MockArticleInteractiveCacheMockRecorder struct {
+	mock *MockArticleInteractiveCache
+}
+
+// NewMockArticleInteractiveCache creates a new mock instance.
+func NewMockArticleInteractiveCache(ctrl *gomock.Controller) *MockArticleInteractiveCache {
+	mock := &MockArticleInteractiveCache{ctrl: ctrl}
+	mock.recorder = &MockArticleInteractiveCacheMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockArticleInteractiveCache) EXPECT() *MockArticleInteractiveCacheMockRecorder {
+	return m.recorder
+}
+
+// AckBufferedReadCounts mocks base method.
+func (m *MockArticleInteractiveCache) AckBufferedReadCounts(ctx *gin.Context, articleIds []int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AckBufferedReadCounts", ctx, articleIds)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AckBufferedReadCounts indicates an expected call of AckBufferedReadCounts.
+func (mr *MockArticleInteractiveCacheMockRecorder) AckBufferedReadCounts(ctx, articleIds any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AckBufferedReadCounts", reflect.TypeOf((*MockArticleInteractiveCache)(nil).AckBufferedReadCounts), ctx, articleIds)
+}
+
+// BufferReadCount mocks base method.
+func (m *MockArticleInteractiveCache) BufferReadCount(ctx *gin.Context, articleId int64, viewer string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BufferReadCount", ctx, articleId, viewer)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BufferReadCount indicates an expected call of BufferReadCount.
+func (mr *MockArticleInteractiveCacheMockRecorder) BufferReadCount(ctx, articleId, viewer any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BufferReadCount", reflect.TypeOf((*MockArticleInteractiveCache)(nil).BufferReadCount), ctx, articleId, viewer)
+}
+
+// DecrCollectCountInCache mocks base method.
+func (m *MockArticleInteractiveCache) DecrCollectCountInCache(ctx *gin.Context, articleId int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DecrCollectCountInCache", ctx, articleId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DecrCollectCountInCache indicates an expected call of DecrCollectCountInCache.
+func (mr *MockArticleInteractiveCacheMockRecorder) DecrCollectCountInCache(ctx, articleId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecrCollectCountInCache", reflect.TypeOf((*MockArticleInteractiveCache)(nil).DecrCollectCountInCache), ctx, articleId)
+}
+
+// GetInteractive mocks base method.
+func (m *MockArticleInteractiveCache) GetInteractive(ctx *gin.Context, articleId int64) (*service.Interactive, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInteractive", ctx, articleId)
+	ret0, _ := ret[0].(*service.Interactive)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInteractive indicates an expected call of GetInteractive.
+func (mr *MockArticleInteractiveCacheMockRecorder) GetInteractive(ctx, articleId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInteractive", reflect.TypeOf((*MockArticleInteractiveCache)(nil).GetInteractive), ctx, articleId)
+}
+
+// GetInteractives mocks base method.
+func (m *MockArticleInteractiveCache) GetInteractives(ctx *gin.Context, articleIds []int64) (map[int64]*service.Interactive, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInteractives", ctx, articleIds)
+	ret0, _ := ret[0].(map[int64]*service.Interactive)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInteractives indicates an expected call of GetInteractives.
+func (mr *MockArticleInteractiveCacheMockRecorder) GetInteractives(ctx, articleIds any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInteractives", reflect.TypeOf((*MockArticleInteractiveCache)(nil).GetInteractives), ctx, articleIds)
+}
+
+// GetUniqueReaderCounts mocks base method.
+func (m *MockArticleInteractiveCache) GetUniqueReaderCounts(ctx *gin.Context, articleIds []int64) (map[int64]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUniqueReaderCounts", ctx, articleIds)
+	ret0, _ := ret[0].(map[int64]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUniqueReaderCounts indicates an expected call of GetUniqueReaderCounts.
+func (mr *MockArticleInteractiveCacheMockRecorder) GetUniqueReaderCounts(ctx, articleIds any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUniqueReaderCounts", reflect.TypeOf((*MockArticleInteractiveCache)(nil).GetUniqueReaderCounts), ctx, articleIds)
+}
+
+// IncrCollectCountInCache mocks base method.
+func (m *MockArticleInteractiveCache) IncrCollectCountInCache(ctx *gin.Context, articleId int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrCollectCountInCache", ctx, articleId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IncrCollectCountInCache indicates an expected call of IncrCollectCountInCache.
+func (mr *MockArticleInteractiveCacheMockRecorder) IncrCollectCountInCache(ctx, articleId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrCollectCountInCache", reflect.TypeOf((*MockArticleInteractiveCache)(nil).IncrCollectCountInCache), ctx, articleId)
+}
+
+// IncrCommentCountInCache mocks base method.
+func (m *MockArticleInteractiveCache) IncrCommentCountInCache(ctx *gin.Context, articleId, delta int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrCommentCountInCache", ctx, articleId, delta)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IncrCommentCountInCache indicates an expected call of IncrCommentCountInCache.
+func (mr *MockArticleInteractiveCacheMockRecorder) IncrCommentCountInCache(ctx, articleId, delta any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrCommentCountInCache", reflect.TypeOf((*MockArticleInteractiveCache)(nil).IncrCommentCountInCache), ctx, articleId, delta)
+}
+
+// IncrReactionCountInCache mocks base method.
+func (m *MockArticleInteractiveCache) IncrReactionCountInCache(ctx *gin.Context, articleId, likeDelta, dislikeDelta int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrReactionCountInCache", ctx, articleId, likeDelta, dislikeDelta)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IncrReactionCountInCache indicates an expected call of IncrReactionCountInCache.
+func (mr *MockArticleInteractiveCacheMockRecorder) IncrReactionCountInCache(ctx, articleId, likeDelta, dislikeDelta any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrReactionCountInCache", reflect.TypeOf((*MockArticleInteractiveCache)(nil).IncrReactionCountInCache), ctx, articleId, likeDelta, dislikeDelta)
+}
+
+// SetInteractives mocks base method.
+func (m *MockArticleInteractiveCache) SetInteractives(ctx *gin.Context, inters []*service.Interactive) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetInteractives", ctx, inters)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetInteractives indicates an expected call of SetInteractives.
+func (mr *MockArticleInteractiveCacheMockRecorder) SetInteractives(ctx, inters any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetInteractives", reflect.TypeOf((*MockArticleInteractiveCache)(nil).SetInteractives), ctx, inters)
+}
+
+// TakeBufferedReadCounts mocks base method.
+func (m *MockArticleInteractiveCache) TakeBufferedReadCounts(ctx *gin.Context, limit int64) (string, map[int64]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TakeBufferedReadCounts", ctx, limit)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(map[int64]int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// TakeBufferedReadCounts indicates an expected call of TakeBufferedReadCounts.
+func (mr *MockArticleInteractiveCacheMockRecorder) TakeBufferedReadCounts(ctx, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TakeBufferedReadCounts", reflect.TypeOf((*MockArticleInteractiveCache)(nil).TakeBufferedReadCounts), ctx, limit)
+}
+
+// TryLockReadFlush mocks base method.
+func (m *MockArticleInteractiveCache) TryLockReadFlush(ctx *gin.Context, ttl time.Duration) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TryLockReadFlush", ctx, ttl)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TryLockReadFlush indicates an expected call of TryLockReadFlush.
+func (mr *MockArticleInteractiveCacheMockRecorder) TryLockReadFlush(ctx, ttl any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TryLockReadFlush", reflect.TypeOf((*MockArticleInteractiveCache)(nil).TryLockReadFlush), ctx, ttl)
+}
+
+// UnlockReadFlush mocks base method.
+func (m *MockArticleInteractiveCache) UnlockReadFlush(ctx *gin.Context, token string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnlockReadFlush", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnlockReadFlush indicates an expected call of UnlockReadFlush.
+func (mr *MockArticleInteractiveCacheMockRecorder) UnlockReadFlush(ctx, token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnlockReadFlush", reflect.TypeOf((*MockArticleInteractiveCache)(nil).UnlockReadFlush), ctx, token)
+}
+
+// MockArticleCollectRepo is a mock of ArticleCollectRepo interface.
+type MockArticleCollectRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockArticleCollectRepoMockRecorder
+}
+
+// MockArticleCollectRepoMockRecorder is the mock recorder for MockArticleCollectRepo.
+type MockArticleCollectRepoMockRecorder struct {
+	mock *MockArticleCollectRepo
+}
+
+// NewMockArticleCollectRepo creates a new mock instance.
+func NewMockArticleCollectRepo(ctrl *gomock.Controller) *MockArticleCollectRepo {
+	mock := &MockArticleCollectRepo{ctrl: ctrl}
+	mock.recorder = &MockArticleCollectRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockArticleCollectRepo) EXPECT() *MockArticleCollectRepoMockRecorder {
+	return m.recorder
+}
+
+// CancelCollectInfo mocks base method.
+func (m *MockArticleCollectRepo) CancelCollectInfo(ctx *gin.Context, userId, articleId int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelCollectInfo", ctx, userId, articleId)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelCollectInfo indicates an expected call of CancelCollectInfo.
+func (mr *MockArticleCollectRepoMockRecorder) CancelCollectInfo(ctx, userId, articleId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelCollectInfo", reflect.TypeOf((*MockArticleCollectRepo)(nil).CancelCollectInfo), ctx, userId, articleId)
+}
+
+// Collected mocks base method.
+func (m *MockArticleCollectRepo) Collected(ctx *gin.Context, userId, articleId int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Collected", ctx, userId, articleId)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Collected indicates an expected call of Collected.
+func (mr *MockArticleCollectRepoMockRecorder) Collected(ctx, userId, articleId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Collected", reflect.TypeOf((*MockArticleCollectRepo)(nil).Collected), ctx, userId, articleId)
+}
+
+// CreateFolder mocks base method.
+func (m *MockArticleCollectRepo) CreateFolder(ctx *gin.Context, folder *service.CollectFolder) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateFolder", ctx, folder)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateFolder indicates an expected call of CreateFolder.
+func (mr *MockArticleCollectRepoMockRecorder) CreateFolder(ctx, folder any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFolder", reflect.TypeOf((*MockArticleCollectRepo)(nil).CreateFolder), ctx, folder)
+}
+
+// GetFolderById mocks base method.
+func (m *MockArticleCollectRepo) GetFolderById(ctx *gin.Context, folderId int64) (*service.CollectFolder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFolderById", ctx, folderId)
+	ret0, _ := ret[0].(*service.CollectFolder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFolderById indicates an expected call of GetFolderById.
+func (mr *MockArticleCollectRepoMockRecorder) GetFolderById(ctx, folderId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFolderById", reflect.TypeOf((*MockArticleCollectRepo)(nil).GetFolderById), ctx, folderId)
+}
+
+// ListFolderArticles mocks base method.
+func (m *MockArticleCollectRepo) ListFolderArticles(ctx *gin.Context, folderId, offset, limit int64) ([]*service.Article, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFolderArticles", ctx, folderId, offset, limit)
+	ret0, _ := ret[0].([]*service.Article)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFolderArticles indicates an expected call of ListFolderArticles.
+func (mr *MockArticleCollectRepoMockRecorder) ListFolderArticles(ctx, folderId, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFolderArticles", reflect.TypeOf((*MockArticleCollectRepo)(nil).ListFolderArticles), ctx, folderId, offset, limit)
+}
+
+// ListFolders mocks base method.
+func (m *MockArticleCollectRepo) ListFolders(ctx *gin.Context, userId int64, onlyPublic bool) ([]*service.CollectFolder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFolders", ctx, userId, onlyPublic)
+	ret0, _ := ret[0].([]*service.CollectFolder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFolders indicates an expected call of ListFolders.
+func (mr *MockArticleCollectRepoMockRecorder) ListFolders(ctx, userId, onlyPublic any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFolders", reflect.TypeOf((*MockArticleCollectRepo)(nil).ListFolders), ctx, userId, onlyPublic)
+}
+
+// UpsertCollectInfo mocks base method.
+func (m *MockArticleCollectRepo) UpsertCollectInfo(ctx *gin.Context, userId, articleId, folderId int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertCollectInfo", ctx, userId, articleId, folderId)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertCollectInfo indicates an expected call of UpsertCollectInfo.
+func (mr *MockArticleCollectRepoMockRecorder) UpsertCollectInfo(ctx, userId, articleId, folderId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertCollectInfo", reflect.TypeOf((*MockArticleCollectRepo)(nil).UpsertCollectInfo), ctx, userId, articleId, folderId)
+}
+
+// MockArticleService is a mock of ArticleService interface.
+type MockArticleService struct {
+	ctrl     *gomock.Controller
+	recorder *MockArticleServiceMockRecorder
+}
+
+// MockArticleServiceMockRecorder is the mock recorder for MockArticleService.
+type MockArticleServiceMockRecorder struct {
+	mock *MockArticleService
+}
+
+// NewMockArticleService creates a new mock instance.
+func NewMockArticleService(ctrl *gomock.Controller) *MockArticleService {
+	mock := &MockArticleService{ctrl: ctrl}
+	mock.recorder = &MockArticleServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockArticleService) EXPECT() *MockArticleServiceMockRecorder {
+	return m.recorder
+}
+
+// CancelCollectArticle mocks base method.
+func (m *MockArticleService) CancelCollectArticle(ctx *gin.Context, userId, articleId int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelCollectArticle", ctx, userId, articleId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelCollectArticle indicates an expected call of CancelCollectArticle.
+func (mr *MockArticleServiceMockRecorder) CancelCollectArticle(ctx, userId, articleId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelCollectArticle", reflect.TypeOf((*MockArticleService)(nil).CancelCollectArticle), ctx, userId, articleId)
+}
+
+// CancelSchedule mocks base method.
+func (m *MockArticleService) CancelSchedule(ctx *gin.Context, scheduleId, authorId int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelSchedule", ctx, scheduleId, authorId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelSchedule indicates an expected call of CancelSchedule.
+func (mr *MockArticleServiceMockRecorder) CancelSchedule(ctx, scheduleId, authorId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelSchedule", reflect.TypeOf((*MockArticleService)(nil).CancelSchedule), ctx, scheduleId, authorId)
+}
+
+// CollectArticle mocks base method.
+func (m *MockArticleService) CollectArticle(ctx *gin.Context, userId, articleId, folderId int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CollectArticle", ctx, userId, articleId, folderId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CollectArticle indicates an expected call of CollectArticle.
+func (mr *MockArticleServiceMockRecorder) CollectArticle(ctx, userId, articleId, folderId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CollectArticle", reflect.TypeOf((*MockArticleService)(nil).CollectArticle), ctx, userId, articleId, folderId)
+}
+
+// CreateCollectFolder mocks base method.
+func (m *MockArticleService) CreateCollectFolder(ctx *gin.Context, folder *service.CollectFolder) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCollectFolder", ctx, folder)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateCollectFolder indicates an expected call of CreateCollectFolder.
+func (mr *MockArticleServiceMockRecorder) CreateCollectFolder(ctx, folder any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCollectFolder", reflect.TypeOf((*MockArticleService)(nil).CreateCollectFolder), ctx, folder)
+}
+
+// DeleteArticle mocks base method.
+func (m *MockArticleService) DeleteArticle(ctx *gin.Context, articleId, authorId int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteArticle", ctx, articleId, authorId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteArticle indicates an expected call of DeleteArticle.
+func (mr *MockArticleServiceMockRecorder) DeleteArticle(ctx, articleId, authorId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteArticle", reflect.TypeOf((*MockArticleService)(nil).DeleteArticle), ctx, articleId, authorId)
+}
+
+// DiffRevisions mocks base method.
+func (m *MockArticleService) DiffRevisions(ctx *gin.Context, fromId, toId, authorId int64, mode service.DiffMode) (*service.RevisionDiff, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DiffRevisions", ctx, fromId, toId, authorId, mode)
+	ret0, _ := ret[0].(*service.RevisionDiff)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DiffRevisions indicates an expected call of DiffRevisions.
+func (mr *MockArticleServiceMockRecorder) DiffRevisions(ctx, fromId, toId, authorId, mode any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DiffRevisions", reflect.TypeOf((*MockArticleService)(nil).DiffRevisions), ctx, fromId, toId, authorId, mode)
+}
+
+// EditArticle mocks base method.
+func (m *MockArticleService) EditArticle(ctx *gin.Context, article *service.Article) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EditArticle", ctx, article)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EditArticle indicates an expected call of EditArticle.
+func (mr *MockArticleServiceMockRecorder) EditArticle(ctx, article any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EditArticle", reflect.TypeOf((*MockArticleService)(nil).EditArticle), ctx, article)
+}
+
+// ExecuteDueSchedules mocks base method.
+func (m *MockArticleService) ExecuteDueSchedules(ctx *gin.Context, limit int64) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExecuteDueSchedules", ctx, limit)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExecuteDueSchedules indicates an expected call of ExecuteDueSchedules.
+func (mr *MockArticleServiceMockRecorder) ExecuteDueSchedules(ctx, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteDueSchedules", reflect.TypeOf((*MockArticleService)(nil).ExecuteDueSchedules), ctx, limit)
+}
+
+// FlushReadCounts mocks base method.
+func (m *MockArticleService) FlushReadCounts(ctx *gin.Context, batchSize int64, lockTTL time.Duration) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FlushReadCounts", ctx, batchSize, lockTTL)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FlushReadCounts indicates an expected call of FlushReadCounts.
+func (mr *MockArticleServiceMockRecorder) FlushReadCounts(ctx, batchSize, lockTTL any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FlushReadCounts", reflect.TypeOf((*MockArticleService)(nil).FlushReadCounts), ctx, batchSize, lockTTL)
+}
+
+// GetArticleDetail mocks base method.
+func (m *MockArticleService) GetArticleDetail(ctx *gin.Context, articleId, userId int64) (*service.Article, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetArticleDetail", ctx, articleId, userId)
+	ret0, _ := ret[0].(*service.Article)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetArticleDetail indicates an expected call of GetArticleDetail.
+func (mr *MockArticleServiceMockRecorder) GetArticleDetail(ctx, articleId, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetArticleDetail", reflect.TypeOf((*MockArticleService)(nil).GetArticleDetail), ctx, articleId, userId)
+}
+
+// GetPubArticleDetail mocks base method.
+func (m *MockArticleService) GetPubArticleDetail(ctx *gin.Context, articleId, viewerId int64) (*service.Article, *service.Interactive, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPubArticleDetail", ctx, articleId, viewerId)
+	ret0, _ := ret[0].(*service.Article)
+	ret1, _ := ret[1].(*service.Interactive)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetPubArticleDetail indicates an expected call of GetPubArticleDetail.
+func (mr *MockArticleServiceMockRecorder) GetPubArticleDetail(ctx, articleId, viewerId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPubArticleDetail", reflect.TypeOf((*MockArticleService)(nil).GetPubArticleDetail), ctx, articleId, viewerId)
+}
+
+// GetRevision mocks base method.
+func (m *MockArticleService) GetRevision(ctx *gin.Context, revisionId, authorId int64) (*service.ArticleRevision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRevision", ctx, revisionId, authorId)
+	ret0, _ := ret[0].(*service.ArticleRevision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRevision indicates an expected call of GetRevision.
+func (mr *MockArticleServiceMockRecorder) GetRevision(ctx, revisionId, authorId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRevision", reflect.TypeOf((*MockArticleService)(nil).GetRevision), ctx, revisionId, authorId)
+}
+
+// IncrReadCount mocks base method.
+func (m *MockArticleService) IncrReadCount(ctx *gin.Context, articleId int64, viewer string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrReadCount", ctx, articleId, viewer)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IncrReadCount indicates an expected call of IncrReadCount.
+func (mr *MockArticleServiceMockRecorder) IncrReadCount(ctx, articleId, viewer any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrReadCount", reflect.TypeOf((*MockArticleService)(nil).IncrReadCount), ctx, articleId, viewer)
+}
+
+// ListArticleLikers mocks base method.
+func (m *MockArticleService) ListArticleLikers(ctx *gin.Context, articleId int64, cursor service.ListCursor, limit int64) ([]*service.LikeRecord, service.ListCursor, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListArticleLikers", ctx, articleId, cursor, limit)
+	ret0, _ := ret[0].([]*service.LikeRecord)
+	ret1, _ := ret[1].(service.ListCursor)
+	ret2, _ := ret[2].(bool)
+	ret3, _ := ret[3].(error)
+	return ret0, ret1, ret2, ret3
+}
+
+// ListArticleLikers indicates an expected call of ListArticleLikers.
+func (mr *MockArticleServiceMockRecorder) ListArticleLikers(ctx, articleId, cursor, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListArticleLikers", reflect.TypeOf((*MockArticleService)(nil).ListArticleLikers), ctx, articleId, cursor, limit)
+}
+
+// ListAuthorArticleStats mocks base method.
+func (m *MockArticleService) ListAuthorArticleStats(ctx *gin.Context, authorId int64, cursor service.ListCursor, limit int64) ([]*service.Article, service.ListCursor, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAuthorArticleStats", ctx, authorId, cursor, limit)
+	ret0, _ := ret[0].([]*service.Article)
+	ret1, _ := ret[1].(service.ListCursor)
+	ret2, _ := ret[2].(bool)
+	ret3, _ := ret[3].(error)
+	return ret0, ret1, ret2, ret3
+}
+
+// ListAuthorArticleStats indicates an expected call of ListAuthorArticleStats.
+func (mr *MockArticleServiceMockRecorder) ListAuthorArticleStats(ctx, authorId, cursor, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAuthorArticleStats", reflect.TypeOf((*MockArticleService)(nil).ListAuthorArticleStats), ctx, authorId, cursor, limit)
+}
+
+// ListCollectFolderArticles mocks base method.
+func (m *MockArticleService) ListCollectFolderArticles(ctx *gin.Context, folderId, viewerId, offset, limit int64) ([]*service.Article, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCollectFolderArticles", ctx, folderId, viewerId, offset, limit)
+	ret0, _ := ret[0].([]*service.Article)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCollectFolderArticles indicates an expected call of ListCollectFolderArticles.
+func (mr *MockArticleServiceMockRecorder) ListCollectFolderArticles(ctx, folderId, viewerId, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCollectFolderArticles", reflect.TypeOf((*MockArticleService)(nil).ListCollectFolderArticles), ctx, folderId, viewerId, offset, limit)
+}
+
+// ListCollectFolders mocks base method.
+func (m *MockArticleService) ListCollectFolders(ctx *gin.Context, ownerId, viewerId int64) ([]*service.CollectFolder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCollectFolders", ctx, ownerId, viewerId)
+	ret0, _ := ret[0].([]*service.CollectFolder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCollectFolders indicates an expected call of ListCollectFolders.
+func (mr *MockArticleServiceMockRecorder) ListCollectFolders(ctx, ownerId, viewerId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCollectFolders", reflect.TypeOf((*MockArticleService)(nil).ListCollectFolders), ctx, ownerId, viewerId)
+}
+
+// ListFeed mocks base method.
+func (m *MockArticleService) ListFeed(ctx *gin.Context, userId int64, cursor service.ListCursor, limit int64) ([]*service.Article, service.ListCursor, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFeed", ctx, userId, cursor, limit)
+	ret0, _ := ret[0].([]*service.Article)
+	ret1, _ := ret[1].(service.ListCursor)
+	ret2, _ := ret[2].(bool)
+	ret3, _ := ret[3].(error)
+	return ret0, ret1, ret2, ret3
+}
+
+// ListFeed indicates an expected call of ListFeed.
+func (mr *MockArticleServiceMockRecorder) ListFeed(ctx, userId, cursor, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFeed", reflect.TypeOf((*MockArticleService)(nil).ListFeed), ctx, userId, cursor, limit)
+}
+
+// ListHotArticles mocks base method.
+func (m *MockArticleService) ListHotArticles(ctx *gin.Context, offset, limit int64) ([]*service.Article, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListHotArticles", ctx, offset, limit)
+	ret0, _ := ret[0].([]*service.Article)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListHotArticles indicates an expected call of ListHotArticles.
+func (mr *MockArticleServiceMockRecorder) ListHotArticles(ctx, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListHotArticles", reflect.TypeOf((*MockArticleService)(nil).ListHotArticles), ctx, offset, limit)
+}
+
+// ListLikedArticles mocks base method.
+func (m *MockArticleService) ListLikedArticles(ctx *gin.Context, userId int64, cursor service.ListCursor, limit int64) ([]*service.LikedArticle, service.ListCursor, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListLikedArticles", ctx, userId, cursor, limit)
+	ret0, _ := ret[0].([]*service.LikedArticle)
+	ret1, _ := ret[1].(service.ListCursor)
+	ret2, _ := ret[2].(bool)
+	ret3, _ := ret[3].(error)
+	return ret0, ret1, ret2, ret3
+}
+
+// ListLikedArticles indicates an expected call of ListLikedArticles.
+func (mr *MockArticleServiceMockRecorder) ListLikedArticles(ctx, userId, cursor, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListLikedArticles", reflect.TypeOf((*MockArticleService)(nil).ListLikedArticles), ctx, userId, cursor, limit)
+}
+
+// ListPubArticles mocks base method.
+func (m *MockArticleService) ListPubArticles(ctx *gin.Context, filter service.ArticleListFilter, cursor service.ListCursor, limit int64) ([]*service.Article, service.ListCursor, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPubArticles", ctx, filter, cursor, limit)
+	ret0, _ := ret[0].([]*service.Article)
+	ret1, _ := ret[1].(service.ListCursor)
+	ret2, _ := ret[2].(bool)
+	ret3, _ := ret[3].(error)
+	return ret0, ret1, ret2, ret3
+}
+
+// ListPubArticles indicates an expected call of ListPubArticles.
+func (mr *MockArticleServiceMockRecorder) ListPubArticles(ctx, filter, cursor, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPubArticles", reflect.TypeOf((*MockArticleService)(nil).ListPubArticles), ctx, filter, cursor, limit)
+}
+
+// ListRecycleBin mocks base method.
+func (m *MockArticleService) ListRecycleBin(ctx *gin.Context, authorId, offset, limit int64) ([]*service.DeletedArticle, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRecycleBin", ctx, authorId, offset, limit)
+	ret0, _ := ret[0].([]*service.DeletedArticle)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRecycleBin indicates an expected call of ListRecycleBin.
+func (mr *MockArticleServiceMockRecorder) ListRecycleBin(ctx, authorId, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRecycleBin", reflect.TypeOf((*MockArticleService)(nil).ListRecycleBin), ctx, authorId, offset, limit)
+}
+
+// ListRevisions mocks base method.
+func (m *MockArticleService) ListRevisions(ctx *gin.Context, articleId, authorId, offset, limit int64) ([]*service.ArticleRevision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRevisions", ctx, articleId, authorId, offset, limit)
+	ret0, _ := ret[0].([]*service.ArticleRevision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRevisions indicates an expected call of ListRevisions.
+func (mr *MockArticleServiceMockRecorder) ListRevisions(ctx, articleId, authorId, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRevisions", reflect.TypeOf((*MockArticleService)(nil).ListRevisions), ctx, articleId, authorId, offset, limit)
+}
+
+// ListSchedules mocks base method.
+func (m *MockArticleService) ListSchedules(ctx *gin.Context, authorId, articleId int64) ([]*service.ArticleSchedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSchedules", ctx, authorId, articleId)
+	ret0, _ := ret[0].([]*service.ArticleSchedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSchedules indicates an expected call of ListSchedules.
+func (mr *MockArticleServiceMockRecorder) ListSchedules(ctx, authorId, articleId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSchedules", reflect.TypeOf((*MockArticleService)(nil).ListSchedules), ctx, authorId, articleId)
+}
+
+// ListTagCounts mocks base method.
+func (m *MockArticleService) ListTagCounts(ctx *gin.Context, limit int64) ([]*service.TagCount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTagCounts", ctx, limit)
+	ret0, _ := ret[0].([]*service.TagCount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTagCounts indicates an expected call of ListTagCounts.
+func (mr *MockArticleServiceMockRecorder) ListTagCounts(ctx, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTagCounts", reflect.TypeOf((*MockArticleService)(nil).ListTagCounts), ctx, limit)
+}
+
+// PublishArticle mocks base method.
+func (m *MockArticleService) PublishArticle(ctx *gin.Context, article *service.Article, schedule service.PublishSchedule) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublishArticle", ctx, article, schedule)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PublishArticle indicates an expected call of PublishArticle.
+func (mr *MockArticleServiceMockRecorder) PublishArticle(ctx, article, schedule any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishArticle", reflect.TypeOf((*MockArticleService)(nil).PublishArticle), ctx, article, schedule)
+}
+
+// PurgeExpiredArticles mocks base method.
+func (m *MockArticleService) PurgeExpiredArticles(ctx *gin.Context, limit int64) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeExpiredArticles", ctx, limit)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeExpiredArticles indicates an expected call of PurgeExpiredArticles.
+func (mr *MockArticleServiceMockRecorder) PurgeExpiredArticles(ctx, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeExpiredArticles", reflect.TypeOf((*MockArticleService)(nil).PurgeExpiredArticles), ctx, limit)
+}
+
+// ReactArticle mocks base method.
+func (m *MockArticleService) ReactArticle(ctx *gin.Context, userId, articleId int64, status service.ReactionStatus) (service.ReactionStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReactArticle", ctx, userId, articleId, status)
+	ret0, _ := ret[0].(service.ReactionStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReactArticle indicates an expected call of ReactArticle.
+func (mr *MockArticleServiceMockRecorder) ReactArticle(ctx, userId, articleId, status any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReactArticle", reflect.TypeOf((*MockArticleService)(nil).ReactArticle), ctx, userId, articleId, status)
+}
+
+// RecomputeHotScores mocks base method.
+func (m *MockArticleService) RecomputeHotScores(ctx *gin.Context, lockTTL time.Duration) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecomputeHotScores", ctx, lockTTL)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecomputeHotScores indicates an expected call of RecomputeHotScores.
+func (mr *MockArticleServiceMockRecorder) RecomputeHotScores(ctx, lockTTL any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecomputeHotScores", reflect.TypeOf((*MockArticleService)(nil).RecomputeHotScores), ctx, lockTTL)
+}
+
+// ReconcileArticles mocks base method.
+func (m *MockArticleService) ReconcileArticles(ctx *gin.Context, repair bool, lockTTL time.Duration) (*service.ReconcileReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReconcileArticles", ctx, repair, lockTTL)
+	ret0, _ := ret[0].(*service.ReconcileReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReconcileArticles indicates an expected call of ReconcileArticles.
+func (mr *MockArticleServiceMockRecorder) ReconcileArticles(ctx, repair, lockTTL any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReconcileArticles", reflect.TypeOf((*MockArticleService)(nil).ReconcileArticles), ctx, repair, lockTTL)
+}
+
+// RelayOutboxEvents mocks base method.
+func (m *MockArticleService) RelayOutboxEvents(ctx *gin.Context, articleId, limit int64) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RelayOutboxEvents", ctx, articleId, limit)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RelayOutboxEvents indicates an expected call of RelayOutboxEvents.
+func (mr *MockArticleServiceMockRecorder) RelayOutboxEvents(ctx, articleId, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RelayOutboxEvents", reflect.TypeOf((*MockArticleService)(nil).RelayOutboxEvents), ctx, articleId, limit)
+}
+
+// RequeueDeadOutboxEvents mocks base method.
+func (m *MockArticleService) RequeueDeadOutboxEvents(ctx *gin.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequeueDeadOutboxEvents", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequeueDeadOutboxEvents indicates an expected call of RequeueDeadOutboxEvents.
+func (mr *MockArticleServiceMockRecorder) RequeueDeadOutboxEvents(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequeueDeadOutboxEvents", reflect.TypeOf((*MockArticleService)(nil).RequeueDeadOutboxEvents), ctx)
+}
+
+// RestoreDeletedArticle mocks base method.
+func (m *MockArticleService) RestoreDeletedArticle(ctx *gin.Context, articleId, authorId int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreDeletedArticle", ctx, articleId, authorId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreDeletedArticle indicates an expected call of RestoreDeletedArticle.
+func (mr *MockArticleServiceMockRecorder) RestoreDeletedArticle(ctx, articleId, authorId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreDeletedArticle", reflect.TypeOf((*MockArticleService)(nil).RestoreDeletedArticle), ctx, articleId, authorId)
+}
+
+// RestoreRevision mocks base method.
+func (m *MockArticleService) RestoreRevision(ctx *gin.Context, revisionId, authorId, version int64) (*service.Article, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreRevision", ctx, revisionId, authorId, version)
+	ret0, _ := ret[0].(*service.Article)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreRevision indicates an expected call of RestoreRevision.
+func (mr *MockArticleServiceMockRecorder) RestoreRevision(ctx, revisionId, authorId, version any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreRevision", reflect.TypeOf((*MockArticleService)(nil).RestoreRevision), ctx, revisionId, authorId, version)
+}
+
+// UpdateSchedule mocks base method.
+func (m *MockArticleService) UpdateSchedule(ctx *gin.Context, scheduleId, authorId, executeAt int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSchedule", ctx, scheduleId, authorId, executeAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSchedule indicates an expected call of UpdateSchedule.
+func (mr *MockArticleServiceMockRecorder) UpdateSchedule(ctx, scheduleId, authorId, executeAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSchedule", reflect.TypeOf((*MockArticleService)(nil).UpdateSchedule), ctx, scheduleId, authorId, executeAt)
+}
+
+// WithDrawArticle mocks base method.
+func (m *MockArticleService) WithDrawArticle(ctx *gin.Context, articleId, userId int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithDrawArticle", ctx, articleId, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WithDrawArticle indicates an expected call of WithDrawArticle.
+func (mr *MockArticleServiceMockRecorder) WithDrawArticle(ctx, articleId, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithDrawArticle", reflect.TypeOf((*MockArticleService)(nil).WithDrawArticle), ctx, articleId, userId)
+}
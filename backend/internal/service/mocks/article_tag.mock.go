@@ -0,0 +1,71 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/service/article_tag.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./internal/service/article_tag.go -package=usvcmocks -destination=./internal/service/mocks/article_tag.mock.go
+//
+
+// Package usvcmocks is a generated GoMock package.
+package usvcmocks
+
+import (
+	service "ibook/internal/service"
+	reflect "reflect"
+
+	gin "github.com/gin-gonic/gin"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockArticleTagRepo is a mock of ArticleTagRepo interface.
+type MockArticleTagRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockArticleTagRepoMockRecorder
+}
+
+// MockArticleTagRepoMockRecorder is the mock recorder for MockArticleTagRepo.
+type MockArticleTagRepoMockRecorder struct {
+	mock *MockArticleTagRepo
+}
+
+// NewMockArticleTagRepo creates a new mock instance.
+func NewMockArticleTagRepo(ctrl *gomock.Controller) *MockArticleTagRepo {
+	mock := &MockArticleTagRepo{ctrl: ctrl}
+	mock.recorder = &MockArticleTagRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockArticleTagRepo) EXPECT() *MockArticleTagRepoMockRecorder {
+	return m.recorder
+}
+
+// ListTagCounts mocks base method.
+func (m *MockArticleTagRepo) ListTagCounts(ctx *gin.Context, limit int64) ([]*service.TagCount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTagCounts", ctx, limit)
+	ret0, _ := ret[0].([]*service.TagCount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTagCounts indicates an expected call of ListTagCounts.
+func (mr *MockArticleTagRepoMockRecorder) ListTagCounts(ctx, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTagCounts", reflect.TypeOf((*MockArticleTagRepo)(nil).ListTagCounts), ctx, limit)
+}
+
+// ListTagsByArticleIds mocks base method.
+func (m *MockArticleTagRepo) ListTagsByArticleIds(ctx *gin.Context, ids []int64) (map[int64][]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTagsByArticleIds", ctx, ids)
+	ret0, _ := ret[0].(map[int64][]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTagsByArticleIds indicates an expected call of ListTagsByArticleIds.
+func (mr *MockArticleTagRepoMockRecorder) ListTagsByArticleIds(ctx, ids any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTagsByArticleIds", reflect.TypeOf((*MockArticleTagRepo)(nil).ListTagsByArticleIds), ctx, ids)
+}
@@ -0,0 +1,70 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/service/feed.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./internal/service/feed.go -package=usvcmocks -destination=./internal/service/mocks/feed.mock.go
+//
+
+// Package usvcmocks is a generated GoMock package.
+package usvcmocks
+
+import (
+	service "ibook/internal/service"
+	reflect "reflect"
+
+	gin "github.com/gin-gonic/gin"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockFeedRepo is a mock of FeedRepo interface.
+type MockFeedRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockFeedRepoMockRecorder
+}
+
+// MockFeedRepoMockRecorder is the mock recorder for MockFeedRepo.
+type MockFeedRepoMockRecorder struct {
+	mock *MockFeedRepo
+}
+
+// NewMockFeedRepo creates a new mock instance.
+func NewMockFeedRepo(ctrl *gomock.Controller) *MockFeedRepo {
+	mock := &MockFeedRepo{ctrl: ctrl}
+	mock.recorder = &MockFeedRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFeedRepo) EXPECT() *MockFeedRepoMockRecorder {
+	return m.recorder
+}
+
+// ListInbox mocks base method.
+func (m *MockFeedRepo) ListInbox(ctx *gin.Context, userId int64, cursor service.ListCursor, limit int64) ([]*service.FeedItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListInbox", ctx, userId, cursor, limit)
+	ret0, _ := ret[0].([]*service.FeedItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListInbox indicates an expected call of ListInbox.
+func (mr *MockFeedRepoMockRecorder) ListInbox(ctx, userId, cursor, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListInbox", reflect.TypeOf((*MockFeedRepo)(nil).ListInbox), ctx, userId, cursor, limit)
+}
+
+// PushInbox mocks base method.
+func (m *MockFeedRepo) PushInbox(ctx *gin.Context, userIds []int64, item *service.FeedItem) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PushInbox", ctx, userIds, item)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PushInbox indicates an expected call of PushInbox.
+func (mr *MockFeedRepoMockRecorder) PushInbox(ctx, userIds, item any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PushInbox", reflect.TypeOf((*MockFeedRepo)(nil).PushInbox), ctx, userIds, item)
+}
@@ -0,0 +1,247 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/service/follow.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./internal/service/follow.go -package=usvcmocks -destination=./internal/service/mocks/follow.mock.go
+//
+
+// Package usvcmocks is a generated GoMock package.
+package usvcmocks
+
+import (
+	service "ibook/internal/service"
+	reflect "reflect"
+
+	gin "github.com/gin-gonic/gin"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockFollowRepo is a mock of FollowRepo interface.
+type MockFollowRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockFollowRepoMockRecorder
+}
+
+// MockFollowRepoMockRecorder is the mock recorder for MockFollowRepo.
+type MockFollowRepoMockRecorder struct {
+	mock *MockFollowRepo
+}
+
+// NewMockFollowRepo creates a new mock instance.
+func NewMockFollowRepo(ctrl *gomock.Controller) *MockFollowRepo {
+	mock := &MockFollowRepo{ctrl: ctrl}
+	mock.recorder = &MockFollowRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFollowRepo) EXPECT() *MockFollowRepoMockRecorder {
+	return m.recorder
+}
+
+// FilterFollowed mocks base method.
+func (m *MockFollowRepo) FilterFollowed(ctx *gin.Context, followerId int64, followeeIds []int64) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FilterFollowed", ctx, followerId, followeeIds)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FilterFollowed indicates an expected call of FilterFollowed.
+func (mr *MockFollowRepoMockRecorder) FilterFollowed(ctx, followerId, followeeIds any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FilterFollowed", reflect.TypeOf((*MockFollowRepo)(nil).FilterFollowed), ctx, followerId, followeeIds)
+}
+
+// Follow mocks base method.
+func (m *MockFollowRepo) Follow(ctx *gin.Context, followerId, followeeId int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Follow", ctx, followerId, followeeId)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Follow indicates an expected call of Follow.
+func (mr *MockFollowRepoMockRecorder) Follow(ctx, followerId, followeeId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Follow", reflect.TypeOf((*MockFollowRepo)(nil).Follow), ctx, followerId, followeeId)
+}
+
+// Followed mocks base method.
+func (m *MockFollowRepo) Followed(ctx *gin.Context, followerId, followeeId int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Followed", ctx, followerId, followeeId)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Followed indicates an expected call of Followed.
+func (mr *MockFollowRepoMockRecorder) Followed(ctx, followerId, followeeId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Followed", reflect.TypeOf((*MockFollowRepo)(nil).Followed), ctx, followerId, followeeId)
+}
+
+// GetStatistic mocks base method.
+func (m *MockFollowRepo) GetStatistic(ctx *gin.Context, userId int64) (*service.FollowStatistic, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStatistic", ctx, userId)
+	ret0, _ := ret[0].(*service.FollowStatistic)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStatistic indicates an expected call of GetStatistic.
+func (mr *MockFollowRepoMockRecorder) GetStatistic(ctx, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStatistic", reflect.TypeOf((*MockFollowRepo)(nil).GetStatistic), ctx, userId)
+}
+
+// ListFollowees mocks base method.
+func (m *MockFollowRepo) ListFollowees(ctx *gin.Context, userId int64, cursor service.ListCursor, limit int64) ([]*service.FollowRelation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFollowees", ctx, userId, cursor, limit)
+	ret0, _ := ret[0].([]*service.FollowRelation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFollowees indicates an expected call of ListFollowees.
+func (mr *MockFollowRepoMockRecorder) ListFollowees(ctx, userId, cursor, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFollowees", reflect.TypeOf((*MockFollowRepo)(nil).ListFollowees), ctx, userId, cursor, limit)
+}
+
+// ListFollowerIds mocks base method.
+func (m *MockFollowRepo) ListFollowerIds(ctx *gin.Context, userId, afterId, limit int64) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFollowerIds", ctx, userId, afterId, limit)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFollowerIds indicates an expected call of ListFollowerIds.
+func (mr *MockFollowRepoMockRecorder) ListFollowerIds(ctx, userId, afterId, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFollowerIds", reflect.TypeOf((*MockFollowRepo)(nil).ListFollowerIds), ctx, userId, afterId, limit)
+}
+
+// ListFollowers mocks base method.
+func (m *MockFollowRepo) ListFollowers(ctx *gin.Context, userId int64, cursor service.ListCursor, limit int64) ([]*service.FollowRelation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFollowers", ctx, userId, cursor, limit)
+	ret0, _ := ret[0].([]*service.FollowRelation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFollowers indicates an expected call of ListFollowers.
+func (mr *MockFollowRepoMockRecorder) ListFollowers(ctx, userId, cursor, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFollowers", reflect.TypeOf((*MockFollowRepo)(nil).ListFollowers), ctx, userId, cursor, limit)
+}
+
+// ListPopularFollowees mocks base method.
+func (m *MockFollowRepo) ListPopularFollowees(ctx *gin.Context, userId, minFollowers int64) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPopularFollowees", ctx, userId, minFollowers)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPopularFollowees indicates an expected call of ListPopularFollowees.
+func (mr *MockFollowRepoMockRecorder) ListPopularFollowees(ctx, userId, minFollowers any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPopularFollowees", reflect.TypeOf((*MockFollowRepo)(nil).ListPopularFollowees), ctx, userId, minFollowers)
+}
+
+// Unfollow mocks base method.
+func (m *MockFollowRepo) Unfollow(ctx *gin.Context, followerId, followeeId int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unfollow", ctx, followerId, followeeId)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Unfollow indicates an expected call of Unfollow.
+func (mr *MockFollowRepoMockRecorder) Unfollow(ctx, followerId, followeeId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unfollow", reflect.TypeOf((*MockFollowRepo)(nil).Unfollow), ctx, followerId, followeeId)
+}
+
+// MockFollowCache is a mock of FollowCache interface.
+type MockFollowCache struct {
+	ctrl     *gomock.Controller
+	recorder *MockFollowCacheMockRecorder
+}
+
+// MockFollowCacheMockRecorder is the mock recorder for MockFollowCache.
+type MockFollowCacheMockRecorder struct {
+	mock *MockFollowCache
+}
+
+// NewMockFollowCache creates a new mock instance.
+func NewMockFollowCache(ctrl *gomock.Controller) *MockFollowCache {
+	mock := &MockFollowCache{ctrl: ctrl}
+	mock.recorder = &MockFollowCacheMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFollowCache) EXPECT() *MockFollowCacheMockRecorder {
+	return m.recorder
+}
+
+// DelStatistic mocks base method.
+func (m *MockFollowCache) DelStatistic(ctx *gin.Context, userIds ...int64) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx}
+	for _, a := range userIds {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DelStatistic", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DelStatistic indicates an expected call of DelStatistic.
+func (mr *MockFollowCacheMockRecorder) DelStatistic(ctx any, userIds ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx}, userIds...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DelStatistic", reflect.TypeOf((*MockFollowCache)(nil).DelStatistic), varargs...)
+}
+
+// GetStatistic mocks base method.
+func (m *MockFollowCache) GetStatistic(ctx *gin.Context, userId int64) (*service.FollowStatistic, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStatistic", ctx, userId)
+	ret0, _ := ret[0].(*service.FollowStatistic)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStatistic indicates an expected call of GetStatistic.
+func (mr *MockFollowCacheMockRecorder) GetStatistic(ctx, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStatistic", reflect.TypeOf((*MockFollowCache)(nil).GetStatistic), ctx, userId)
+}
+
+// SetStatistic mocks base method.
+func (m *MockFollowCache) SetStatistic(ctx *gin.Context, stat *service.FollowStatistic) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetStatistic", ctx, stat)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetStatistic indicates an expected call of SetStatistic.
+func (mr *MockFollowCacheMockRecorder) SetStatistic(ctx, stat any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetStatistic", reflect.TypeOf((*MockFollowCache)(nil).SetStatistic), ctx, stat)
+}
@@ -3,7 +3,7 @@
 //
 // Generated by this command:
 //
-//	mockgen -source=./internal/service/user.go -package=usvcmocks -destination=./internal/service/mocks/user.mocks.go
+//	mockgen -source=./internal/service/user.go -package=usvcmocks -destination=./internal/service/mocks/user.mock.go
 //
 
 // Package usvcmocks is a generated GoMock package.
@@ -17,18 +17,18 @@ import (
 	gomock "go.uber.org/mock/gomock"
 )
 
-// MockUserRepo is a mocks of UserRepo interface.
+// MockUserRepo is a mock of UserRepo interface.
 type MockUserRepo struct {
 	ctrl     *gomock.Controller
 	recorder *MockUserRepoMockRecorder
 }
 
-// MockUserRepoMockRecorder is the mocks recorder for MockUserRepo.
+// MockUserRepoMockRecorder is the mock recorder for MockUserRepo.
 type MockUserRepoMockRecorder struct {
 	mock *MockUserRepo
 }
 
-// NewMockUserRepo creates a new mocks instance.
+// NewMockUserRepo creates a new mock instance.
 func NewMockUserRepo(ctrl *gomock.Controller) *MockUserRepo {
 	mock := &MockUserRepo{ctrl: ctrl}
 	mock.recorder = &MockUserRepoMockRecorder{mock}
@@ -99,18 +99,98 @@ func (mr *MockUserRepoMockRecorder) FindUserByPhone(number any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUserByPhone", reflect.TypeOf((*MockUserRepo)(nil).FindUserByPhone), number)
 }
 
-// MockUserCache is a mocks of UserCache interface.
+// UpdatePassword mocks base method.
+func (m *MockUserRepo) UpdatePassword(ctx *gin.Context, userId int64, password string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePassword", ctx, userId, password)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePassword indicates an expected call of UpdatePassword.
+func (mr *MockUserRepoMockRecorder) UpdatePassword(ctx, userId, password any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePassword", reflect.TypeOf((*MockUserRepo)(nil).UpdatePassword), ctx, userId, password)
+}
+
+// UpdateProfile mocks base method.
+func (m *MockUserRepo) UpdateProfile(ctx *gin.Context, userId int64, edit *service.UserProfileEdit) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProfile", ctx, userId, edit)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateProfile indicates an expected call of UpdateProfile.
+func (mr *MockUserRepoMockRecorder) UpdateProfile(ctx, userId, edit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProfile", reflect.TypeOf((*MockUserRepo)(nil).UpdateProfile), ctx, userId, edit)
+}
+
+// MockUserTokenRepo is a mock of UserTokenRepo interface.
+type MockUserTokenRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockUserTokenRepoMockRecorder
+}
+
+// MockUserTokenRepoMockRecorder is the mock recorder for MockUserTokenRepo.
+type MockUserTokenRepoMockRecorder struct {
+	mock *MockUserTokenRepo
+}
+
+// NewMockUserTokenRepo creates a new mock instance.
+func NewMockUserTokenRepo(ctrl *gomock.Controller) *MockUserTokenRepo {
+	mock := &MockUserTokenRepo{ctrl: ctrl}
+	mock.recorder = &MockUserTokenRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUserTokenRepo) EXPECT() *MockUserTokenRepoMockRecorder {
+	return m.recorder
+}
+
+// RevokeTokens mocks base method.
+func (m *MockUserTokenRepo) RevokeTokens(ctx *gin.Context, userId, before int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeTokens", ctx, userId, before)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeTokens indicates an expected call of RevokeTokens.
+func (mr *MockUserTokenRepoMockRecorder) RevokeTokens(ctx, userId, before any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeTokens", reflect.TypeOf((*MockUserTokenRepo)(nil).RevokeTokens), ctx, userId, before)
+}
+
+// RevokedBefore mocks base method.
+func (m *MockUserTokenRepo) RevokedBefore(ctx *gin.Context, userId int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokedBefore", ctx, userId)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokedBefore indicates an expected call of RevokedBefore.
+func (mr *MockUserTokenRepoMockRecorder) RevokedBefore(ctx, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokedBefore", reflect.TypeOf((*MockUserTokenRepo)(nil).RevokedBefore), ctx, userId)
+}
+
+// MockUserCache is a mock of UserCache interface.
 type MockUserCache struct {
 	ctrl     *gomock.Controller
 	recorder *MockUserCacheMockRecorder
 }
 
-// MockUserCacheMockRecorder is the mocks recorder for MockUserCache.
+// MockUserCacheMockRecorder is the mock recorder for MockUserCache.
 type MockUserCacheMockRecorder struct {
 	mock *MockUserCache
 }
 
-// NewMockUserCache creates a new mocks instance.
+// NewMockUserCache creates a new mock instance.
 func NewMockUserCache(ctrl *gomock.Controller) *MockUserCache {
 	mock := &MockUserCache{ctrl: ctrl}
 	mock.recorder = &MockUserCacheMockRecorder{mock}
@@ -122,6 +202,20 @@ func (m *MockUserCache) EXPECT() *MockUserCacheMockRecorder {
 	return m.recorder
 }
 
+// DelUserById mocks base method.
+func (m *MockUserCache) DelUserById(ctx *gin.Context, userId int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DelUserById", ctx, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DelUserById indicates an expected call of DelUserById.
+func (mr *MockUserCacheMockRecorder) DelUserById(ctx, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DelUserById", reflect.TypeOf((*MockUserCache)(nil).DelUserById), ctx, userId)
+}
+
 // GetUserById mocks base method.
 func (m *MockUserCache) GetUserById(ctx *gin.Context, userId int64) (*service.User, error) {
 	m.ctrl.T.Helper()
@@ -151,18 +245,18 @@ func (mr *MockUserCacheMockRecorder) SetUserById(ctx, user any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserById", reflect.TypeOf((*MockUserCache)(nil).SetUserById), ctx, user)
 }
 
-// MockUserService is a mocks of UserService interface.
+// MockUserService is a mock of UserService interface.
 type MockUserService struct {
 	ctrl     *gomock.Controller
 	recorder *MockUserServiceMockRecorder
 }
 
-// MockUserServiceMockRecorder is the mocks recorder for MockUserService.
+// MockUserServiceMockRecorder is the mock recorder for MockUserService.
 type MockUserServiceMockRecorder struct {
 	mock *MockUserService
 }
 
-// NewMockUserService creates a new mocks instance.
+// NewMockUserService creates a new mock instance.
 func NewMockUserService(ctrl *gomock.Controller) *MockUserService {
 	mock := &MockUserService{ctrl: ctrl}
 	mock.recorder = &MockUserServiceMockRecorder{mock}
@@ -174,19 +268,82 @@ func (m *MockUserService) EXPECT() *MockUserServiceMockRecorder {
 	return m.recorder
 }
 
+// EditProfile mocks base method.
+func (m *MockUserService) EditProfile(ctx *gin.Context, userId int64, edit *service.UserProfileEdit) (*service.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EditProfile", ctx, userId, edit)
+	ret0, _ := ret[0].(*service.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EditProfile indicates an expected call of EditProfile.
+func (mr *MockUserServiceMockRecorder) EditProfile(ctx, userId, edit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EditProfile", reflect.TypeOf((*MockUserService)(nil).EditProfile), ctx, userId, edit)
+}
+
+// Follow mocks base method.
+func (m *MockUserService) Follow(ctx *gin.Context, followerId, followeeId int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Follow", ctx, followerId, followeeId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Follow indicates an expected call of Follow.
+func (mr *MockUserServiceMockRecorder) Follow(ctx, followerId, followeeId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Follow", reflect.TypeOf((*MockUserService)(nil).Follow), ctx, followerId, followeeId)
+}
+
+// ListFollowees mocks base method.
+func (m *MockUserService) ListFollowees(ctx *gin.Context, userId int64, cursor service.ListCursor, limit int64) ([]*service.FollowRelation, service.ListCursor, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFollowees", ctx, userId, cursor, limit)
+	ret0, _ := ret[0].([]*service.FollowRelation)
+	ret1, _ := ret[1].(service.ListCursor)
+	ret2, _ := ret[2].(bool)
+	ret3, _ := ret[3].(error)
+	return ret0, ret1, ret2, ret3
+}
+
+// ListFollowees indicates an expected call of ListFollowees.
+func (mr *MockUserServiceMockRecorder) ListFollowees(ctx, userId, cursor, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFollowees", reflect.TypeOf((*MockUserService)(nil).ListFollowees), ctx, userId, cursor, limit)
+}
+
+// ListFollowers mocks base method.
+func (m *MockUserService) ListFollowers(ctx *gin.Context, userId int64, cursor service.ListCursor, limit int64) ([]*service.FollowRelation, service.ListCursor, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFollowers", ctx, userId, cursor, limit)
+	ret0, _ := ret[0].([]*service.FollowRelation)
+	ret1, _ := ret[1].(service.ListCursor)
+	ret2, _ := ret[2].(bool)
+	ret3, _ := ret[3].(error)
+	return ret0, ret1, ret2, ret3
+}
+
+// ListFollowers indicates an expected call of ListFollowers.
+func (mr *MockUserServiceMockRecorder) ListFollowers(ctx, userId, cursor, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFollowers", reflect.TypeOf((*MockUserService)(nil).ListFollowers), ctx, userId, cursor, limit)
+}
+
 // Login mocks base method.
-func (m *MockUserService) Login(ctx *gin.Context, email, password string) (*service.User, error) {
+func (m *MockUserService) Login(ctx *gin.Context, phone, email, password string) (*service.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Login", ctx, email, password)
+	ret := m.ctrl.Call(m, "Login", ctx, phone, email, password)
 	ret0, _ := ret[0].(*service.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Login indicates an expected call of Login.
-func (mr *MockUserServiceMockRecorder) Login(ctx, email, password any) *gomock.Call {
+func (mr *MockUserServiceMockRecorder) Login(ctx, phone, email, password any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockUserService)(nil).Login), ctx, email, password)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockUserService)(nil).Login), ctx, phone, email, password)
 }
 
 // LoginSMS mocks base method.
@@ -205,18 +362,33 @@ func (mr *MockUserServiceMockRecorder) LoginSMS(context, phoneNumber, code any)
 }
 
 // Profile mocks base method.
-func (m *MockUserService) Profile(ctx *gin.Context, userId int64) (*service.User, error) {
+func (m *MockUserService) Profile(ctx *gin.Context, userId, viewerId int64) (*service.User, *service.FollowStatistic, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Profile", ctx, userId)
+	ret := m.ctrl.Call(m, "Profile", ctx, userId, viewerId)
 	ret0, _ := ret[0].(*service.User)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret1, _ := ret[1].(*service.FollowStatistic)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
 }
 
 // Profile indicates an expected call of Profile.
-func (mr *MockUserServiceMockRecorder) Profile(ctx, userId any) *gomock.Call {
+func (mr *MockUserServiceMockRecorder) Profile(ctx, userId, viewerId any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Profile", reflect.TypeOf((*MockUserService)(nil).Profile), ctx, userId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Profile", reflect.TypeOf((*MockUserService)(nil).Profile), ctx, userId, viewerId)
+}
+
+// ResetPassword mocks base method.
+func (m *MockUserService) ResetPassword(ctx *gin.Context, emailAddr, code, password, confirmPassword string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPassword", ctx, emailAddr, code, password, confirmPassword)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetPassword indicates an expected call of ResetPassword.
+func (mr *MockUserServiceMockRecorder) ResetPassword(ctx, emailAddr, code, password, confirmPassword any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockUserService)(nil).ResetPassword), ctx, emailAddr, code, password, confirmPassword)
 }
 
 // SendLoginVerifyCode mocks base method.
@@ -233,17 +405,45 @@ func (mr *MockUserServiceMockRecorder) SendLoginVerifyCode(ctx, phoneNumber any)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendLoginVerifyCode", reflect.TypeOf((*MockUserService)(nil).SendLoginVerifyCode), ctx, phoneNumber)
 }
 
+// SendResetPasswordCode mocks base method.
+func (m *MockUserService) SendResetPasswordCode(ctx *gin.Context, emailAddr string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendResetPasswordCode", ctx, emailAddr)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendResetPasswordCode indicates an expected call of SendResetPasswordCode.
+func (mr *MockUserServiceMockRecorder) SendResetPasswordCode(ctx, emailAddr any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendResetPasswordCode", reflect.TypeOf((*MockUserService)(nil).SendResetPasswordCode), ctx, emailAddr)
+}
+
 // SignUp mocks base method.
-func (m *MockUserService) SignUp(ctx *gin.Context, email, password, confirmPassword string) (*service.User, error) {
+func (m *MockUserService) SignUp(ctx *gin.Context, email, nickName, password, confirmPassword string) (*service.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SignUp", ctx, email, password, confirmPassword)
+	ret := m.ctrl.Call(m, "SignUp", ctx, email, nickName, password, confirmPassword)
 	ret0, _ := ret[0].(*service.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // SignUp indicates an expected call of SignUp.
-func (mr *MockUserServiceMockRecorder) SignUp(ctx, email, password, confirmPassword any) *gomock.Call {
+func (mr *MockUserServiceMockRecorder) SignUp(ctx, email, nickName, password, confirmPassword any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignUp", reflect.TypeOf((*MockUserService)(nil).SignUp), ctx, email, nickName, password, confirmPassword)
+}
+
+// Unfollow mocks base method.
+func (m *MockUserService) Unfollow(ctx *gin.Context, followerId, followeeId int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unfollow", ctx, followerId, followeeId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Unfollow indicates an expected call of Unfollow.
+func (mr *MockUserServiceMockRecorder) Unfollow(ctx, followerId, followeeId any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignUp", reflect.TypeOf((*MockUserService)(nil).SignUp), ctx, email, password, confirmPassword)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unfollow", reflect.TypeOf((*MockUserService)(nil).Unfollow), ctx, followerId, followeeId)
 }
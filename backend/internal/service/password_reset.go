@@ -0,0 +1,70 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"ibook/internal/service/message/email"
+	"ibook/pkg/utils/randcode"
+	"strconv"
+	"time"
+)
+
+// SendResetPasswordCode 向邮箱发送重置密码的验证码
+// 邮箱未注册时直接返回成功，不向调用方暴露邮箱是否已注册
+func (s *userService) SendResetPasswordCode(ctx *gin.Context, emailAddr string) error {
+	user, err := s.ur.FindUserByEmail(emailAddr)
+	if errors.Is(err, UserNotExistsErr) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	code := randcode.GenVerifyCode(6, randcode.TYPE_DIGIT)
+	// 与短信登录共用验证码的存储与校验，按业务名区分
+	if err = s.vcr.SetVerifyCode(ctx, resetVerifyCodeKey(emailAddr), code, resetCodeLifeSeconds); err != nil {
+		return err
+	}
+	return s.er.SendMessage(ctx, email.TplResetPassword, []string{user.Email}, []email.MsgArgs{
+		{Name: "code", Value: code},
+		{Name: "minutes", Value: strconv.Itoa(resetCodeLifeSeconds / 60)},
+	})
+}
+
+// ResetPassword 校验验证码后设置新密码，并使该用户已签发的令牌全部失效
+func (s *userService) ResetPassword(ctx *gin.Context, emailAddr string, code string, password string, confirmPassword string) error {
+	// 先校验两次密码，避免输入有误时消耗验证码
+	if password != confirmPassword {
+		return PasswordNotEqualErr
+	}
+	ok, err := s.vcr.CheckVerifyCode(ctx, resetVerifyCodeKey(emailAddr), code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return VerifyCodeComparedErr
+	}
+	user, err := s.ur.FindUserByEmail(emailAddr)
+	if err != nil {
+		return err
+	}
+	cryptPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	if err = s.ur.UpdatePassword(ctx, user.Id, string(cryptPassword)); err != nil {
+		return err
+	}
+	// 令牌按毫秒精度的签发时间 iatMs 与吊销时间比较，此刻之前签发的令牌全部失效；
+	// 不带 iatMs 的旧令牌退回按 iat 所在秒的起始时间比较，本秒内签发的旧令牌同样失效
+	if err = s.tr.RevokeTokens(ctx, user.Id, time.Now().UnixMilli()); err != nil {
+		return fmt.Errorf("密码已修改，但使旧令牌失效时出错：%w", err)
+	}
+	_ = s.uc.DelUserById(ctx, user.Id)
+	return nil
+}
+
+func resetVerifyCodeKey(emailAddr string) string {
+	return fmt.Sprintf("verify_code:%s:%s", "reset", emailAddr)
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"ibook/pkg/utils/fulltext"
+	mylogger "ibook/pkg/utils/logger"
+	"ibook/pkg/utils/markdown"
+)
+
+var (
+	SearchIndexNotReadyErr = errors.New("搜索索引尚未就绪")
+)
+
+// snippetLength 搜索结果中正文片段的最大字符数
+const snippetLength = 120
+
+type SearchHit struct {
+	ArticleId int64
+	Score     float64
+}
+
+type SearchResult struct {
+	Article *Article
+	Score   float64
+	// Title 与 Snippet 均已转义，命中的词使用 <em> 标记
+	Title   string
+	Snippet string
+}
+
+// ArticleSearchRepo 已发表文章的全文索引
+type ArticleSearchRepo interface {
+	IndexArticle(ctx *gin.Context, article *Article) error
+	RemoveArticle(ctx *gin.Context, articleId int64) error
+	// Search 返回按相关度排序的命中结果、查询切分出的词以及命中总数
+	Search(ctx *gin.Context, query string, offset int, limit int) ([]*SearchHit, []string, int, error)
+	// CatchUp 从读者库增量同步上次同步之后发生变化的文章，返回处理的文章数
+	CatchUp(ctx *gin.Context) (int, error)
+	// Rebuild 从读者库全量重建索引，返回索引的文章数
+	Rebuild(ctx *gin.Context) (int, error)
+	LoadSnapshot(ctx *gin.Context) error
+	SaveSnapshot(ctx *gin.Context) error
+}
+
+type SearchService interface {
+	Search(ctx *gin.Context, query string, offset int, limit int) ([]*SearchResult, int, error)
+	// Init 优先从快照加载索引，快照不可用时从读者库重建
+	Init(ctx *gin.Context) error
+	CatchUp(ctx *gin.Context) (int, error)
+	Rebuild(ctx *gin.Context) (int, error)
+	SaveSnapshot(ctx *gin.Context) error
+}
+
+type searchService struct {
+	sr     ArticleSearchRepo
+	rr     ArticleReaderRepo
+	logger mylogger.Logger
+}
+
+func NewSearchService(sr ArticleSearchRepo, rr ArticleReaderRepo, logger mylogger.Logger) SearchService {
+	return &searchService{sr: sr, rr: rr, logger: logger}
+}
+
+func (service *searchService) Search(ctx *gin.Context, query string, offset int, limit int) ([]*SearchResult, int, error) {
+	hits, terms, total, err := service.sr.Search(ctx, query, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	ids := make([]int64, 0, len(hits))
+	scores := make(map[int64]float64, len(hits))
+	for _, hit := range hits {
+		ids = append(ids, hit.ArticleId)
+		scores[hit.ArticleId] = hit.Score
+	}
+	// 按命中顺序获取文章，索引与读者库短暂不一致时已撤回的文章会被跳过
+	articles, err := service.rr.GetPubArticlesByIds(ctx, ids)
+	if err != nil {
+		return nil, 0, err
+	}
+	results := make([]*SearchResult, 0, len(articles))
+	for _, article := range articles {
+		plain := article.Content
+		if article.Format == ContentFormatMarkdown {
+			plain = markdown.PlainText(plain)
+		}
+		results = append(results, &SearchResult{
+			Article: article,
+			Score:   scores[article.Id],
+			Title:   fulltext.Highlight(article.Title, terms, len([]rune(article.Title))),
+			Snippet: fulltext.Highlight(markdown.CollapseSpace(plain), terms, snippetLength),
+		})
+	}
+	return results, total, nil
+}
+
+func (service *searchService) Init(ctx *gin.Context) error {
+	l := mylogger.TagCtxLogger(ctx, service.logger, "SearchService-Init")
+	err := service.sr.LoadSnapshot(ctx)
+	if err == nil {
+		// 快照保存之后发生的变化通过增量同步补齐
+		_, err = service.sr.CatchUp(ctx)
+		return err
+	}
+	l.Warn("加载搜索索引快照失败，从读者库重建", mylogger.Field{
+		Key:   "详情",
+		Value: err,
+	})
+	if _, err = service.sr.Rebuild(ctx); err != nil {
+		return err
+	}
+	return service.sr.SaveSnapshot(ctx)
+}
+
+func (service *searchService) CatchUp(ctx *gin.Context) (int, error) {
+	return service.sr.CatchUp(ctx)
+}
+
+func (service *searchService) Rebuild(ctx *gin.Context) (int, error) {
+	return service.sr.Rebuild(ctx)
+}
+
+func (service *searchService) SaveSnapshot(ctx *gin.Context) error {
+	return service.sr.SaveSnapshot(ctx)
+}
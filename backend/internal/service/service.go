@@ -1,9 +1,24 @@
 package service
 
-import "github.com/google/wire"
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/google/wire"
+	"html"
+	"ibook/pkg/utils/markdown"
+	"strings"
+)
 
 // ServiceProviderSet is data providers.
-var ServiceProviderSet = wire.NewSet(NewUserService, NewArticleService)
+var ServiceProviderSet = wire.NewSet(NewUserService, NewArticleService, NewSearchService, NewCommentService, NewUploadService)
+
+var (
+	InvalidCursorErr = errors.New("分页游标不合法")
+)
+
+// abstractLength 文章摘要的最大字符数
+const abstractLength = 100
 
 type User struct {
 	Id          int64
@@ -11,6 +26,12 @@ type User struct {
 	NickName    string
 	PhoneNumber string
 	PassWord    string
+	Bio         string
+	// Birthday 格式为 2006-01-02，未设置时为空
+	Birthday  string
+	AvatarUrl string
+	// NickNameUpdatedTime 最近一次修改昵称的时间，用于限制修改频率
+	NickNameUpdatedTime int64
 }
 
 type ArticleAuthor struct {
@@ -30,15 +51,34 @@ const (
 	ArticleStatusPrivate
 )
 
+// ContentFormat 文章正文的格式，未指定时按纯文本处理
+type ContentFormat uint8
+
+const (
+	ContentFormatUnknown ContentFormat = iota
+	ContentFormatPlain
+	ContentFormatMarkdown
+)
+
 type Article struct {
-	Id          int64
-	Title       string
-	Abstract    string
-	Content     string
+	Id       int64
+	Title    string
+	Abstract string
+	Content  string
+	Format   ContentFormat
+	Category string
+	// Tags 为 nil 时表示编辑文章时不修改标签
+	Tags []string
+	// HTML 发表时由正文渲染并清洗过的 HTML，仅读者库中存在
+	HTML        string
 	Status      ArticleStatus
 	Author      Author
 	UpdatedTime int64
 	CreatedTime int64
+	// Version 作者库草稿的版本号，编辑时作为期望版本号传入，写入成功后为新的版本号
+	Version int64
+	// Interactive 读者端列表中填充的互动计数，未填充时为 nil
+	Interactive *Interactive
 }
 
 type Author struct {
@@ -46,10 +86,112 @@ type Author struct {
 	Name string
 }
 
+// Interactive 文章的互动计数，以及当前访问者的点赞、收藏状态
+type Interactive struct {
+	ArticleId  int64
+	ReadCnt    int64
+	LikeCnt    int64
+	DislikeCnt int64
+	CollectCnt int64
+	CommentCnt int64
+	// UniqueReaderCnt 独立读者数的近似值，只在详情与作者统计中填充
+	UniqueReaderCnt int64
+	Liked           bool
+	Collected       bool
+	// Reaction 访问者对文章的表态，只在详情中填充
+	Reaction ReactionStatus
+}
+
+type CollectFolderVisibility uint8
+
+const (
+	CollectFolderUnknown CollectFolderVisibility = iota
+	CollectFolderPublic
+	CollectFolderPrivate
+)
+
+type CollectFolder struct {
+	Id          int64
+	UserId      int64
+	Name        string
+	Visibility  CollectFolderVisibility
+	ArticleCnt  int64
+	CreatedTime int64
+	UpdatedTime int64
+}
+
+// GenAbstract 返回文章摘要，发表时已生成摘要的直接返回，历史数据则根据正文即时生成
 func (a *Article) GenAbstract() string {
-	contentString := []rune(a.Content)
-	if len(contentString) < 100 {
-		a.Abstract = a.Content
+	if a.Abstract == "" {
+		a.Abstract = genAbstract(a.Content, a.Format)
+	}
+	return a.Abstract
+}
+
+func genAbstract(content string, format ContentFormat) string {
+	var plain string
+	if format == ContentFormatMarkdown {
+		plain = markdown.PlainText(content)
+	} else {
+		plain = markdown.CollapseSpace(content)
+	}
+	return markdown.Truncate(plain, abstractLength)
+}
+
+// renderContent 将正文渲染为读者端展示用的 HTML，纯文本按空行分段
+func renderContent(content string, format ContentFormat) (string, error) {
+	if format == ContentFormatMarkdown {
+		return markdown.Render(content)
+	}
+	var sb strings.Builder
+	for _, para := range strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n\n") {
+		para = strings.TrimSpace(para)
+		if para == "" {
+			continue
+		}
+		sb.WriteString("<p>")
+		sb.WriteString(strings.ReplaceAll(html.EscapeString(para), "\n", "<br>"))
+		sb.WriteString("</p>")
+	}
+	return sb.String(), nil
+}
+
+// ListCursor 游标分页的位置，Value 为排序字段（如 updated_time），Id 用于排序字段相同时的去重
+// 零值表示从第一页开始
+type ListCursor struct {
+	Value int64
+	Id    int64
+}
+
+func (c ListCursor) IsZero() bool {
+	return c.Value == 0 && c.Id == 0
+}
+
+// olderThan 按排序字段与 id 倒序排列时 c 是否排在 other 之后
+func (c ListCursor) olderThan(other ListCursor) bool {
+	return c.Value < other.Value || (c.Value == other.Value && c.Id < other.Id)
+}
+
+// EncodeCursor 将游标编码为对调用方不透明的字符串
+func EncodeCursor(c ListCursor) string {
+	if c.IsZero() {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%d", c.Value, c.Id)))
+}
+
+// DecodeCursor 解析 EncodeCursor 生成的游标，空字符串解析为零值游标
+func DecodeCursor(s string) (ListCursor, error) {
+	var c ListCursor
+	if s == "" {
+		return c, nil
+	}
+	bs, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, InvalidCursorErr
+	}
+	if _, err = fmt.Sscanf(string(bs), "%d:%d", &c.Value, &c.Id); err != nil || c.Id <= 0 {
+		return ListCursor{}, InvalidCursorErr
 	}
-	return string(contentString[:100])
+	return c, nil
 }
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"encoding/base64"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDecodeCursor(t *testing.T) {
+	encode := func(s string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(s))
+	}
+	testCases := []struct {
+		name    string
+		cursor  string
+		want    ListCursor
+		wantErr error
+	}{
+		{name: "空字符串为零值游标", cursor: ""},
+		{name: "与编码结果一致", cursor: EncodeCursor(ListCursor{Value: 1700000000000, Id: 42}),
+			want: ListCursor{Value: 1700000000000, Id: 42}},
+		{name: "不是 base64", cursor: "!!!", wantErr: InvalidCursorErr},
+		{name: "格式错误", cursor: encode("abc"), wantErr: InvalidCursorErr},
+		{name: "缺少 id", cursor: encode("1700000000000"), wantErr: InvalidCursorErr},
+		{name: "id 不为正数", cursor: encode("1700000000000:0"), wantErr: InvalidCursorErr},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, err := DecodeCursor(tc.cursor)
+			assert.ErrorIs(t, err, tc.wantErr)
+			assert.Equal(t, tc.want, c)
+		})
+	}
+}
+
+func TestEncodeCursor(t *testing.T) {
+	assert.Equal(t, "", EncodeCursor(ListCursor{}))
+}
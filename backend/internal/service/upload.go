@@ -0,0 +1,172 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"ibook/pkg/utils/thumbnail"
+	"image"
+	"io"
+	"net/http"
+)
+
+// MaxImageBytes 单张图片的大小上限
+const MaxImageBytes = 5 << 20
+
+var (
+	UploadTooLargeErr     = errors.New("上传文件超出大小限制")
+	UploadTypeInvalidErr  = errors.New("只支持 jpeg、png、gif 格式的图片")
+	UploadImageInvalidErr = errors.New("图片无法解析或尺寸过大")
+	BlobNotExistsErr      = errors.New("文件不存在")
+)
+
+type UploadKind uint8
+
+const (
+	UploadKindUnknown UploadKind = iota
+	// UploadKindAvatar 用户头像，缩略图为居中裁剪的正方形
+	UploadKindAvatar
+	// UploadKindArticleImage 文章配图，缩略图等比缩放到固定宽度
+	UploadKindArticleImage
+)
+
+type imageVariantSpec struct {
+	name string
+	// size 正方形的边长或等比缩放后的最大宽度
+	size   int
+	square bool
+}
+
+var imageVariantSpecs = map[UploadKind][]imageVariantSpec{
+	UploadKindAvatar: {
+		{name: "s48", size: 48, square: true},
+		{name: "s96", size: 96, square: true},
+		{name: "s256", size: 256, square: true},
+	},
+	UploadKindArticleImage: {
+		{name: "w320", size: 320},
+		{name: "w640", size: 640},
+		{name: "w1280", size: 1280},
+	},
+}
+
+var uploadKeyPrefixes = map[UploadKind]string{
+	UploadKindAvatar:       "images/avatar",
+	UploadKindArticleImage: "images/article",
+}
+
+type BlobInfo struct {
+	Size        int64
+	ContentType string
+}
+
+type ImageVariant struct {
+	Name   string
+	Width  int
+	Height int
+	Url    string
+}
+
+// UploadedImage 上传后的原图与缩略图，Url 由内容哈希生成，相同的图片总是得到相同的地址
+type UploadedImage struct {
+	Key         string
+	Url         string
+	ContentType string
+	Size        int64
+	Width       int
+	Height      int
+	Thumbnails  []*ImageVariant
+}
+
+type BlobRepo interface {
+	// Put 写入对象，key 已存在时覆盖
+	Put(ctx *gin.Context, key string, data []byte, contentType string) error
+	// Open 读取对象，调用方负责关闭，不存在时返回 BlobNotExistsErr
+	Open(ctx *gin.Context, key string) (io.ReadCloser, *BlobInfo, error)
+	// Url 生成对象的对外访问地址
+	Url(key string) string
+}
+
+type UploadService interface {
+	UploadImage(ctx *gin.Context, kind UploadKind, data []byte) (*UploadedImage, error)
+	OpenBlob(ctx *gin.Context, key string) (io.ReadCloser, *BlobInfo, error)
+}
+
+type uploadService struct {
+	br BlobRepo
+}
+
+func NewUploadService(br BlobRepo) UploadService {
+	return &uploadService{br: br}
+}
+
+// UploadImage 校验图片的实际格式与大小，生成缩略图后与原图一并写入对象存储
+// 对象 key 由内容哈希生成，重复上传同一张图片只会覆盖为相同的内容
+func (service *uploadService) UploadImage(ctx *gin.Context, kind UploadKind, data []byte) (*UploadedImage, error) {
+	specs, ok := imageVariantSpecs[kind]
+	if !ok {
+		return nil, fmt.Errorf("未知的上传类型: %d", kind)
+	}
+	if len(data) > MaxImageBytes {
+		return nil, UploadTooLargeErr
+	}
+	// 以文件内容判断类型，不信任客户端声明的 Content-Type 与扩展名
+	contentType := http.DetectContentType(data)
+	ext, ok := map[string]string{"image/jpeg": "jpg", "image/png": "png", "image/gif": "gif"}[contentType]
+	if !ok {
+		return nil, UploadTypeInvalidErr
+	}
+	img, format, err := thumbnail.Decode(data)
+	if err != nil {
+		return nil, UploadImageInvalidErr
+	}
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	base := fmt.Sprintf("%s/%s/%s", uploadKeyPrefixes[kind], hash[:2], hash)
+
+	res := &UploadedImage{
+		Key:         base + "." + ext,
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		Width:       img.Bounds().Dx(),
+		Height:      img.Bounds().Dy(),
+	}
+	// 先写缩略图再写原图，原图存在即说明缩略图都已写入
+	for _, spec := range specs {
+		var thumb image.Image
+		if spec.square {
+			thumb = thumbnail.Square(img, spec.size)
+		} else {
+			thumb = thumbnail.Fit(img, spec.size)
+		}
+		thumbData, thumbType, err := thumbnail.Encode(thumb, format)
+		if err != nil {
+			return nil, err
+		}
+		thumbExt := "png"
+		if thumbType == "image/jpeg" {
+			thumbExt = "jpg"
+		}
+		key := fmt.Sprintf("%s_%s.%s", base, spec.name, thumbExt)
+		if err = service.br.Put(ctx, key, thumbData, thumbType); err != nil {
+			return nil, err
+		}
+		res.Thumbnails = append(res.Thumbnails, &ImageVariant{
+			Name:   spec.name,
+			Width:  thumb.Bounds().Dx(),
+			Height: thumb.Bounds().Dy(),
+			Url:    service.br.Url(key),
+		})
+	}
+	if err = service.br.Put(ctx, res.Key, data, contentType); err != nil {
+		return nil, err
+	}
+	res.Url = service.br.Url(res.Key)
+	return res, nil
+}
+
+func (service *uploadService) OpenBlob(ctx *gin.Context, key string) (io.ReadCloser, *BlobInfo, error) {
+	return service.br.Open(ctx, key)
+}
@@ -5,8 +5,14 @@ import (
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"golang.org/x/crypto/bcrypt"
+	"ibook/internal/service/message/email"
 	"ibook/internal/service/message/sms"
 	"ibook/pkg/utils/randcode"
+	"net/url"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 )
 
 var (
@@ -17,37 +23,86 @@ var (
 	PasswordNotRightErr  = errors.New("密码不符")
 	UserNotInCacheErr    = errors.New("用户信息未被缓存")
 	UserUnKnownErr       = errors.New("用户服务未知错误")
+	NickNameExistsErr    = errors.New("该昵称已被使用")
+	NickNameCooldownErr  = errors.New("昵称修改过于频繁")
+	ProfileInvalidErr    = errors.New("个人资料不合法")
 )
 
+const (
+	// nickNameCooldown 两次修改昵称的最小间隔
+	nickNameCooldown = 30 * 24 * time.Hour
+	nickNameMaxLen   = 32
+	bioMaxLen        = 256
+	avatarUrlMaxLen  = 512
+	birthdayLayout   = time.DateOnly
+	birthdayEarliest = "1900-01-01"
+	// resetCodeLifeSeconds 重置密码验证码的有效时长
+	resetCodeLifeSeconds = 60 * 15
+)
+
+// UserProfileEdit 需要修改的个人资料，字段为 nil 表示不修改，Birthday 与 AvatarUrl 传空字符串表示清空
+type UserProfileEdit struct {
+	NickName  *string
+	Bio       *string
+	Birthday  *string
+	AvatarUrl *string
+}
+
 type UserRepo interface {
 	CreateUser(user *User) error
 	FindUserByEmail(email string) (*User, error)
 	FindUserById(userId int64) (*User, error)
 	FindUserByPhone(number string) (*User, error)
+	// UpdateProfile 只更新 edit 中不为 nil 的字段，修改昵称时同时记录修改时间，昵称重复时返回 NickNameExistsErr
+	UpdateProfile(ctx *gin.Context, userId int64, edit *UserProfileEdit) error
+	// UpdatePassword password 为加密后的密码
+	UpdatePassword(ctx *gin.Context, userId int64, password string) error
+}
+
+// UserTokenRepo 记录用户令牌的吊销时间，签发时间早于该时间的令牌不再有效
+type UserTokenRepo interface {
+	// RevokeTokens before 为 Unix 时间戳（毫秒）
+	RevokeTokens(ctx *gin.Context, userId int64, before int64) error
+	// RevokedBefore 没有吊销过令牌时返回 0
+	RevokedBefore(ctx *gin.Context, userId int64) (int64, error)
 }
 
 type UserCache interface {
 	GetUserById(ctx *gin.Context, userId int64) (*User, error)
 	SetUserById(ctx *gin.Context, user *User) error
+	DelUserById(ctx *gin.Context, userId int64) error
 }
 
 type UserService interface {
 	SignUp(ctx *gin.Context, email string, nickName string, password string, confirmPassword string) (*User, error)
 	Login(ctx *gin.Context, phone string, email string, password string) (*User, error)
-	Profile(ctx *gin.Context, userId int64) (*User, error)
+	// Profile 返回用户资料与关注计数，viewerId 为访问者，用于判断是否已关注该用户
+	Profile(ctx *gin.Context, userId int64, viewerId int64) (*User, *FollowStatistic, error)
+	EditProfile(ctx *gin.Context, userId int64, edit *UserProfileEdit) (*User, error)
+	Follow(ctx *gin.Context, followerId int64, followeeId int64) error
+	Unfollow(ctx *gin.Context, followerId int64, followeeId int64) error
+	ListFollowers(ctx *gin.Context, userId int64, cursor ListCursor, limit int64) ([]*FollowRelation, ListCursor, bool, error)
+	ListFollowees(ctx *gin.Context, userId int64, cursor ListCursor, limit int64) ([]*FollowRelation, ListCursor, bool, error)
 	SendLoginVerifyCode(ctx *gin.Context, phoneNumber string) error
 	LoginSMS(context *gin.Context, phoneNumber string, code string) (*User, error)
+	SendResetPasswordCode(ctx *gin.Context, emailAddr string) error
+	ResetPassword(ctx *gin.Context, emailAddr string, code string, password string, confirmPassword string) error
 }
 
 type userService struct {
 	ur   UserRepo
 	smsr sms.SMSRepo
+	er   email.EmailRepo
 	vcr  VerifyCodeRepo
 	uc   UserCache
+	tr   UserTokenRepo
+	fr   FollowRepo
+	fc   FollowCache
 }
 
-func NewUserService(ur UserRepo, uc UserCache, smsr sms.SMSRepo, vcr VerifyCodeRepo) UserService {
-	return &userService{ur: ur, uc: uc, smsr: smsr, vcr: vcr}
+func NewUserService(ur UserRepo, uc UserCache, smsr sms.SMSRepo, er email.EmailRepo, vcr VerifyCodeRepo, tr UserTokenRepo,
+	fr FollowRepo, fc FollowCache) UserService {
+	return &userService{ur: ur, uc: uc, smsr: smsr, er: er, vcr: vcr, tr: tr, fr: fr, fc: fc}
 }
 
 func (s *userService) SignUp(ctx *gin.Context, email string, nickName string, password string, confirmPassword string) (*User, error) {
@@ -99,7 +154,20 @@ func (s *userService) Login(ctx *gin.Context, phone string, email string, passwo
 	return user, nil
 }
 
-func (s *userService) Profile(ctx *gin.Context, userId int64) (*User, error) {
+func (s *userService) Profile(ctx *gin.Context, userId int64, viewerId int64) (*User, *FollowStatistic, error) {
+	user, err := s.getUser(ctx, userId)
+	if err != nil {
+		return nil, nil, err
+	}
+	stat, err := s.getFollowStatistic(ctx, userId, viewerId)
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, stat, nil
+}
+
+// getUser 先查缓存，未命中时从 repo 中查找并写回缓存，返回的用户不包含密码
+func (s *userService) getUser(ctx *gin.Context, userId int64) (*User, error) {
 	userCache, err := s.uc.GetUserById(ctx, userId)
 	if err == nil {
 		userCache.PassWord = ""
@@ -126,6 +194,82 @@ func (s *userService) Profile(ctx *gin.Context, userId int64) (*User, error) {
 	return user, nil
 }
 
+// EditProfile 校验并修改个人资料，成功后删除用户信息缓存，返回修改后的资料
+func (s *userService) EditProfile(ctx *gin.Context, userId int64, edit *UserProfileEdit) (*User, error) {
+	if err := normalizeProfileEdit(edit); err != nil {
+		return nil, err
+	}
+	user, err := s.ur.FindUserById(userId)
+	if err != nil {
+		return nil, err
+	}
+	// 昵称未变化时不计入修改次数
+	if edit.NickName != nil && *edit.NickName == user.NickName {
+		edit.NickName = nil
+	}
+	if edit.NickName != nil && user.NickNameUpdatedTime > 0 &&
+		time.Since(time.UnixMilli(user.NickNameUpdatedTime)) < nickNameCooldown {
+		return nil, NickNameCooldownErr
+	}
+	if edit.NickName == nil && edit.Bio == nil && edit.Birthday == nil && edit.AvatarUrl == nil {
+		user.PassWord = ""
+		return user, nil
+	}
+	if err = s.ur.UpdateProfile(ctx, userId, edit); err != nil {
+		return nil, err
+	}
+	// 缓存删除失败时旧资料最多保留到缓存过期，不影响本次修改
+	_ = s.uc.DelUserById(ctx, userId)
+	return s.getUser(ctx, userId)
+}
+
+// normalizeProfileEdit 去除首尾空白并校验各字段
+func normalizeProfileEdit(edit *UserProfileEdit) error {
+	if edit == nil {
+		return ProfileInvalidErr
+	}
+	if edit.NickName != nil {
+		nickName := strings.TrimSpace(*edit.NickName)
+		if nickName == "" || utf8.RuneCountInString(nickName) > nickNameMaxLen ||
+			strings.IndexFunc(nickName, unicode.IsControl) >= 0 {
+			return fmt.Errorf("%w: 昵称不能为空、不能超过 %d 个字符且不能包含控制字符", ProfileInvalidErr, nickNameMaxLen)
+		}
+		edit.NickName = &nickName
+	}
+	if edit.Bio != nil {
+		bio := strings.TrimSpace(*edit.Bio)
+		if utf8.RuneCountInString(bio) > bioMaxLen {
+			return fmt.Errorf("%w: 简介不能超过 %d 个字符", ProfileInvalidErr, bioMaxLen)
+		}
+		edit.Bio = &bio
+	}
+	if edit.Birthday != nil {
+		birthday := strings.TrimSpace(*edit.Birthday)
+		if birthday != "" {
+			t, err := time.ParseInLocation(birthdayLayout, birthday, time.Local)
+			// 同为 2006-01-02 格式，可直接按字符串比较
+			if err != nil || birthday < birthdayEarliest || t.After(time.Now()) {
+				return fmt.Errorf("%w: 生日格式应为 YYYY-MM-DD，且不能早于 %s 或晚于今天", ProfileInvalidErr, birthdayEarliest)
+			}
+		}
+		edit.Birthday = &birthday
+	}
+	if edit.AvatarUrl != nil {
+		avatarUrl := strings.TrimSpace(*edit.AvatarUrl)
+		if avatarUrl != "" {
+			// 上传接口在未配置外部访问地址时返回 /blobs/... 形式的站内地址
+			u, err := url.Parse(avatarUrl)
+			absolute := err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+			sitePath := err == nil && u.Scheme == "" && u.Host == "" && strings.HasPrefix(avatarUrl, "/")
+			if len(avatarUrl) > avatarUrlMaxLen || !absolute && !sitePath {
+				return fmt.Errorf("%w: 头像地址应为不超过 %d 个字符的 http(s) 链接或站内地址", ProfileInvalidErr, avatarUrlMaxLen)
+			}
+		}
+		edit.AvatarUrl = &avatarUrl
+	}
+	return nil
+}
+
 func (s *userService) SendLoginVerifyCode(ctx *gin.Context, phoneNumber string) error {
 	code := randcode.GenVerifyCode(6, randcode.TYPE_MIXED)
 	phoneNumbers := []string{phoneNumber}
@@ -1,6 +1,7 @@
 package web
 
 import (
+	"errors"
 	"github.com/gin-gonic/gin"
 	"ibook/internal/service"
 	"ibook/pkg/utils/bskit/slice"
@@ -16,12 +17,14 @@ const handlerName = "ArticleHandler"
 
 type ArticleHandler struct {
 	svc    service.ArticleService
+	csvc   service.CommentService
 	logger logger.Logger
 }
 
-func NewArticleHandler(svc service.ArticleService, myLogger logger.Logger) *ArticleHandler {
+func NewArticleHandler(svc service.ArticleService, csvc service.CommentService, myLogger logger.Logger) *ArticleHandler {
 	return &ArticleHandler{
 		svc:    svc,
+		csvc:   csvc,
 		logger: myLogger,
 	}
 }
@@ -32,10 +35,36 @@ func (handler *ArticleHandler) RegisterRoutesV1(server *gin.Engine) {
 	ug.POST("/edit", handler.Edit)
 	ug.POST("/publish", handler.Publish)
 	ug.POST("/withdraw", handler.Withdraw)
+	ug.POST("/delete", handler.Delete)
+	ug.GET("/recycle/list", handler.ListRecycleBin)
+	ug.POST("/recycle/restore", handler.RestoreDeleted)
 	ug.GET("/pub/list", handler.PubList)
 	ug.GET("/pub/detail/:id", handler.PubDetail)
+	ug.GET("/pub/tags", handler.TagCounts)
+	ug.GET("/pub/hot", handler.HotList)
+	ug.GET("/stats", handler.AuthorStats)
 	ug.GET("/detail/:id", handler.Detail)
 	ug.POST("/pub/like", handler.Like)
+	ug.GET("/pub/:id/likers", handler.Likers)
+	ug.POST("/pub/collect", handler.Collect)
+	ug.POST("/pub/comment", handler.CreateComment)
+	ug.POST("/pub/comment/delete", handler.DeleteComment)
+	ug.POST("/pub/comment/like", handler.LikeComment)
+	ug.GET("/pub/comments", handler.ListComments)
+	ug.GET("/pub/comment/:id/replies", handler.ListReplies)
+	ug.POST("/collect/folder/create", handler.CreateCollectFolder)
+	ug.GET("/collect/folders", handler.ListCollectFolders)
+	ug.GET("/collect/folder/:id/articles", handler.ListCollectFolderArticles)
+	ug.GET("/revision/list", handler.ListRevisions)
+	ug.GET("/revision/detail/:id", handler.RevisionDetail)
+	ug.GET("/revision/diff", handler.DiffRevisions)
+	ug.POST("/revision/restore", handler.RestoreRevision)
+	ug.GET("/schedule/list", handler.ListSchedules)
+	ug.POST("/schedule/update", handler.UpdateSchedule)
+	ug.POST("/schedule/cancel", handler.CancelSchedule)
+	// 当前用户维度的文章列表挂在 /users 下
+	server.GET("/users/me/likes", handler.MyLikes)
+	server.GET("/feed", handler.Feed)
 }
 
 func (handler *ArticleHandler) Edit(ctx *gin.Context) {
@@ -54,17 +83,46 @@ func (handler *ArticleHandler) Edit(ctx *gin.Context) {
 			Value: nil,
 		})
 		result.RespWithError(ctx, result.UNKNOWN_ERROR_CODE, "用户未登录", nil)
+		return
+	}
+	// 编辑或发表已有文章时必须带上获取草稿时的版本号，避免覆盖别处的修改
+	if req.Id > 0 && req.Version <= 0 {
+		result.RespWithError(ctx, result.PARAM_NOT_EQUAL_CODE, "编辑已有文章需传入版本号", nil)
+		return
+	}
+	format, ok := parseContentFormat(req.Format)
+	if !ok {
+		result.RespWithError(ctx, result.PARAM_FORMAT_ERROR_CODE, "正文格式只能为 markdown 或 plain", nil)
+		return
 	}
 	article := &service.Article{
-		Id:      req.Id,
-		Title:   req.Title,
-		Content: req.Content,
+		Id:       req.Id,
+		Title:    req.Title,
+		Content:  req.Content,
+		Format:   format,
+		Category: req.Category,
+		Tags:     req.Tags,
 		Author: service.Author{
 			Id: userId.(int64),
 		},
+		Version: req.Version,
 	}
 	err := handler.svc.EditArticle(ctx, article)
 	if err != nil {
+		if errors.Is(err, service.ArticleTagInvalidErr) || errors.Is(err, service.ArticleCategoryInvalidErr) {
+			result.RespWithError(ctx, result.PARAM_FORMAT_ERROR_CODE, "最多 5 个标签，标签与分类不超过 32 个字符", nil)
+			return
+		}
+		// 版本冲突时返回服务端当前的草稿，由前端提示用户合并
+		var conflict *service.ArticleConflictError
+		if errors.As(err, &conflict) {
+			result.RespWithError(ctx, result.VERSION_CONFLICT_CODE, "文章已在别处被修改", toGetArticleReply(conflict.Current))
+			return
+		}
+		if errors.Is(err, service.ArticleVersionConflictErr) {
+			result.RespWithError(ctx, result.VERSION_CONFLICT_CODE, "文章已在别处被修改", nil)
+			return
+		}
 		result.RespWithError(ctx, result.UNKNOWN_ERROR_CODE, "未知错误", err)
 		return
 	}
@@ -73,6 +131,7 @@ func (handler *ArticleHandler) Edit(ctx *gin.Context) {
 		Title: article.Title,
 		// Content:  article.Content,
 		AuthorId: article.Author.Id,
+		Version:  article.Version,
 	})
 }
 
@@ -92,23 +151,63 @@ func (handler *ArticleHandler) Publish(ctx *gin.Context) {
 			Value: nil,
 		})
 		result.RespWithError(ctx, result.UNKNOWN_ERROR_CODE, "用户未登录", nil)
+		return
+	}
+	// 编辑或发表已有文章时必须带上获取草稿时的版本号，避免覆盖别处的修改
+	if req.Id > 0 && req.Version <= 0 {
+		result.RespWithError(ctx, result.PARAM_NOT_EQUAL_CODE, "编辑已有文章需传入版本号", nil)
+		return
+	}
+	format, ok := parseContentFormat(req.Format)
+	if !ok {
+		result.RespWithError(ctx, result.PARAM_FORMAT_ERROR_CODE, "正文格式只能为 markdown 或 plain", nil)
+		return
 	}
 	article := &service.Article{
-		Id:      req.Id,
-		Title:   req.Title,
-		Content: req.Content,
+		Id:       req.Id,
+		Title:    req.Title,
+		Content:  req.Content,
+		Format:   format,
+		Category: req.Category,
+		Tags:     req.Tags,
 		Author: service.Author{
 			Id: userId.(int64),
 		},
+		Version: req.Version,
 	}
-	err := handler.svc.PublishArticle(ctx, article)
+	schedule := service.PublishSchedule{PublishAt: req.PublishAt, ExpireAt: req.ExpireAt}
+	err := handler.svc.PublishArticle(ctx, article, schedule)
 	if err != nil {
+		if errors.Is(err, service.ScheduleTimeInvalidErr) {
+			result.RespWithError(ctx, result.PARAM_FORMAT_ERROR_CODE, "撤回时间需晚于当前时间及发表时间", nil)
+			return
+		}
+		if errors.Is(err, service.ArticleTagInvalidErr) || errors.Is(err, service.ArticleCategoryInvalidErr) {
+			result.RespWithError(ctx, result.PARAM_FORMAT_ERROR_CODE, "最多 5 个标签，标签与分类不超过 32 个字符", nil)
+			return
+		}
+		var conflict *service.ArticleConflictError
+		if errors.As(err, &conflict) {
+			result.RespWithError(ctx, result.VERSION_CONFLICT_CODE, "文章已在别处被修改", toGetArticleReply(conflict.Current))
+			return
+		}
+		if errors.Is(err, service.ArticleVersionConflictErr) {
+			result.RespWithError(ctx, result.VERSION_CONFLICT_CODE, "文章已在别处被修改", nil)
+			return
+		}
 		result.RespWithError(ctx, result.UNKNOWN_ERROR_CODE, "未知错误", nil)
 		return
 	}
-	result.RespWithSuccess(ctx, "发布成功", &ArticlePublishReply{
-		Id: article.Id,
-		OK: true,
+	scheduled := req.PublishAt > time.Now().UnixMilli()
+	msg := "发布成功"
+	if scheduled {
+		msg = "已设置定时发表"
+	}
+	result.RespWithSuccess(ctx, msg, &ArticlePublishReply{
+		Id:        article.Id,
+		OK:        true,
+		Version:   article.Version,
+		Scheduled: scheduled,
 	})
 
 }
@@ -131,6 +230,7 @@ func (handler *ArticleHandler) Withdraw(ctx *gin.Context) {
 			Value: nil,
 		})
 		result.RespWithError(ctx, result.UNKNOWN_ERROR_CODE, "用户未登录", nil)
+		return
 	}
 	err := handler.svc.WithDrawArticle(ctx, req.Id, userId.(int64))
 	if err != nil {
@@ -158,32 +258,84 @@ func (handler *ArticleHandler) PubList(ctx *gin.Context) {
 			Value: nil,
 		})
 		result.RespWithError(ctx, result.UNKNOWN_ERROR_CODE, "用户未登录", nil)
+		return
 	}
-	articles, err := handler.svc.ListPubArticles(ctx, req.AuthorId, req.Offset, req.Limit)
+	if req.Limit <= 0 || req.Limit > 100 {
+		req.Limit = 20
+	}
+	cursor, err := service.DecodeCursor(req.Cursor)
+	if err != nil {
+		result.RespWithError(ctx, result.PARAM_FORMAT_ERROR_CODE, "分页游标不合法", nil)
+		return
+	}
+	filter := service.ArticleListFilter{
+		AuthorId: req.AuthorId,
+		Tag:      service.NormalizeTag(req.Tag),
+		Category: strings.TrimSpace(req.Category),
+	}
+	articles, next, hasMore, err := handler.svc.ListPubArticles(ctx, filter, cursor, req.Limit)
 	if err != nil {
 		l.Warn("获取文章列表失败", logger.Field{
 			Key:   "错误详情",
 			Value: err,
 		})
+		result.RespWithError(ctx, result.UNKNOWN_ERROR_CODE, "文章列表获取失败", nil)
+		return
 	}
 	result.RespWithSuccess(ctx, "获取成功",
 		&ArticleListReply{
 			Articles: slice.Map[*service.Article, *Article](articles, func(idx int, src *service.Article) *Article {
-				return &Article{
-					Id:          src.Id,
-					Title:       src.Title,
-					Abstract:    src.GenAbstract(),
-					Status:      uint8(src.Status),
-					AuthorId:    src.Author.Id,
-					AuthorName:  src.Author.Name,
-					UpdatedTime: time.Unix(src.UpdatedTime, 0).Local().Format(time.DateTime),
-					CreatedTime: time.Unix(src.CreatedTime, 0).Local().Format(time.DateTime),
-				}
+				return toArticleVO(src)
 			}),
+			NextCursor: service.EncodeCursor(next),
+			HasMore:    hasMore,
 		},
 	)
 }
 
+// Feed 当前用户关注的作者发表的文章，按发表时间倒序
+func (handler *ArticleHandler) Feed(ctx *gin.Context) {
+	l := logger.TagCtxLogger(ctx, handler.logger, "ArticleHandler-Feed")
+	req := &FeedReq{}
+	if err := request.ParseRequestBody(ctx, req); err != nil {
+		result.RespWithError(ctx, result.PARAM_NOT_EQUAL_CODE, "请求传参或设置有误", nil)
+		return
+	}
+	if req.Limit <= 0 || req.Limit > 50 {
+		req.Limit = 20
+	}
+	cursor, err := service.DecodeCursor(req.Cursor)
+	if err != nil {
+		result.RespWithError(ctx, result.PARAM_FORMAT_ERROR_CODE, "分页游标不合法", nil)
+		return
+	}
+	userId, exists := ctx.Get("userId")
+	if !exists || userId.(int64) <= 0 {
+		l.Error("未成功从 token 提取 userId", logger.Field{
+			Key:   "token错误",
+			Value: nil,
+		})
+		result.RespWithError(ctx, result.UNKNOWN_ERROR_CODE, "用户未登录", nil)
+		return
+	}
+	articles, next, hasMore, err := handler.svc.ListFeed(ctx, userId.(int64), cursor, req.Limit)
+	if err != nil {
+		l.Warn("获取关注流失败", logger.Field{
+			Key:   "详情",
+			Value: err,
+		})
+		result.RespWithError(ctx, result.UNKNOWN_ERROR_CODE, "关注流获取失败", nil)
+		return
+	}
+	result.RespWithSuccess(ctx, "获取成功", &ArticleListReply{
+		Articles: slice.Map[*service.Article, *Article](articles, func(idx int, src *service.Article) *Article {
+			return toArticleVO(src)
+		}),
+		NextCursor: service.EncodeCursor(next),
+		HasMore:    hasMore,
+	})
+}
+
 func (handler *ArticleHandler) PubDetail(ctx *gin.Context) {
 	articleId := ctx.Param("id")
 	if articleId == "" {
@@ -196,11 +348,27 @@ func (handler *ArticleHandler) PubDetail(ctx *gin.Context) {
 		return
 	}
 	l := logger.TagCtxLogger(ctx, handler.logger, "ArticleHandler-PubDetail")
-	// 从缓存中查找文章详情
+	// 未登录的访问者 viewerId 为 0，此时不查询点赞、收藏状态
+	viewerId := ctx.GetInt64("userId")
+	article, inter, err := handler.svc.GetPubArticleDetail(ctx, int64(id), viewerId)
+	if err != nil {
+		if errors.Is(err, service.ArticleNotExistsErr) {
+			result.RespWithError(ctx, result.RECORD_DO_NOT_EXISTS_CODE, "文章不存在", nil)
+			return
+		}
+		l.Warn("获取文章详情失败", logger.Field{
+			Key:   "详情",
+			Value: err,
+		})
+		result.RespWithError(ctx, result.UNKNOWN_ERROR_CODE, "文章获取失败", nil)
+		return
+	}
 
-	//增加计数
+	//增加计数，ctx 在请求结束后会被 gin 回收，异步使用时需拷贝
+	cCtx := ctx.Copy()
+	viewer := readerIdentity(ctx, viewerId)
 	go func() {
-		err := handler.svc.IncrReadCount(ctx, int64(id))
+		err := handler.svc.IncrReadCount(cCtx, int64(id), viewer)
 		if err != nil {
 			// 打日志
 			l.Warn("异步增加文章阅读计数时出错：", logger.Field{
@@ -209,13 +377,43 @@ func (handler *ArticleHandler) PubDetail(ctx *gin.Context) {
 			})
 		}
 	}()
+	result.RespWithSuccess(ctx, "获取成功", &PubArticleDetailReply{
+		Id:              article.Id,
+		Title:           article.Title,
+		Content:         article.Content,
+		Format:          contentFormatName(article.Format),
+		HTML:            article.HTML,
+		Category:        article.Category,
+		Tags:            article.Tags,
+		AuthorId:        article.Author.Id,
+		AuthorName:      article.Author.Name,
+		ReadCnt:         inter.ReadCnt,
+		UniqueReaderCnt: inter.UniqueReaderCnt,
+		LikeCnt:         inter.LikeCnt,
+		DislikeCnt:      inter.DislikeCnt,
+		CollectCnt:      inter.CollectCnt,
+		CommentCnt:      inter.CommentCnt,
+		Liked:           inter.Liked,
+		Reaction:        inter.Reaction.String(),
+		Collected:       inter.Collected,
+		CreatedTime:     time.UnixMilli(article.CreatedTime).Local().Format(time.DateTime),
+		UpdatedTime:     time.UnixMilli(article.UpdatedTime).Local().Format(time.DateTime),
+	})
+}
+
+// readerIdentity 阅读去重与独立读者统计使用的访问者标识，未登录时按 IP 区分
+func readerIdentity(ctx *gin.Context, viewerId int64) string {
+	if viewerId > 0 {
+		return "u:" + strconv.FormatInt(viewerId, 10)
+	}
+	return "ip:" + ctx.ClientIP()
 }
 
 func (handler *ArticleHandler) Detail(context *gin.Context) {
 	l := logger.TagCtxLogger(context, handler.logger, "ArticleHandler-Detail")
 	id := context.Param("id")
 	articleId, err := strconv.ParseInt(id, 10, 64)
-	if err == nil {
+	if err != nil || articleId <= 0 {
 		result.RespWithError(context, result.PARAM_NOT_EQUAL_CODE, "请求传参或设置有误", nil)
 		return
 	}
@@ -226,6 +424,7 @@ func (handler *ArticleHandler) Detail(context *gin.Context) {
 			Value: nil,
 		})
 		result.RespWithError(context, result.UNKNOWN_ERROR_CODE, "用户未登录", nil)
+		return
 	}
 
 	// 去制作库中获取数据
@@ -238,20 +437,131 @@ func (handler *ArticleHandler) Detail(context *gin.Context) {
 		result.RespWithError(context, result.UNKNOWN_ERROR_CODE, "文章获取失败", nil)
 		return
 	}
-	result.RespWithSuccess(context, "获取成功", &GetArticleReply{
-		Id:      article.Id,
-		Title:   article.Title,
-		Content: article.Content,
+	result.RespWithSuccess(context, "获取成功", toGetArticleReply(article))
+}
+
+func toGetArticleReply(article *service.Article) *GetArticleReply {
+	return &GetArticleReply{
+		Id:       article.Id,
+		Title:    article.Title,
+		Content:  article.Content,
+		Format:   contentFormatName(article.Format),
+		Category: article.Category,
+		Tags:     article.Tags,
+		Version:  article.Version,
+	}
+}
+
+func (handler *ArticleHandler) TagCounts(ctx *gin.Context) {
+	l := logger.TagCtxLogger(ctx, handler.logger, "ArticleHandler-TagCounts")
+	req := &TagCountsReq{}
+	if err := request.ParseRequestBody(ctx, req); err != nil {
+		result.RespWithError(ctx, result.PARAM_NOT_EQUAL_CODE, "请求传参或设置有误", nil)
+		return
+	}
+	if req.Limit <= 0 || req.Limit > 100 {
+		req.Limit = 50
+	}
+	counts, err := handler.svc.ListTagCounts(ctx, req.Limit)
+	if err != nil {
+		l.Warn("获取标签统计失败", logger.Field{
+			Key:   "详情",
+			Value: err,
+		})
+		result.RespWithError(ctx, result.UNKNOWN_ERROR_CODE, "服务内部错误", nil)
+		return
+	}
+	result.RespWithSuccess(ctx, "获取成功", &TagCountsReply{
+		Tags: slice.Map[*service.TagCount, *TagCount](counts, func(idx int, src *service.TagCount) *TagCount {
+			return &TagCount{Name: src.Name, ArticleCnt: src.ArticleCnt}
+		}),
 	})
 }
 
+// HotList 按热度分页获取已发表文章
+func (handler *ArticleHandler) HotList(ctx *gin.Context) {
+	l := logger.TagCtxLogger(ctx, handler.logger, "ArticleHandler-HotList")
+	req := &HotListReq{}
+	if err := request.ParseRequestBody(ctx, req); err != nil {
+		result.RespWithError(ctx, result.PARAM_NOT_EQUAL_CODE, "请求传参或设置有误", nil)
+		return
+	}
+	if req.Limit <= 0 || req.Limit > 100 {
+		req.Limit = 20
+	}
+	if req.Offset < 0 {
+		req.Offset = 0
+	}
+	articles, hasMore, err := handler.svc.ListHotArticles(ctx, req.Offset, req.Limit)
+	if err != nil {
+		l.Warn("获取热榜失败", logger.Field{
+			Key:   "详情",
+			Value: err,
+		})
+		result.RespWithError(ctx, result.UNKNOWN_ERROR_CODE, "服务内部错误", nil)
+		return
+	}
+	result.RespWithSuccess(ctx, "获取成功", &ArticleListReply{
+		Articles: slice.Map[*service.Article, *Article](articles, func(idx int, src *service.Article) *Article {
+			return toArticleVO(src)
+		}),
+		HasMore: hasMore,
+	})
+}
+
+func toArticleVO(src *service.Article) *Article {
+	vo := &Article{
+		Id:          src.Id,
+		Title:       src.Title,
+		Abstract:    src.GenAbstract(),
+		Status:      uint8(src.Status),
+		Category:    src.Category,
+		Tags:        src.Tags,
+		AuthorId:    src.Author.Id,
+		AuthorName:  src.Author.Name,
+		UpdatedTime: time.UnixMilli(src.UpdatedTime).Local().Format(time.DateTime),
+		CreatedTime: time.UnixMilli(src.CreatedTime).Local().Format(time.DateTime),
+	}
+	if src.Interactive != nil {
+		vo.ReadCnt = src.Interactive.ReadCnt
+		vo.LikeCnt = src.Interactive.LikeCnt
+		vo.DislikeCnt = src.Interactive.DislikeCnt
+		vo.CollectCnt = src.Interactive.CollectCnt
+		vo.CommentCnt = src.Interactive.CommentCnt
+	}
+	return vo
+}
+
+func parseContentFormat(format string) (service.ContentFormat, bool) {
+	switch format {
+	case "", "plain":
+		return service.ContentFormatPlain, true
+	case "markdown":
+		return service.ContentFormatMarkdown, true
+	default:
+		return service.ContentFormatUnknown, false
+	}
+}
+
+func contentFormatName(format service.ContentFormat) string {
+	if format == service.ContentFormatMarkdown {
+		return "markdown"
+	}
+	return "plain"
+}
+
 func (handler *ArticleHandler) Like(ctx *gin.Context) {
 	l := logger.TagCtxLogger(ctx, handler.logger, "ArticleHandler-Like")
-	var req *LikeArticleReq
-	if err := request.ParseRequestBody(ctx, req); err != nil {
+	req := &LikeArticleReq{}
+	if err := request.ParseRequestBody(ctx, req); err != nil || req.ArticleId <= 0 {
 		result.RespWithError(ctx, result.PARAM_NOT_EQUAL_CODE, "请求传参或设置有误", nil)
 		return
 	}
+	status, err := parseReaction(req)
+	if err != nil {
+		result.RespWithError(ctx, result.PARAM_NOT_EQUAL_CODE, "表态只能为 like、normal 或 dislike", nil)
+		return
+	}
 	userId, exists := ctx.Get("userId")
 	if !exists || userId.(int64) < 0 {
 		l.Error("未成功从 token 提取 userId", logger.Field{
@@ -259,29 +569,216 @@ func (handler *ArticleHandler) Like(ctx *gin.Context) {
 			Value: nil,
 		})
 		result.RespWithError(ctx, result.UNKNOWN_ERROR_CODE, "用户未登录", nil)
+		return
+	}
+	prev, err := handler.svc.ReactArticle(ctx, userId.(int64), req.ArticleId, status)
+	if err != nil {
+		if errors.Is(err, service.ArticleNotExistsErr) {
+			result.RespWithError(ctx, result.RECORD_DO_NOT_EXISTS_CODE, "文章不存在", nil)
+			return
+		}
+		l.Warn("用户赞/踩文章失败", logger.Field{
+			Key:   "详情",
+			Value: err,
+		})
+		result.RespWithError(ctx, result.UNKNOWN_ERROR_CODE, "服务内部错误", nil)
+		return
+	}
+	result.RespWithSuccess(ctx, "操作成功", &LikeArticleReply{
+		OK:             true,
+		PreviousStatus: prev.String(),
+		CurrentStatus:  status.String(),
+		Changed:        prev != status,
+	})
+}
+
+// parseReaction 未传 status 时兼容旧版本的 like 字段
+func parseReaction(req *LikeArticleReq) (service.ReactionStatus, error) {
+	if req.Status == "" {
+		if req.Like == 1 {
+			return service.ReactionLike, nil
+		}
+		return service.ReactionNormal, nil
+	}
+	return service.ParseReactionStatus(req.Status)
+}
+
+func (handler *ArticleHandler) Collect(ctx *gin.Context) {
+	l := logger.TagCtxLogger(ctx, handler.logger, "ArticleHandler-Collect")
+	req := &CollectArticleReq{}
+	if err := request.ParseRequestBody(ctx, req); err != nil || req.ArticleId <= 0 {
+		result.RespWithError(ctx, result.PARAM_NOT_EQUAL_CODE, "请求传参或设置有误", nil)
+		return
+	}
+	userId, exists := ctx.Get("userId")
+	if !exists || userId.(int64) < 0 {
+		l.Error("未成功从 token 提取 userId", logger.Field{
+			Key:   "token错误",
+			Value: nil,
+		})
+		result.RespWithError(ctx, result.UNKNOWN_ERROR_CODE, "用户未登录", nil)
+		return
 	}
-	// 本次标记为喜欢
 	var err error
-	var status string
-	if req.Like == 1 {
-		err = handler.svc.LikeArticle(ctx, userId.(int64), req.ArticleId)
-		status = "like"
+	if req.Collect == 1 {
+		err = handler.svc.CollectArticle(ctx, userId.(int64), req.ArticleId, req.FolderId)
 	} else {
-		err = handler.svc.CancelLikeArticle(ctx, userId.(int64), req.ArticleId)
-		status = "normal"
+		err = handler.svc.CancelCollectArticle(ctx, userId.(int64), req.ArticleId)
 	}
 	if err != nil {
-		l.Warn("用户赞/取消赞文章失败", logger.Field{
+		switch {
+		case errors.Is(err, service.ArticleNotExistsErr):
+			result.RespWithError(ctx, result.RECORD_DO_NOT_EXISTS_CODE, "文章不存在", nil)
+		case errors.Is(err, service.CollectFolderNotExistsErr):
+			result.RespWithError(ctx, result.RECORD_DO_NOT_EXISTS_CODE, "收藏夹不存在", nil)
+		default:
+			l.Warn("用户收藏/取消收藏文章失败", logger.Field{
+				Key:   "详情",
+				Value: err,
+			})
+			result.RespWithError(ctx, result.UNKNOWN_ERROR_CODE, "服务内部错误", nil)
+		}
+		return
+	}
+	result.RespWithSuccess(ctx, "操作成功", &CollectArticleReply{
+		OK:        true,
+		Collected: req.Collect == 1,
+	})
+}
+
+func (handler *ArticleHandler) CreateCollectFolder(ctx *gin.Context) {
+	l := logger.TagCtxLogger(ctx, handler.logger, "ArticleHandler-CreateCollectFolder")
+	req := &CreateCollectFolderReq{}
+	if err := request.ParseRequestBody(ctx, req); err != nil {
+		result.RespWithError(ctx, result.PARAM_NOT_EQUAL_CODE, "请求传参或设置有误", nil)
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || len([]rune(req.Name)) > 64 {
+		result.RespWithError(ctx, result.PARAM_FORMAT_ERROR_CODE, "收藏夹名称不能为空且不超过 64 个字符", nil)
+		return
+	}
+	userId, exists := ctx.Get("userId")
+	if !exists || userId.(int64) < 0 {
+		l.Error("未成功从 token 提取 userId", logger.Field{
+			Key:   "token错误",
+			Value: nil,
+		})
+		result.RespWithError(ctx, result.UNKNOWN_ERROR_CODE, "用户未登录", nil)
+		return
+	}
+	folder := &service.CollectFolder{
+		UserId:     userId.(int64),
+		Name:       req.Name,
+		Visibility: service.CollectFolderPrivate,
+	}
+	if req.Public {
+		folder.Visibility = service.CollectFolderPublic
+	}
+	if err := handler.svc.CreateCollectFolder(ctx, folder); err != nil {
+		if errors.Is(err, service.CollectFolderAlreadyExistsErr) {
+			result.RespWithError(ctx, result.RECORD_ALREADY_EXISTS_CODE, "同名收藏夹已存在", nil)
+			return
+		}
+		l.Warn("创建收藏夹失败", logger.Field{
 			Key:   "详情",
 			Value: err,
 		})
-		result.RespWithError(ctx, result.PARAM_NOT_EQUAL_CODE, "服务内部错误", nil)
+		result.RespWithError(ctx, result.UNKNOWN_ERROR_CODE, "服务内部错误", nil)
 		return
 	}
+	result.RespWithSuccess(ctx, "创建成功", &CreateCollectFolderReply{Id: folder.Id})
+}
 
-	result.RespWithSuccess(ctx, "操作成功", &LikeArticleReply{
-		OK:            true,
-		CurrentStatus: status,
+func (handler *ArticleHandler) ListCollectFolders(ctx *gin.Context) {
+	l := logger.TagCtxLogger(ctx, handler.logger, "ArticleHandler-ListCollectFolders")
+	req := &ListCollectFoldersReq{}
+	if err := request.ParseRequestBody(ctx, req); err != nil {
+		result.RespWithError(ctx, result.PARAM_NOT_EQUAL_CODE, "请求传参或设置有误", nil)
+		return
+	}
+	userId, exists := ctx.Get("userId")
+	if !exists || userId.(int64) < 0 {
+		l.Error("未成功从 token 提取 userId", logger.Field{
+			Key:   "token错误",
+			Value: nil,
+		})
+		result.RespWithError(ctx, result.UNKNOWN_ERROR_CODE, "用户未登录", nil)
+		return
+	}
+	ownerId := req.UserId
+	if ownerId <= 0 {
+		ownerId = userId.(int64)
+	}
+	folders, err := handler.svc.ListCollectFolders(ctx, ownerId, userId.(int64))
+	if err != nil {
+		l.Warn("获取收藏夹列表失败", logger.Field{
+			Key:   "详情",
+			Value: err,
+		})
+		result.RespWithError(ctx, result.UNKNOWN_ERROR_CODE, "服务内部错误", nil)
+		return
+	}
+	result.RespWithSuccess(ctx, "获取成功", &ListCollectFoldersReply{
+		Folders: slice.Map[*service.CollectFolder, *CollectFolder](folders, func(idx int, src *service.CollectFolder) *CollectFolder {
+			return &CollectFolder{
+				Id:          src.Id,
+				UserId:      src.UserId,
+				Name:        src.Name,
+				Public:      src.Visibility == service.CollectFolderPublic,
+				ArticleCnt:  src.ArticleCnt,
+				CreatedTime: time.UnixMilli(src.CreatedTime).Local().Format(time.DateTime),
+			}
+		}),
 	})
+}
 
+func (handler *ArticleHandler) ListCollectFolderArticles(ctx *gin.Context) {
+	l := logger.TagCtxLogger(ctx, handler.logger, "ArticleHandler-ListCollectFolderArticles")
+	folderId, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil || folderId <= 0 {
+		result.RespWithError(ctx, result.PARAM_NOT_EQUAL_CODE, "请求传参或设置有误", nil)
+		return
+	}
+	req := &ListCollectFolderArticlesReq{}
+	if err := request.ParseRequestBody(ctx, req); err != nil {
+		result.RespWithError(ctx, result.PARAM_NOT_EQUAL_CODE, "请求传参或设置有误", nil)
+		return
+	}
+	if req.Limit <= 0 || req.Limit > 100 {
+		req.Limit = 20
+	}
+	if req.Offset < 0 {
+		req.Offset = 0
+	}
+	userId, exists := ctx.Get("userId")
+	if !exists || userId.(int64) < 0 {
+		l.Error("未成功从 token 提取 userId", logger.Field{
+			Key:   "token错误",
+			Value: nil,
+		})
+		result.RespWithError(ctx, result.UNKNOWN_ERROR_CODE, "用户未登录", nil)
+		return
+	}
+	articles, err := handler.svc.ListCollectFolderArticles(ctx, folderId, userId.(int64), req.Offset, req.Limit)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.CollectFolderNotExistsErr):
+			result.RespWithError(ctx, result.RECORD_DO_NOT_EXISTS_CODE, "收藏夹不存在", nil)
+		case errors.Is(err, service.CollectFolderForbiddenErr):
+			result.RespWithError(ctx, result.PERMISSION_DENIED_CODE, "无权访问此收藏夹", nil)
+		default:
+			l.Warn("获取收藏夹文章失败", logger.Field{
+				Key:   "详情",
+				Value: err,
+			})
+			result.RespWithError(ctx, result.UNKNOWN_ERROR_CODE, "服务内部错误", nil)
+		}
+		return
+	}
+	result.RespWithSuccess(ctx, "获取成功", &ArticleListReply{
+		Articles: slice.Map[*service.Article, *Article](articles, func(idx int, src *service.Article) *Article {
+			return toArticleVO(src)
+		}),
+	})
 }
@@ -0,0 +1,106 @@
+package web
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"ibook/internal/service"
+	"ibook/pkg/utils/bskit/slice"
+	"ibook/pkg/utils/logger"
+	"ibook/pkg/utils/request"
+	"ibook/pkg/utils/result"
+	"strconv"
+	"time"
+)
+
+// MyLikes 当前用户点赞过的文章，按点赞时间倒序
+func (handler *ArticleHandler) MyLikes(ctx *gin.Context) {
+	l := logger.TagCtxLogger(ctx, handler.logger, "ArticleHandler-MyLikes")
+	req := &LikeListReq{}
+	if err := request.ParseRequestBody(ctx, req); err != nil {
+		result.RespWithError(ctx, result.PARAM_NOT_EQUAL_CODE, "请求传参或设置有误", nil)
+		return
+	}
+	if req.Limit <= 0 || req.Limit > 100 {
+		req.Limit = 20
+	}
+	cursor, err := service.DecodeCursor(req.Cursor)
+	if err != nil {
+		result.RespWithError(ctx, result.PARAM_FORMAT_ERROR_CODE, "分页游标不合法", nil)
+		return
+	}
+	userId, exists := ctx.Get("userId")
+	if !exists || userId.(int64) < 0 {
+		l.Error("未成功从 token 提取 userId", logger.Field{
+			Key:   "token错误",
+			Value: nil,
+		})
+		result.RespWithError(ctx, result.UNKNOWN_ERROR_CODE, "用户未登录", nil)
+		return
+	}
+	articles, next, hasMore, err := handler.svc.ListLikedArticles(ctx, userId.(int64), cursor, req.Limit)
+	if err != nil {
+		l.Warn("获取点赞文章失败", logger.Field{
+			Key:   "详情",
+			Value: err,
+		})
+		result.RespWithError(ctx, result.UNKNOWN_ERROR_CODE, "服务内部错误", nil)
+		return
+	}
+	result.RespWithSuccess(ctx, "获取成功", &LikedArticleListReply{
+		Articles: slice.Map[*service.LikedArticle, *LikedArticle](articles, func(idx int, src *service.LikedArticle) *LikedArticle {
+			return &LikedArticle{
+				Article:   toArticleVO(&src.Article),
+				LikedTime: time.UnixMilli(src.LikedTime).Local().Format(time.DateTime),
+			}
+		}),
+		NextCursor: service.EncodeCursor(next),
+		HasMore:    hasMore,
+	})
+}
+
+// Likers 已发表文章的点赞用户，按点赞时间倒序
+func (handler *ArticleHandler) Likers(ctx *gin.Context) {
+	l := logger.TagCtxLogger(ctx, handler.logger, "ArticleHandler-Likers")
+	articleId, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil || articleId <= 0 {
+		result.RespWithError(ctx, result.PARAM_NOT_EQUAL_CODE, "请求传参或设置有误", nil)
+		return
+	}
+	req := &LikeListReq{}
+	if err = request.ParseRequestBody(ctx, req); err != nil {
+		result.RespWithError(ctx, result.PARAM_NOT_EQUAL_CODE, "请求传参或设置有误", nil)
+		return
+	}
+	if req.Limit <= 0 || req.Limit > 100 {
+		req.Limit = 20
+	}
+	cursor, err := service.DecodeCursor(req.Cursor)
+	if err != nil {
+		result.RespWithError(ctx, result.PARAM_FORMAT_ERROR_CODE, "分页游标不合法", nil)
+		return
+	}
+	likers, next, hasMore, err := handler.svc.ListArticleLikers(ctx, articleId, cursor, req.Limit)
+	if err != nil {
+		if errors.Is(err, service.ArticleNotExistsErr) {
+			result.RespWithError(ctx, result.RECORD_DO_NOT_EXISTS_CODE, "文章不存在", nil)
+			return
+		}
+		l.Warn("获取点赞用户失败", logger.Field{
+			Key:   "详情",
+			Value: err,
+		})
+		result.RespWithError(ctx, result.UNKNOWN_ERROR_CODE, "服务内部错误", nil)
+		return
+	}
+	result.RespWithSuccess(ctx, "获取成功", &LikerListReply{
+		Likers: slice.Map[*service.LikeRecord, *Liker](likers, func(idx int, src *service.LikeRecord) *Liker {
+			return &Liker{
+				UserId:    src.UserId,
+				NickName:  src.NickName,
+				LikedTime: time.UnixMilli(src.LikedTime).Local().Format(time.DateTime),
+			}
+		}),
+		NextCursor: service.EncodeCursor(next),
+		HasMore:    hasMore,
+	})
+}
@@ -0,0 +1,115 @@
+package web
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"ibook/internal/service"
+	"ibook/pkg/utils/bskit/slice"
+	"ibook/pkg/utils/logger"
+	"ibook/pkg/utils/request"
+	"ibook/pkg/utils/result"
+	"time"
+)
+
+// Delete 将文章移入回收站，已发表的文章同时从读者端移除
+func (handler *ArticleHandler) Delete(ctx *gin.Context) {
+	l := logger.TagCtxLogger(ctx, handler.logger, "ArticleHandler-Delete")
+	req := &ArticleDeleteReq{}
+	if err := request.ParseRequestBody(ctx, req); err != nil || req.Id <= 0 {
+		result.RespWithError(ctx, result.PARAM_NOT_EQUAL_CODE, "请求传参或设置有误", nil)
+		return
+	}
+	userId, exists := ctx.Get("userId")
+	if !exists || userId.(int64) < 0 {
+		l.Error("未成功从 token 提取 userId", logger.Field{
+			Key:   "token错误",
+			Value: nil,
+		})
+		result.RespWithError(ctx, result.UNKNOWN_ERROR_CODE, "用户未登录", nil)
+		return
+	}
+	if err := handler.svc.DeleteArticle(ctx, req.Id, userId.(int64)); err != nil {
+		handler.respRecycleErr(ctx, l, err)
+		return
+	}
+	result.RespWithSuccess(ctx, "已移入回收站", &ArticleWithdrawReply{Id: req.Id, OK: true})
+}
+
+func (handler *ArticleHandler) ListRecycleBin(ctx *gin.Context) {
+	l := logger.TagCtxLogger(ctx, handler.logger, "ArticleHandler-ListRecycleBin")
+	req := &RecycleBinListReq{}
+	if err := request.ParseRequestBody(ctx, req); err != nil {
+		result.RespWithError(ctx, result.PARAM_NOT_EQUAL_CODE, "请求传参或设置有误", nil)
+		return
+	}
+	if req.Limit <= 0 || req.Limit > 100 {
+		req.Limit = 20
+	}
+	if req.Offset < 0 {
+		req.Offset = 0
+	}
+	userId, exists := ctx.Get("userId")
+	if !exists || userId.(int64) < 0 {
+		l.Error("未成功从 token 提取 userId", logger.Field{
+			Key:   "token错误",
+			Value: nil,
+		})
+		result.RespWithError(ctx, result.UNKNOWN_ERROR_CODE, "用户未登录", nil)
+		return
+	}
+	articles, err := handler.svc.ListRecycleBin(ctx, userId.(int64), req.Offset, req.Limit)
+	if err != nil {
+		handler.respRecycleErr(ctx, l, err)
+		return
+	}
+	result.RespWithSuccess(ctx, "获取成功", &RecycleBinListReply{
+		Articles: slice.Map[*service.DeletedArticle, *DeletedArticle](articles, func(idx int, src *service.DeletedArticle) *DeletedArticle {
+			return &DeletedArticle{
+				Id:          src.Id,
+				Title:       src.Title,
+				Category:    src.Category,
+				DeletedTime: time.UnixMilli(src.DeletedTime).Local().Format(time.DateTime),
+				PurgeTime:   time.UnixMilli(src.PurgeTime).Local().Format(time.DateTime),
+			}
+		}),
+	})
+}
+
+// RestoreDeleted 从回收站恢复文章，恢复后为草稿状态，需要重新发表
+func (handler *ArticleHandler) RestoreDeleted(ctx *gin.Context) {
+	l := logger.TagCtxLogger(ctx, handler.logger, "ArticleHandler-RestoreDeleted")
+	req := &RecycleBinRestoreReq{}
+	if err := request.ParseRequestBody(ctx, req); err != nil || req.Id <= 0 {
+		result.RespWithError(ctx, result.PARAM_NOT_EQUAL_CODE, "请求传参或设置有误", nil)
+		return
+	}
+	userId, exists := ctx.Get("userId")
+	if !exists || userId.(int64) < 0 {
+		l.Error("未成功从 token 提取 userId", logger.Field{
+			Key:   "token错误",
+			Value: nil,
+		})
+		result.RespWithError(ctx, result.UNKNOWN_ERROR_CODE, "用户未登录", nil)
+		return
+	}
+	if err := handler.svc.RestoreDeletedArticle(ctx, req.Id, userId.(int64)); err != nil {
+		handler.respRecycleErr(ctx, l, err)
+		return
+	}
+	result.RespWithSuccess(ctx, "恢复成功", &ArticleWithdrawReply{Id: req.Id, OK: true})
+}
+
+func (handler *ArticleHandler) respRecycleErr(ctx *gin.Context, l logger.Logger, err error) {
+	switch {
+	case errors.Is(err, service.ArticleNotExistsErr):
+		result.RespWithError(ctx, result.RECORD_DO_NOT_EXISTS_CODE, "文章不存在或已删除", nil)
+	case errors.Is(err, service.ArticleNotInRecycleBinErr):
+		result.RespWithError(ctx, result.RECORD_DO_NOT_EXISTS_CODE, "文章不在回收站中或已超过可恢复期限", nil)
+	default:
+		l.Warn("处理回收站操作失败", logger.Field{
+			Key:   "详情",
+			Value: err,
+		})
+		result.RespWithError(ctx, result.UNKNOWN_ERROR_CODE, "服务内部错误", nil)
+	}
+}
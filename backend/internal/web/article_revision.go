@@ -0,0 +1,187 @@
+package web
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"ibook/internal/service"
+	"ibook/pkg/utils/bskit/slice"
+	"ibook/pkg/utils/logger"
+	"ibook/pkg/utils/request"
+	"ibook/pkg/utils/result"
+	"ibook/pkg/utils/textdiff"
+	"strconv"
+	"time"
+)
+
+func (handler *ArticleHandler) ListRevisions(ctx *gin.Context) {
+	l := logger.TagCtxLogger(ctx, handler.logger, "ArticleHandler-ListRevisions")
+	req := &ListRevisionsReq{}
+	if err := request.ParseRequestBody(ctx, req); err != nil || req.ArticleId <= 0 {
+		result.RespWithError(ctx, result.PARAM_NOT_EQUAL_CODE, "请求传参或设置有误", nil)
+		return
+	}
+	if req.Limit <= 0 || req.Limit > 100 {
+		req.Limit = 20
+	}
+	if req.Offset < 0 {
+		req.Offset = 0
+	}
+	userId, exists := ctx.Get("userId")
+	if !exists || userId.(int64) < 0 {
+		l.Error("未成功从 token 提取 userId", logger.Field{
+			Key:   "token错误",
+			Value: nil,
+		})
+		result.RespWithError(ctx, result.UNKNOWN_ERROR_CODE, "用户未登录", nil)
+		return
+	}
+	revisions, err := handler.svc.ListRevisions(ctx, req.ArticleId, userId.(int64), req.Offset, req.Limit)
+	if err != nil {
+		l.Warn("获取文章历史版本失败", logger.Field{
+			Key:   "详情",
+			Value: err,
+		})
+		result.RespWithError(ctx, result.UNKNOWN_ERROR_CODE, "服务内部错误", nil)
+		return
+	}
+	result.RespWithSuccess(ctx, "获取成功", &ListRevisionsReply{
+		Revisions: slice.Map[*service.ArticleRevision, *ArticleRevision](revisions, func(idx int, src *service.ArticleRevision) *ArticleRevision {
+			return toRevisionVO(src)
+		}),
+	})
+}
+
+func (handler *ArticleHandler) RevisionDetail(ctx *gin.Context) {
+	l := logger.TagCtxLogger(ctx, handler.logger, "ArticleHandler-RevisionDetail")
+	revisionId, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil || revisionId <= 0 {
+		result.RespWithError(ctx, result.PARAM_NOT_EQUAL_CODE, "请求传参或设置有误", nil)
+		return
+	}
+	userId, exists := ctx.Get("userId")
+	if !exists || userId.(int64) < 0 {
+		l.Error("未成功从 token 提取 userId", logger.Field{
+			Key:   "token错误",
+			Value: nil,
+		})
+		result.RespWithError(ctx, result.UNKNOWN_ERROR_CODE, "用户未登录", nil)
+		return
+	}
+	revision, err := handler.svc.GetRevision(ctx, revisionId, userId.(int64))
+	if err != nil {
+		handler.respRevisionErr(ctx, l, err)
+		return
+	}
+	result.RespWithSuccess(ctx, "获取成功", toRevisionVO(revision))
+}
+
+func (handler *ArticleHandler) DiffRevisions(ctx *gin.Context) {
+	l := logger.TagCtxLogger(ctx, handler.logger, "ArticleHandler-DiffRevisions")
+	req := &DiffRevisionsReq{}
+	if err := request.ParseRequestBody(ctx, req); err != nil || req.From <= 0 || req.To <= 0 {
+		result.RespWithError(ctx, result.PARAM_NOT_EQUAL_CODE, "请求传参或设置有误", nil)
+		return
+	}
+	mode := service.DiffModeLine
+	switch req.Mode {
+	case "", string(service.DiffModeLine):
+	case string(service.DiffModeWord):
+		mode = service.DiffModeWord
+	default:
+		result.RespWithError(ctx, result.PARAM_FORMAT_ERROR_CODE, "比较方式只能为 line 或 word", nil)
+		return
+	}
+	userId, exists := ctx.Get("userId")
+	if !exists || userId.(int64) < 0 {
+		l.Error("未成功从 token 提取 userId", logger.Field{
+			Key:   "token错误",
+			Value: nil,
+		})
+		result.RespWithError(ctx, result.UNKNOWN_ERROR_CODE, "用户未登录", nil)
+		return
+	}
+	diff, err := handler.svc.DiffRevisions(ctx, req.From, req.To, userId.(int64), mode)
+	if err != nil {
+		handler.respRevisionErr(ctx, l, err)
+		return
+	}
+	from, to := toRevisionVO(diff.From), toRevisionVO(diff.To)
+	from.Content, to.Content = "", ""
+	result.RespWithSuccess(ctx, "获取成功", &DiffRevisionsReply{
+		From:    from,
+		To:      to,
+		Title:   toDiffOpVOs(diff.Title),
+		Content: toDiffOpVOs(diff.Content),
+	})
+}
+
+func (handler *ArticleHandler) RestoreRevision(ctx *gin.Context) {
+	l := logger.TagCtxLogger(ctx, handler.logger, "ArticleHandler-RestoreRevision")
+	req := &RestoreRevisionReq{}
+	if err := request.ParseRequestBody(ctx, req); err != nil || req.RevisionId <= 0 {
+		result.RespWithError(ctx, result.PARAM_NOT_EQUAL_CODE, "请求传参或设置有误", nil)
+		return
+	}
+	if req.Version <= 0 {
+		result.RespWithError(ctx, result.PARAM_NOT_EQUAL_CODE, "恢复历史版本需传入当前草稿的版本号", nil)
+		return
+	}
+	userId, exists := ctx.Get("userId")
+	if !exists || userId.(int64) < 0 {
+		l.Error("未成功从 token 提取 userId", logger.Field{
+			Key:   "token错误",
+			Value: nil,
+		})
+		result.RespWithError(ctx, result.UNKNOWN_ERROR_CODE, "用户未登录", nil)
+		return
+	}
+	article, err := handler.svc.RestoreRevision(ctx, req.RevisionId, userId.(int64), req.Version)
+	if err != nil {
+		handler.respRevisionErr(ctx, l, err)
+		return
+	}
+	result.RespWithSuccess(ctx, "恢复成功", &ArticleEditReply{
+		Id:       article.Id,
+		Title:    article.Title,
+		AuthorId: article.Author.Id,
+		Version:  article.Version,
+	})
+}
+
+func (handler *ArticleHandler) respRevisionErr(ctx *gin.Context, l logger.Logger, err error) {
+	// 恢复时版本冲突，返回服务端当前的草稿
+	var conflict *service.ArticleConflictError
+	switch {
+	case errors.As(err, &conflict):
+		result.RespWithError(ctx, result.VERSION_CONFLICT_CODE, "文章已在别处被修改", toGetArticleReply(conflict.Current))
+	case errors.Is(err, service.ArticleVersionConflictErr):
+		result.RespWithError(ctx, result.VERSION_CONFLICT_CODE, "文章已在别处被修改", nil)
+	case errors.Is(err, service.ArticleRevisionNotExistsErr):
+		result.RespWithError(ctx, result.RECORD_DO_NOT_EXISTS_CODE, "历史版本不存在", nil)
+	case errors.Is(err, service.ArticleRevisionMismatchErr):
+		result.RespWithError(ctx, result.PARAM_FORMAT_ERROR_CODE, "只能比较同一篇文章的历史版本", nil)
+	default:
+		l.Warn("处理文章历史版本失败", logger.Field{
+			Key:   "详情",
+			Value: err,
+		})
+		result.RespWithError(ctx, result.UNKNOWN_ERROR_CODE, "服务内部错误", nil)
+	}
+}
+
+func toRevisionVO(src *service.ArticleRevision) *ArticleRevision {
+	return &ArticleRevision{
+		Id:          src.Id,
+		ArticleId:   src.ArticleId,
+		Title:       src.Title,
+		Content:     src.Content,
+		Kind:        uint8(src.Kind),
+		CreatedTime: time.UnixMilli(src.CreatedTime).Local().Format(time.DateTime),
+	}
+}
+
+func toDiffOpVOs(ops []textdiff.Op) []*DiffOp {
+	return slice.Map[textdiff.Op, *DiffOp](ops, func(idx int, src textdiff.Op) *DiffOp {
+		return &DiffOp{Type: src.Type.String(), Text: src.Text}
+	})
+}
@@ -0,0 +1,115 @@
+package web
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"ibook/internal/service"
+	"ibook/pkg/utils/bskit/slice"
+	"ibook/pkg/utils/logger"
+	"ibook/pkg/utils/request"
+	"ibook/pkg/utils/result"
+)
+
+func (handler *ArticleHandler) ListSchedules(ctx *gin.Context) {
+	l := logger.TagCtxLogger(ctx, handler.logger, "ArticleHandler-ListSchedules")
+	req := &ListSchedulesReq{}
+	if err := request.ParseRequestBody(ctx, req); err != nil || req.ArticleId < 0 {
+		result.RespWithError(ctx, result.PARAM_NOT_EQUAL_CODE, "请求传参或设置有误", nil)
+		return
+	}
+	userId, exists := ctx.Get("userId")
+	if !exists || userId.(int64) < 0 {
+		l.Error("未成功从 token 提取 userId", logger.Field{
+			Key:   "token错误",
+			Value: nil,
+		})
+		result.RespWithError(ctx, result.UNKNOWN_ERROR_CODE, "用户未登录", nil)
+		return
+	}
+	schedules, err := handler.svc.ListSchedules(ctx, userId.(int64), req.ArticleId)
+	if err != nil {
+		l.Warn("获取定时计划失败", logger.Field{
+			Key:   "详情",
+			Value: err,
+		})
+		result.RespWithError(ctx, result.UNKNOWN_ERROR_CODE, "服务内部错误", nil)
+		return
+	}
+	result.RespWithSuccess(ctx, "获取成功", &ListSchedulesReply{
+		Schedules: slice.Map[*service.ArticleSchedule, *ArticleSchedule](schedules, func(idx int, src *service.ArticleSchedule) *ArticleSchedule {
+			action := "publish"
+			if src.Action == service.ScheduleActionWithdraw {
+				action = "withdraw"
+			}
+			return &ArticleSchedule{
+				Id:        src.Id,
+				ArticleId: src.ArticleId,
+				Action:    action,
+				ExecuteAt: src.ExecuteAt,
+				Attempts:  src.Attempts,
+				LastErr:   src.LastErr,
+			}
+		}),
+	})
+}
+
+func (handler *ArticleHandler) UpdateSchedule(ctx *gin.Context) {
+	l := logger.TagCtxLogger(ctx, handler.logger, "ArticleHandler-UpdateSchedule")
+	req := &UpdateScheduleReq{}
+	if err := request.ParseRequestBody(ctx, req); err != nil || req.Id <= 0 {
+		result.RespWithError(ctx, result.PARAM_NOT_EQUAL_CODE, "请求传参或设置有误", nil)
+		return
+	}
+	userId, exists := ctx.Get("userId")
+	if !exists || userId.(int64) < 0 {
+		l.Error("未成功从 token 提取 userId", logger.Field{
+			Key:   "token错误",
+			Value: nil,
+		})
+		result.RespWithError(ctx, result.UNKNOWN_ERROR_CODE, "用户未登录", nil)
+		return
+	}
+	if err := handler.svc.UpdateSchedule(ctx, req.Id, userId.(int64), req.ExecuteAt); err != nil {
+		handler.respScheduleErr(ctx, l, err)
+		return
+	}
+	result.RespWithSuccess(ctx, "修改成功", &ScheduleOpReply{Id: req.Id, OK: true})
+}
+
+func (handler *ArticleHandler) CancelSchedule(ctx *gin.Context) {
+	l := logger.TagCtxLogger(ctx, handler.logger, "ArticleHandler-CancelSchedule")
+	req := &CancelScheduleReq{}
+	if err := request.ParseRequestBody(ctx, req); err != nil || req.Id <= 0 {
+		result.RespWithError(ctx, result.PARAM_NOT_EQUAL_CODE, "请求传参或设置有误", nil)
+		return
+	}
+	userId, exists := ctx.Get("userId")
+	if !exists || userId.(int64) < 0 {
+		l.Error("未成功从 token 提取 userId", logger.Field{
+			Key:   "token错误",
+			Value: nil,
+		})
+		result.RespWithError(ctx, result.UNKNOWN_ERROR_CODE, "用户未登录", nil)
+		return
+	}
+	if err := handler.svc.CancelSchedule(ctx, req.Id, userId.(int64)); err != nil {
+		handler.respScheduleErr(ctx, l, err)
+		return
+	}
+	result.RespWithSuccess(ctx, "取消成功", &ScheduleOpReply{Id: req.Id, OK: true})
+}
+
+func (handler *ArticleHandler) respScheduleErr(ctx *gin.Context, l logger.Logger, err error) {
+	switch {
+	case errors.Is(err, service.ArticleScheduleNotExistsErr):
+		result.RespWithError(ctx, result.RECORD_DO_NOT_EXISTS_CODE, "定时计划不存在或已执行", nil)
+	case errors.Is(err, service.ScheduleTimeInvalidErr):
+		result.RespWithError(ctx, result.PARAM_FORMAT_ERROR_CODE, "执行时间需晚于当前时间，且定时发表需早于定时撤回", nil)
+	default:
+		l.Warn("处理定时计划失败", logger.Field{
+			Key:   "详情",
+			Value: err,
+		})
+		result.RespWithError(ctx, result.UNKNOWN_ERROR_CODE, "服务内部错误", nil)
+	}
+}
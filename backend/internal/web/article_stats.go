@@ -0,0 +1,64 @@
+package web
+
+import (
+	"github.com/gin-gonic/gin"
+	"ibook/internal/service"
+	"ibook/pkg/utils/bskit/slice"
+	"ibook/pkg/utils/logger"
+	"ibook/pkg/utils/request"
+	"ibook/pkg/utils/result"
+	"time"
+)
+
+// AuthorStats 当前用户已发表文章的阅读数、独立读者数与互动计数
+func (handler *ArticleHandler) AuthorStats(ctx *gin.Context) {
+	l := logger.TagCtxLogger(ctx, handler.logger, "ArticleHandler-AuthorStats")
+	req := &AuthorStatsReq{}
+	if err := request.ParseRequestBody(ctx, req); err != nil {
+		result.RespWithError(ctx, result.PARAM_NOT_EQUAL_CODE, "请求传参或设置有误", nil)
+		return
+	}
+	if req.Limit <= 0 || req.Limit > 100 {
+		req.Limit = 20
+	}
+	cursor, err := service.DecodeCursor(req.Cursor)
+	if err != nil {
+		result.RespWithError(ctx, result.PARAM_FORMAT_ERROR_CODE, "分页游标不合法", nil)
+		return
+	}
+	userId, exists := ctx.Get("userId")
+	if !exists || userId.(int64) < 0 {
+		l.Error("未成功从 token 提取 userId", logger.Field{
+			Key:   "token错误",
+			Value: nil,
+		})
+		result.RespWithError(ctx, result.UNKNOWN_ERROR_CODE, "用户未登录", nil)
+		return
+	}
+	articles, next, hasMore, err := handler.svc.ListAuthorArticleStats(ctx, userId.(int64), cursor, req.Limit)
+	if err != nil {
+		l.Warn("获取作者统计失败", logger.Field{
+			Key:   "详情",
+			Value: err,
+		})
+		result.RespWithError(ctx, result.UNKNOWN_ERROR_CODE, "服务内部错误", nil)
+		return
+	}
+	result.RespWithSuccess(ctx, "获取成功", &AuthorStatsReply{
+		Articles: slice.Map[*service.Article, *ArticleStats](articles, func(idx int, src *service.Article) *ArticleStats {
+			return &ArticleStats{
+				Id:              src.Id,
+				Title:           src.Title,
+				ReadCnt:         src.Interactive.ReadCnt,
+				UniqueReaderCnt: src.Interactive.UniqueReaderCnt,
+				LikeCnt:         src.Interactive.LikeCnt,
+				DislikeCnt:      src.Interactive.DislikeCnt,
+				CollectCnt:      src.Interactive.CollectCnt,
+				CommentCnt:      src.Interactive.CommentCnt,
+				CreatedTime:     time.UnixMilli(src.CreatedTime).Local().Format(time.DateTime),
+			}
+		}),
+		NextCursor: service.EncodeCursor(next),
+		HasMore:    hasMore,
+	})
+}
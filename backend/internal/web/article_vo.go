@@ -1,9 +1,18 @@
 package web
 
+import "mime/multipart"
+
 type ArticleEditReq struct {
 	Id      int64  `json:"id"`
 	Title   string `json:"title"`
 	Content string `json:"content"`
+	// markdown / plain，为空时按纯文本处理
+	Format   string `json:"format"`
+	Category string `json:"category"`
+	// 为 null 时不修改标签，传空数组时清空标签
+	Tags []string `json:"tags"`
+	// 编辑已有文章时传入获取草稿时拿到的版本号，与服务端不一致时返回冲突
+	Version int64 `json:"version"`
 }
 
 type ArticleEditReply struct {
@@ -11,17 +20,33 @@ type ArticleEditReply struct {
 	Title    string `json:"title"`
 	Content  string `json:"content"`
 	AuthorId int64  `json:"authorId"`
+	// 保存后的版本号，下次编辑时传入
+	Version int64 `json:"version"`
 }
 
 type ArticlePublishReq struct {
 	Id      int64  `json:"id"`
 	Title   string `json:"title"`
 	Content string `json:"content"`
+	// markdown / plain，为空时按纯文本处理
+	Format   string `json:"format"`
+	Category string `json:"category"`
+	// 为 null 时不修改标签，传空数组时清空标签
+	Tags []string `json:"tags"`
+	// 发表已有文章时传入获取草稿时拿到的版本号，与服务端不一致时返回冲突
+	Version int64 `json:"version"`
+	// 定时发表的毫秒时间戳，为空时立即发表
+	PublishAt int64 `json:"publishAt"`
+	// 定时撤回的毫秒时间戳，为空时不自动撤回
+	ExpireAt int64 `json:"expireAt"`
 }
 
 type ArticlePublishReply struct {
-	Id int64 `json:"id"`
-	OK bool  `json:"ok"`
+	Id      int64 `json:"id"`
+	OK      bool  `json:"ok"`
+	Version int64 `json:"version"`
+	// 是否为定时发表，此时文章已保存为草稿
+	Scheduled bool `json:"scheduled"`
 }
 
 type ArticleWithdrawReq struct {
@@ -33,25 +58,96 @@ type ArticleWithdrawReply struct {
 	OK bool  `json:"ok"`
 }
 
+type ArticleDeleteReq struct {
+	Id int64 `json:"id"`
+}
+
+type RecycleBinListReq struct {
+	Offset int64 `form:"offset" json:"offset"`
+	Limit  int64 `form:"limit" json:"limit"`
+}
+
+type RecycleBinListReply struct {
+	Articles []*DeletedArticle `json:"articles"`
+}
+
+type DeletedArticle struct {
+	Id          int64  `json:"id"`
+	Title       string `json:"title"`
+	Category    string `json:"category"`
+	DeletedTime string `json:"deletedTime"`
+	// 超过该时间后文章将被永久清除，无法恢复
+	PurgeTime string `json:"purgeTime"`
+}
+
+type RecycleBinRestoreReq struct {
+	Id int64 `json:"id"`
+}
+
 type ArticleListReq struct {
-	AuthorId int64 `json:"authorId"`
-	Offset   int64 `json:"offset"`
-	Limit    int64 `json:"limit"`
+	// 为空时查询全站文章
+	AuthorId int64 `form:"authorId" json:"authorId"`
+	// 上一页返回的 nextCursor，为空时查询第一页
+	Cursor string `form:"cursor" json:"cursor"`
+	// 按标签或分类筛选
+	Tag      string `form:"tag" json:"tag"`
+	Category string `form:"category" json:"category"`
+	Limit    int64  `form:"limit" json:"limit"`
+}
+
+type FeedReq struct {
+	// 上一页返回的 nextCursor，为空时查询第一页
+	Cursor string `form:"cursor" json:"cursor"`
+	Limit  int64  `form:"limit" json:"limit"`
 }
 
 type ArticleListReply struct {
-	Articles []*Article `json:"articles"`
+	Articles   []*Article `json:"articles"`
+	NextCursor string     `json:"nextCursor,omitempty"`
+	HasMore    bool       `json:"hasMore"`
 }
 
 type GetArticleReply struct {
+	Id       int64    `json:"id"`
+	Title    string   `json:"title"`
+	Content  string   `json:"content"`
+	Format   string   `json:"format"`
+	Category string   `json:"category"`
+	Tags     []string `json:"tags"`
+	Version  int64    `json:"version"`
+}
+
+type PubArticleDetailReply struct {
 	Id      int64  `json:"id"`
 	Title   string `json:"title"`
 	Content string `json:"content"`
+	Format  string `json:"format"`
+	// 渲染并清洗过的正文 HTML
+	HTML       string   `json:"html"`
+	Category   string   `json:"category"`
+	Tags       []string `json:"tags"`
+	AuthorId   int64    `json:"authorId"`
+	AuthorName string   `json:"authorName"`
+	ReadCnt    int64    `json:"readCnt"`
+	// 独立读者数，近似值
+	UniqueReaderCnt int64  `json:"uniqueReaderCnt"`
+	LikeCnt         int64  `json:"likeCnt"`
+	DislikeCnt      int64  `json:"dislikeCnt"`
+	CollectCnt      int64  `json:"collectCnt"`
+	CommentCnt      int64  `json:"commentCnt"`
+	Liked           bool   `json:"liked"`
+	Collected       bool   `json:"collected"`
+	CreatedTime     string `json:"createdTime"`
+	UpdatedTime     string `json:"updatedTime"`
+	// 访问者的表态："like" / "normal" / "dislike"，未登录时为 "normal"
+	Reaction string `json:"reaction"`
 }
 
 type LikeArticleReq struct {
 	ArticleId int64 `json:"articleId"`
-	// 1 -> 从不喜欢改为喜欢  0 -> 从喜欢改为不喜欢
+	// "like" or "normal" or "dislike"，为空时按 Like 字段处理
+	Status string `json:"status"`
+	// 兼容旧版本：1 -> 喜欢  0 -> 取消喜欢
 	Like int64 `json:"like"`
 }
 
@@ -59,7 +155,84 @@ type LikeArticleReply struct {
 	OK bool `json:"ok"`
 	// "like" or "normal" or "dislike"
 	// 喜欢 / 默认 / 踩
-	CurrentStatus string `json:"currentStatus"`
+	PreviousStatus string `json:"previousStatus"`
+	CurrentStatus  string `json:"currentStatus"`
+	// 重复提交相同的表态时为 false，计数不变
+	Changed bool `json:"changed"`
+}
+
+type LikeListReq struct {
+	// 上一页返回的 nextCursor，为空时查询第一页
+	Cursor string `form:"cursor" json:"cursor"`
+	Limit  int64  `form:"limit" json:"limit"`
+}
+
+type LikedArticleListReply struct {
+	Articles   []*LikedArticle `json:"articles"`
+	NextCursor string          `json:"nextCursor,omitempty"`
+	HasMore    bool            `json:"hasMore"`
+}
+
+type LikedArticle struct {
+	*Article
+	LikedTime string `json:"likedTime"`
+}
+
+type LikerListReply struct {
+	Likers     []*Liker `json:"likers"`
+	NextCursor string   `json:"nextCursor,omitempty"`
+	HasMore    bool     `json:"hasMore"`
+}
+
+type Liker struct {
+	UserId    int64  `json:"userId"`
+	NickName  string `json:"nickName"`
+	LikedTime string `json:"likedTime"`
+}
+
+type CollectArticleReq struct {
+	ArticleId int64 `json:"articleId"`
+	// 为空时收藏到默认收藏夹
+	FolderId int64 `json:"folderId"`
+	// 1 -> 收藏  0 -> 取消收藏
+	Collect int64 `json:"collect"`
+}
+
+type CollectArticleReply struct {
+	OK        bool `json:"ok"`
+	Collected bool `json:"collected"`
+}
+
+type CreateCollectFolderReq struct {
+	Name   string `json:"name"`
+	Public bool   `json:"public"`
+}
+
+type CreateCollectFolderReply struct {
+	Id int64 `json:"id"`
+}
+
+type ListCollectFoldersReq struct {
+	// 为空时查询当前用户的收藏夹
+	UserId int64 `form:"userId" json:"userId"`
+}
+
+type ListCollectFoldersReply struct {
+	Folders []*CollectFolder `json:"folders"`
+}
+
+type ListCollectFolderArticlesReq struct {
+	Offset int64 `form:"offset" json:"offset"`
+	Limit  int64 `form:"limit" json:"limit"`
+}
+
+type CollectFolder struct {
+	Id          int64  `json:"id"`
+	UserId      int64  `json:"userId"`
+	Name        string `json:"name"`
+	Public      bool   `json:"public"`
+	ArticleCnt  int64  `json:"articleCnt"`
+	CreatedTime string `json:"createdTime"`
 }
 
 type Article struct {
@@ -67,13 +240,247 @@ type Article struct {
 	Title    string `json:"title"`
 	Abstract string `json:"abstract"`
 	// Content string `json:"content"`
-	Status      uint8  `json:"status"`
-	AuthorId    int64  `json:"authorId"`
-	AuthorName  string `json:"authorName"`
-	CreatedTime string `json:"createdTime"`
-	UpdatedTime string `json:"updatedTime"`
+	Status      uint8    `json:"status"`
+	Category    string   `json:"category"`
+	Tags        []string `json:"tags"`
+	AuthorId    int64    `json:"authorId"`
+	AuthorName  string   `json:"authorName"`
+	ReadCnt     int64    `json:"readCnt"`
+	LikeCnt     int64    `json:"likeCnt"`
+	DislikeCnt  int64    `json:"dislikeCnt"`
+	CollectCnt  int64    `json:"collectCnt"`
+	CommentCnt  int64    `json:"commentCnt"`
+	CreatedTime string   `json:"createdTime"`
+	UpdatedTime string   `json:"updatedTime"`
 }
 
 func (req ArticleEditReq) validate() bool {
 	return true
 }
+
+type ListRevisionsReq struct {
+	ArticleId int64 `form:"articleId" json:"articleId"`
+	Offset    int64 `form:"offset" json:"offset"`
+	Limit     int64 `form:"limit" json:"limit"`
+}
+
+type ListRevisionsReply struct {
+	Revisions []*ArticleRevision `json:"revisions"`
+}
+
+type DiffRevisionsReq struct {
+	From int64 `form:"from" json:"from"`
+	To   int64 `form:"to" json:"to"`
+	// line -> 按行比较  word -> 按词比较，默认按行
+	Mode string `form:"mode" json:"mode"`
+}
+
+type DiffRevisionsReply struct {
+	From    *ArticleRevision `json:"from"`
+	To      *ArticleRevision `json:"to"`
+	Title   []*DiffOp        `json:"title"`
+	Content []*DiffOp        `json:"content"`
+}
+
+type RestoreRevisionReq struct {
+	RevisionId int64 `json:"revisionId"`
+	// Version 客户端当前草稿的版本号
+	Version int64 `json:"version"`
+}
+
+type ArticleRevision struct {
+	Id        int64  `json:"id"`
+	ArticleId int64  `json:"articleId"`
+	Title     string `json:"title"`
+	// 列表接口中不返回正文
+	Content     string `json:"content,omitempty"`
+	Kind        uint8  `json:"kind"`
+	CreatedTime string `json:"createdTime"`
+}
+
+type DiffOp struct {
+	// equal / insert / delete
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type ListSchedulesReq struct {
+	// 为空时列出当前用户全部文章的定时计划
+	ArticleId int64 `form:"articleId" json:"articleId"`
+}
+
+type ListSchedulesReply struct {
+	Schedules []*ArticleSchedule `json:"schedules"`
+}
+
+type UpdateScheduleReq struct {
+	Id int64 `json:"id"`
+	// 新的执行时间，毫秒时间戳
+	ExecuteAt int64 `json:"executeAt"`
+}
+
+type CancelScheduleReq struct {
+	Id int64 `json:"id"`
+}
+
+type ScheduleOpReply struct {
+	Id int64 `json:"id"`
+	OK bool  `json:"ok"`
+}
+
+type ArticleSchedule struct {
+	Id        int64 `json:"id"`
+	ArticleId int64 `json:"articleId"`
+	// publish -> 定时发表  withdraw -> 定时撤回
+	Action    string `json:"action"`
+	ExecuteAt int64  `json:"executeAt"`
+	Attempts  int64  `json:"attempts"`
+	LastErr   string `json:"lastErr,omitempty"`
+}
+
+type AuthorStatsReq struct {
+	// 上一页返回的 nextCursor，为空时查询第一页
+	Cursor string `form:"cursor" json:"cursor"`
+	Limit  int64  `form:"limit" json:"limit"`
+}
+
+type AuthorStatsReply struct {
+	Articles   []*ArticleStats `json:"articles"`
+	NextCursor string          `json:"nextCursor,omitempty"`
+	HasMore    bool            `json:"hasMore"`
+}
+
+type ArticleStats struct {
+	Id    int64  `json:"id"`
+	Title string `json:"title"`
+	// 阅读数，去重窗口内同一访问者的重复阅读只计一次
+	ReadCnt int64 `json:"readCnt"`
+	// 独立读者数，近似值
+	UniqueReaderCnt int64  `json:"uniqueReaderCnt"`
+	LikeCnt         int64  `json:"likeCnt"`
+	DislikeCnt      int64  `json:"dislikeCnt"`
+	CollectCnt      int64  `json:"collectCnt"`
+	CommentCnt      int64  `json:"commentCnt"`
+	CreatedTime     string `json:"createdTime"`
+}
+
+type HotListReq struct {
+	Offset int64 `form:"offset" json:"offset"`
+	Limit  int64 `form:"limit" json:"limit"`
+}
+
+type TagCountsReq struct {
+	Limit int64 `form:"limit" json:"limit"`
+}
+
+type TagCountsReply struct {
+	Tags []*TagCount `json:"tags"`
+}
+
+type TagCount struct {
+	Name       string `json:"name"`
+	ArticleCnt int64  `json:"articleCnt"`
+}
+
+type SearchReq struct {
+	Query  string `form:"q" json:"q"`
+	Offset int    `form:"offset" json:"offset"`
+	Limit  int    `form:"limit" json:"limit"`
+}
+
+type SearchReply struct {
+	Results []*SearchResult `json:"results"`
+	Total   int             `json:"total"`
+	HasMore bool            `json:"hasMore"`
+}
+
+type SearchResult struct {
+	Id int64 `json:"id"`
+	// Title 与 Snippet 为转义后的 HTML，命中的词使用 <em> 标记
+	Title       string  `json:"title"`
+	Snippet     string  `json:"snippet"`
+	Score       float64 `json:"score"`
+	Category    string  `json:"category"`
+	AuthorId    int64   `json:"authorId"`
+	UpdatedTime string  `json:"updatedTime"`
+}
+
+type CreateCommentReq struct {
+	ArticleId int64 `json:"articleId"`
+	// 为 0 时发表一级评论，否则回复该评论
+	ParentId int64  `json:"parentId"`
+	Content  string `json:"content"`
+}
+
+type DeleteCommentReq struct {
+	CommentId int64 `json:"commentId"`
+}
+
+type LikeCommentReq struct {
+	CommentId int64 `json:"commentId"`
+	// 1 -> 点赞  0 -> 取消点赞
+	Like int64 `json:"like"`
+}
+
+type CommentOpReply struct {
+	OK bool `json:"ok"`
+}
+
+type ListCommentsReq struct {
+	ArticleId int64 `form:"articleId" json:"articleId"`
+	// "time" 按时间倒序（默认），"like" 按点赞数倒序
+	Sort   string `form:"sort" json:"sort"`
+	Cursor string `form:"cursor" json:"cursor"`
+	Limit  int64  `form:"limit" json:"limit"`
+}
+
+type ListRepliesReq struct {
+	Cursor string `form:"cursor" json:"cursor"`
+	Limit  int64  `form:"limit" json:"limit"`
+}
+
+type CommentListReply struct {
+	Comments   []*Comment `json:"comments"`
+	NextCursor string     `json:"nextCursor,omitempty"`
+	HasMore    bool       `json:"hasMore"`
+}
+
+type Comment struct {
+	Id          int64      `json:"id"`
+	ArticleId   int64      `json:"articleId"`
+	UserId      int64      `json:"userId"`
+	UserName    string     `json:"userName"`
+	RootId      int64      `json:"rootId"`
+	ParentId    int64      `json:"parentId"`
+	ReplyToUid  int64      `json:"replyToUid"`
+	Content     string     `json:"content"`
+	LikeCnt     int64      `json:"likeCnt"`
+	ReplyCnt    int64      `json:"replyCnt"`
+	Liked       bool       `json:"liked"`
+	CreatedTime string     `json:"createdTime"`
+	Replies     []*Comment `json:"replies,omitempty"`
+}
+
+type UploadImageReq struct {
+	// avatar -> 用户头像  article -> 文章配图
+	Kind string                `form:"kind"`
+	File *multipart.FileHeader `form:"file"`
+}
+
+type UploadImageReply struct {
+	// 原图地址，可直接用于个人资料中的 avatarUrl 或文章正文
+	Url         string          `json:"url"`
+	ContentType string          `json:"contentType"`
+	Size        int64           `json:"size"`
+	Width       int             `json:"width"`
+	Height      int             `json:"height"`
+	Thumbnails  []*ImageVariant `json:"thumbnails"`
+}
+
+type ImageVariant struct {
+	// 头像为 s48 / s96 / s256，文章配图为 w320 / w640 / w1280
+	Name   string `json:"name"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	Url    string `json:"url"`
+}
@@ -0,0 +1,201 @@
+package web
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"ibook/internal/service"
+	"ibook/pkg/utils/bskit/slice"
+	"ibook/pkg/utils/logger"
+	"ibook/pkg/utils/request"
+	"ibook/pkg/utils/result"
+	"strconv"
+	"time"
+)
+
+func (handler *ArticleHandler) CreateComment(ctx *gin.Context) {
+	l := logger.TagCtxLogger(ctx, handler.logger, "ArticleHandler-CreateComment")
+	req := &CreateCommentReq{}
+	if err := request.ParseRequestBody(ctx, req); err != nil || req.ArticleId <= 0 || req.ParentId < 0 {
+		result.RespWithError(ctx, result.PARAM_NOT_EQUAL_CODE, "请求传参或设置有误", nil)
+		return
+	}
+	userId, exists := ctx.Get("userId")
+	if !exists || userId.(int64) < 0 {
+		l.Error("未成功从 token 提取 userId", logger.Field{
+			Key:   "token错误",
+			Value: nil,
+		})
+		result.RespWithError(ctx, result.UNKNOWN_ERROR_CODE, "用户未登录", nil)
+		return
+	}
+	comment := &service.Comment{
+		ArticleId: req.ArticleId,
+		UserId:    userId.(int64),
+		ParentId:  req.ParentId,
+		Content:   req.Content,
+	}
+	if err := handler.csvc.CreateComment(ctx, comment); err != nil {
+		handler.respCommentErr(ctx, l, err)
+		return
+	}
+	result.RespWithSuccess(ctx, "评论成功", toCommentVO(comment))
+}
+
+func (handler *ArticleHandler) DeleteComment(ctx *gin.Context) {
+	l := logger.TagCtxLogger(ctx, handler.logger, "ArticleHandler-DeleteComment")
+	req := &DeleteCommentReq{}
+	if err := request.ParseRequestBody(ctx, req); err != nil || req.CommentId <= 0 {
+		result.RespWithError(ctx, result.PARAM_NOT_EQUAL_CODE, "请求传参或设置有误", nil)
+		return
+	}
+	userId, exists := ctx.Get("userId")
+	if !exists || userId.(int64) < 0 {
+		l.Error("未成功从 token 提取 userId", logger.Field{
+			Key:   "token错误",
+			Value: nil,
+		})
+		result.RespWithError(ctx, result.UNKNOWN_ERROR_CODE, "用户未登录", nil)
+		return
+	}
+	if err := handler.csvc.DeleteComment(ctx, req.CommentId, userId.(int64)); err != nil {
+		handler.respCommentErr(ctx, l, err)
+		return
+	}
+	result.RespWithSuccess(ctx, "删除成功", &CommentOpReply{OK: true})
+}
+
+func (handler *ArticleHandler) ListComments(ctx *gin.Context) {
+	l := logger.TagCtxLogger(ctx, handler.logger, "ArticleHandler-ListComments")
+	req := &ListCommentsReq{}
+	if err := request.ParseRequestBody(ctx, req); err != nil || req.ArticleId <= 0 {
+		result.RespWithError(ctx, result.PARAM_NOT_EQUAL_CODE, "请求传参或设置有误", nil)
+		return
+	}
+	sort := service.CommentSortTime
+	switch req.Sort {
+	case "", "time":
+	case "like":
+		sort = service.CommentSortLike
+	default:
+		result.RespWithError(ctx, result.PARAM_FORMAT_ERROR_CODE, "排序方式只能为 time 或 like", nil)
+		return
+	}
+	if req.Limit <= 0 || req.Limit > 50 {
+		req.Limit = 20
+	}
+	cursor, err := service.DecodeCursor(req.Cursor)
+	if err != nil {
+		result.RespWithError(ctx, result.PARAM_FORMAT_ERROR_CODE, "分页游标不合法", nil)
+		return
+	}
+	viewerId := ctx.GetInt64("userId")
+	comments, next, hasMore, err := handler.csvc.ListComments(ctx, req.ArticleId, viewerId, sort, cursor, req.Limit)
+	if err != nil {
+		handler.respCommentErr(ctx, l, err)
+		return
+	}
+	result.RespWithSuccess(ctx, "获取成功", &CommentListReply{
+		Comments:   slice.Map[*service.Comment, *Comment](comments, func(idx int, src *service.Comment) *Comment { return toCommentVO(src) }),
+		NextCursor: service.EncodeCursor(next),
+		HasMore:    hasMore,
+	})
+}
+
+func (handler *ArticleHandler) ListReplies(ctx *gin.Context) {
+	l := logger.TagCtxLogger(ctx, handler.logger, "ArticleHandler-ListReplies")
+	rootId, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil || rootId <= 0 {
+		result.RespWithError(ctx, result.PARAM_NOT_EQUAL_CODE, "请求传参或设置有误", nil)
+		return
+	}
+	req := &ListRepliesReq{}
+	if err = request.ParseRequestBody(ctx, req); err != nil {
+		result.RespWithError(ctx, result.PARAM_NOT_EQUAL_CODE, "请求传参或设置有误", nil)
+		return
+	}
+	if req.Limit <= 0 || req.Limit > 50 {
+		req.Limit = 20
+	}
+	cursor, err := service.DecodeCursor(req.Cursor)
+	if err != nil {
+		result.RespWithError(ctx, result.PARAM_FORMAT_ERROR_CODE, "分页游标不合法", nil)
+		return
+	}
+	viewerId := ctx.GetInt64("userId")
+	replies, next, hasMore, err := handler.csvc.ListReplies(ctx, rootId, viewerId, cursor, req.Limit)
+	if err != nil {
+		handler.respCommentErr(ctx, l, err)
+		return
+	}
+	result.RespWithSuccess(ctx, "获取成功", &CommentListReply{
+		Comments:   slice.Map[*service.Comment, *Comment](replies, func(idx int, src *service.Comment) *Comment { return toCommentVO(src) }),
+		NextCursor: service.EncodeCursor(next),
+		HasMore:    hasMore,
+	})
+}
+
+func (handler *ArticleHandler) LikeComment(ctx *gin.Context) {
+	l := logger.TagCtxLogger(ctx, handler.logger, "ArticleHandler-LikeComment")
+	req := &LikeCommentReq{}
+	if err := request.ParseRequestBody(ctx, req); err != nil || req.CommentId <= 0 {
+		result.RespWithError(ctx, result.PARAM_NOT_EQUAL_CODE, "请求传参或设置有误", nil)
+		return
+	}
+	userId, exists := ctx.Get("userId")
+	if !exists || userId.(int64) < 0 {
+		l.Error("未成功从 token 提取 userId", logger.Field{
+			Key:   "token错误",
+			Value: nil,
+		})
+		result.RespWithError(ctx, result.UNKNOWN_ERROR_CODE, "用户未登录", nil)
+		return
+	}
+	var err error
+	if req.Like == 1 {
+		err = handler.csvc.LikeComment(ctx, userId.(int64), req.CommentId)
+	} else {
+		err = handler.csvc.CancelLikeComment(ctx, userId.(int64), req.CommentId)
+	}
+	if err != nil {
+		handler.respCommentErr(ctx, l, err)
+		return
+	}
+	result.RespWithSuccess(ctx, "操作成功", &CommentOpReply{OK: true})
+}
+
+func (handler *ArticleHandler) respCommentErr(ctx *gin.Context, l logger.Logger, err error) {
+	switch {
+	case errors.Is(err, service.ArticleNotExistsErr):
+		result.RespWithError(ctx, result.RECORD_DO_NOT_EXISTS_CODE, "文章不存在", nil)
+	case errors.Is(err, service.CommentNotExistsErr):
+		result.RespWithError(ctx, result.RECORD_DO_NOT_EXISTS_CODE, "评论不存在", nil)
+	case errors.Is(err, service.CommentForbiddenErr):
+		result.RespWithError(ctx, result.PERMISSION_DENIED_CODE, "只有评论者与文章作者可以删除评论", nil)
+	case errors.Is(err, service.CommentInvalidErr):
+		result.RespWithError(ctx, result.PARAM_FORMAT_ERROR_CODE, "评论内容不能为空且不超过 1000 字", nil)
+	default:
+		l.Warn("处理评论失败", logger.Field{
+			Key:   "详情",
+			Value: err,
+		})
+		result.RespWithError(ctx, result.UNKNOWN_ERROR_CODE, "服务内部错误", nil)
+	}
+}
+
+func toCommentVO(src *service.Comment) *Comment {
+	return &Comment{
+		Id:          src.Id,
+		ArticleId:   src.ArticleId,
+		UserId:      src.UserId,
+		UserName:    src.UserName,
+		RootId:      src.RootId,
+		ParentId:    src.ParentId,
+		ReplyToUid:  src.ReplyToUid,
+		Content:     src.Content,
+		LikeCnt:     src.LikeCnt,
+		ReplyCnt:    src.ReplyCnt,
+		Liked:       src.Liked,
+		CreatedTime: time.UnixMilli(src.CreatedTime).Local().Format(time.DateTime),
+		Replies:     slice.Map[*service.Comment, *Comment](src.Replies, func(idx int, src *service.Comment) *Comment { return toCommentVO(src) }),
+	}
+}
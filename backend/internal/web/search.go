@@ -0,0 +1,86 @@
+package web
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"ibook/internal/service"
+	"ibook/pkg/utils/bskit/slice"
+	"ibook/pkg/utils/logger"
+	"ibook/pkg/utils/request"
+	"ibook/pkg/utils/result"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	maxSearchQueryLength = 64
+	// maxSearchOffset 相关度排序只支持有限深度的翻页
+	maxSearchOffset = 1000
+)
+
+type SearchHandler struct {
+	svc    service.SearchService
+	logger logger.Logger
+}
+
+func NewSearchHandler(svc service.SearchService, myLogger logger.Logger) *SearchHandler {
+	return &SearchHandler{
+		svc:    svc,
+		logger: myLogger,
+	}
+}
+
+func (handler *SearchHandler) RegisterRoutesV1(server *gin.Engine) {
+	ug := server.Group("/articles")
+	ug.GET("/search", handler.Search)
+}
+
+func (handler *SearchHandler) Search(ctx *gin.Context) {
+	l := logger.TagCtxLogger(ctx, handler.logger, "SearchHandler-Search")
+	req := &SearchReq{}
+	if err := request.ParseRequestBody(ctx, req); err != nil {
+		result.RespWithError(ctx, result.PARAM_NOT_EQUAL_CODE, "请求传参或设置有误", nil)
+		return
+	}
+	req.Query = strings.TrimSpace(req.Query)
+	if req.Query == "" || utf8.RuneCountInString(req.Query) > maxSearchQueryLength {
+		result.RespWithError(ctx, result.PARAM_FORMAT_ERROR_CODE, "搜索关键词不能为空且不超过 64 个字符", nil)
+		return
+	}
+	if req.Limit <= 0 || req.Limit > 50 {
+		req.Limit = 20
+	}
+	if req.Offset < 0 || req.Offset > maxSearchOffset {
+		result.RespWithError(ctx, result.PARAM_FORMAT_ERROR_CODE, "翻页超出范围", nil)
+		return
+	}
+	results, total, err := handler.svc.Search(ctx, req.Query, req.Offset, req.Limit)
+	if err != nil {
+		if errors.Is(err, service.SearchIndexNotReadyErr) {
+			result.RespWithError(ctx, result.SERVICE_UNAVAILABLE_CODE, "搜索服务启动中，请稍后再试", nil)
+			return
+		}
+		l.Warn("搜索文章失败", logger.Field{
+			Key:   "详情",
+			Value: err,
+		})
+		result.RespWithError(ctx, result.UNKNOWN_ERROR_CODE, "服务内部错误", nil)
+		return
+	}
+	result.RespWithSuccess(ctx, "搜索成功", &SearchReply{
+		Results: slice.Map[*service.SearchResult, *SearchResult](results, func(idx int, src *service.SearchResult) *SearchResult {
+			return &SearchResult{
+				Id:          src.Article.Id,
+				Title:       src.Title,
+				Snippet:     src.Snippet,
+				Score:       src.Score,
+				Category:    src.Article.Category,
+				AuthorId:    src.Article.Author.Id,
+				UpdatedTime: time.UnixMilli(src.Article.UpdatedTime).Local().Format(time.DateTime),
+			}
+		}),
+		Total:   total,
+		HasMore: req.Offset+req.Limit < total,
+	})
+}
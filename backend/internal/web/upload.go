@@ -0,0 +1,129 @@
+package web
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"ibook/internal/service"
+	"ibook/pkg/utils/bskit/slice"
+	"ibook/pkg/utils/logger"
+	"ibook/pkg/utils/request"
+	"ibook/pkg/utils/result"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// multipartOverhead 请求体中除文件内容外的表单字段与分隔符
+const multipartOverhead = 64 << 10
+
+type UploadHandler struct {
+	svc    service.UploadService
+	logger logger.Logger
+}
+
+func NewUploadHandler(svc service.UploadService, myLogger logger.Logger) *UploadHandler {
+	return &UploadHandler{
+		svc:    svc,
+		logger: myLogger,
+	}
+}
+
+func (handler *UploadHandler) RegisterRoutesV1(server *gin.Engine) {
+	server.POST("/uploads/images", handler.UploadImage)
+	// 未配置外部访问地址时由服务自身提供上传文件，需在登录校验中放行
+	server.GET("/blobs/*key", handler.Blob)
+}
+
+func (handler *UploadHandler) UploadImage(ctx *gin.Context) {
+	l := logger.TagCtxLogger(ctx, handler.logger, "UploadHandler-UploadImage")
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, service.MaxImageBytes+multipartOverhead)
+	req := &UploadImageReq{}
+	if err := request.ParseRequestBody(ctx, req); err != nil || req.File == nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			result.RespWithError(ctx, result.PARAM_FORMAT_ERROR_CODE, "图片不能超过 5MB", nil)
+			return
+		}
+		result.RespWithError(ctx, result.PARAM_NOT_EQUAL_CODE, "请求传参或设置有误", nil)
+		return
+	}
+	var kind service.UploadKind
+	switch req.Kind {
+	case "avatar":
+		kind = service.UploadKindAvatar
+	case "article":
+		kind = service.UploadKindArticleImage
+	default:
+		result.RespWithError(ctx, result.PARAM_FORMAT_ERROR_CODE, "上传类型只能为 avatar 或 article", nil)
+		return
+	}
+	if req.File.Size > service.MaxImageBytes {
+		result.RespWithError(ctx, result.PARAM_FORMAT_ERROR_CODE, "图片不能超过 5MB", nil)
+		return
+	}
+	file, err := req.File.Open()
+	if err != nil {
+		result.RespWithError(ctx, result.PARAM_NOT_EQUAL_CODE, "请求传参或设置有误", nil)
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, service.MaxImageBytes+1))
+	if err != nil {
+		result.RespWithError(ctx, result.PARAM_NOT_EQUAL_CODE, "请求传参或设置有误", nil)
+		return
+	}
+	img, err := handler.svc.UploadImage(ctx, kind, data)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.UploadTooLargeErr):
+			result.RespWithError(ctx, result.PARAM_FORMAT_ERROR_CODE, "图片不能超过 5MB", nil)
+		case errors.Is(err, service.UploadTypeInvalidErr), errors.Is(err, service.UploadImageInvalidErr):
+			result.RespWithError(ctx, result.PARAM_FORMAT_ERROR_CODE, err.Error(), nil)
+		default:
+			l.Warn("上传图片失败", logger.Field{
+				Key:   "详情",
+				Value: err,
+			})
+			result.RespWithError(ctx, result.UNKNOWN_ERROR_CODE, "服务内部错误", nil)
+		}
+		return
+	}
+	result.RespWithSuccess(ctx, "上传成功", &UploadImageReply{
+		Url:         img.Url,
+		ContentType: img.ContentType,
+		Size:        img.Size,
+		Width:       img.Width,
+		Height:      img.Height,
+		Thumbnails: slice.Map[*service.ImageVariant, *ImageVariant](img.Thumbnails, func(idx int, src *service.ImageVariant) *ImageVariant {
+			return &ImageVariant{
+				Name:   src.Name,
+				Width:  src.Width,
+				Height: src.Height,
+				Url:    src.Url,
+			}
+		}),
+	})
+}
+
+func (handler *UploadHandler) Blob(ctx *gin.Context) {
+	l := logger.TagCtxLogger(ctx, handler.logger, "UploadHandler-Blob")
+	key := strings.TrimPrefix(ctx.Param("key"), "/")
+	rc, info, err := handler.svc.OpenBlob(ctx, key)
+	if err != nil {
+		if errors.Is(err, service.BlobNotExistsErr) {
+			ctx.Status(http.StatusNotFound)
+			return
+		}
+		l.Warn("读取上传文件失败", logger.Field{
+			Key:   "详情",
+			Value: err,
+		})
+		ctx.Status(http.StatusInternalServerError)
+		return
+	}
+	defer rc.Close()
+	// key 由内容哈希生成，内容不会变化，可以长期缓存
+	ctx.Header("Cache-Control", "public, max-age=31536000, immutable")
+	ctx.Header("X-Content-Type-Options", "nosniff")
+	ctx.DataFromReader(http.StatusOK, info.Size, info.ContentType, rc, nil)
+}
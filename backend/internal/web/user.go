@@ -9,7 +9,6 @@ import (
 	"ibook/pkg/utils/request"
 	"ibook/pkg/utils/result"
 	"log"
-	"net/http"
 	"strconv"
 	"strings"
 )
@@ -47,6 +46,11 @@ func (u *UserHandler) RegisterRoutesV1(server *gin.Engine) {
 	ug.POST("/edit", u.Edit)
 	ug.POST("/login_sms/send", u.SendLoginSMSCode)
 	ug.POST("/login_sms", u.LoginSMS)
+	ug.POST("/follow", u.Follow)
+	ug.GET("/followers/:userId", u.Followers)
+	ug.GET("/following/:userId", u.Following)
+	ug.POST("/password/reset/send", u.SendResetPasswordCode)
+	ug.POST("/password/reset", u.ResetPassword)
 }
 
 func (u *UserHandler) SignUp(context *gin.Context) {
@@ -139,7 +143,8 @@ func (u *UserHandler) Profile(context *gin.Context) {
 		return
 	}
 
-	res, err := u.svc.Profile(context, int64(uId))
+	// 未登录时 viewerId 为 0，此时不返回关注状态
+	res, stat, err := u.svc.Profile(context, int64(uId), context.GetInt64("userId"))
 	if err != nil {
 		if errors.Is(err, service.UserNotExistsErr) {
 			result.RespWithError(context, result.USER_DO_NOT_EXISTS_CODE, "用户不存在", nil)
@@ -149,18 +154,58 @@ func (u *UserHandler) Profile(context *gin.Context) {
 		result.RespWithError(context, result.UNKNOWN_ERROR_CODE, "服务内部异常，请联系管理员", nil)
 		return
 	}
-	result.RespWithSuccess(context, "获取成功", &UserProfileResp{
-		UserId:   res.Id,
-		Email:    res.Email,
-		NickName: res.NickName,
-	})
+	resp := toUserProfileResp(res)
+	resp.FollowerCnt = stat.FollowerCnt
+	resp.FollowingCnt = stat.FollowingCnt
+	resp.Followed = stat.Followed
+	result.RespWithSuccess(context, "获取成功", resp)
 }
 
 func (u *UserHandler) Edit(context *gin.Context) {
-	context.JSON(http.StatusOK, gin.H{
-		"code": http.StatusAccepted,
-		"msg":  "功能待完善",
+	req := &UserEditReq{}
+	if err := request.ParseRequestBody(context, req); err != nil || ValidateUserEditReq(req) != nil {
+		result.RespWithError(context, result.PARAM_NOT_EQUAL_CODE, "请求传参或设置有误", nil)
+		return
+	}
+	userId, exists := context.Get("userId")
+	if !exists || userId.(int64) <= 0 {
+		result.RespWithError(context, result.UNKNOWN_ERROR_CODE, "用户未登录", nil)
+		return
+	}
+	res, err := u.svc.EditProfile(context, userId.(int64), &service.UserProfileEdit{
+		NickName:  req.NickName,
+		Bio:       req.Bio,
+		Birthday:  req.Birthday,
+		AvatarUrl: req.AvatarUrl,
 	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ProfileInvalidErr):
+			result.RespWithError(context, result.PARAM_FORMAT_ERROR_CODE, err.Error(), nil)
+		case errors.Is(err, service.NickNameExistsErr):
+			result.RespWithError(context, result.RECORD_ALREADY_EXISTS_CODE, "该昵称已被使用", nil)
+		case errors.Is(err, service.NickNameCooldownErr):
+			result.RespWithError(context, result.OPERATION_TOO_FREQUENT_CODE, "昵称每 30 天只能修改一次", nil)
+		case errors.Is(err, service.UserNotExistsErr):
+			result.RespWithError(context, result.USER_DO_NOT_EXISTS_CODE, "用户不存在", nil)
+		default:
+			log.Println(err)
+			result.RespWithError(context, result.UNKNOWN_ERROR_CODE, "服务内部异常，请联系管理员", nil)
+		}
+		return
+	}
+	result.RespWithSuccess(context, "修改成功", toUserProfileResp(res))
+}
+
+func toUserProfileResp(user *service.User) *UserProfileResp {
+	return &UserProfileResp{
+		UserId:    user.Id,
+		Email:     user.Email,
+		NickName:  user.NickName,
+		Bio:       user.Bio,
+		Birthday:  user.Birthday,
+		AvatarUrl: user.AvatarUrl,
+	}
 }
 
 func (u *UserHandler) SendLoginSMSCode(context *gin.Context) {
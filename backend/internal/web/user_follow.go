@@ -0,0 +1,106 @@
+package web
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"ibook/internal/service"
+	"ibook/pkg/utils/bskit/slice"
+	"ibook/pkg/utils/request"
+	"ibook/pkg/utils/result"
+	"log"
+	"strconv"
+	"time"
+)
+
+// Follow 关注或取消关注用户，重复提交时结果不变
+func (u *UserHandler) Follow(context *gin.Context) {
+	req := &UserFollowReq{}
+	if err := request.ParseRequestBody(context, req); err != nil || ValidateUserFollowReq(req) != nil {
+		result.RespWithError(context, result.PARAM_NOT_EQUAL_CODE, "请求传参或设置有误", nil)
+		return
+	}
+	userId, exists := context.Get("userId")
+	if !exists || userId.(int64) <= 0 {
+		result.RespWithError(context, result.UNKNOWN_ERROR_CODE, "用户未登录", nil)
+		return
+	}
+	var err error
+	if req.Follow == 1 {
+		err = u.svc.Follow(context, userId.(int64), req.UserId)
+	} else {
+		err = u.svc.Unfollow(context, userId.(int64), req.UserId)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, service.FollowSelfErr):
+			result.RespWithError(context, result.PARAM_FORMAT_ERROR_CODE, "不能关注自己", nil)
+		case errors.Is(err, service.UserNotExistsErr):
+			result.RespWithError(context, result.USER_DO_NOT_EXISTS_CODE, "用户不存在", nil)
+		default:
+			log.Println(err)
+			result.RespWithError(context, result.UNKNOWN_ERROR_CODE, "服务内部异常，请联系管理员", nil)
+		}
+		return
+	}
+	if req.Follow == 1 {
+		result.RespWithSuccess(context, "关注成功", nil)
+		return
+	}
+	result.RespWithSuccess(context, "已取消关注", nil)
+}
+
+// Followers 用户的粉丝列表，按关注时间倒序
+func (u *UserHandler) Followers(context *gin.Context) {
+	u.followList(context, u.svc.ListFollowers)
+}
+
+// Following 用户的关注列表，按关注时间倒序
+func (u *UserHandler) Following(context *gin.Context) {
+	u.followList(context, u.svc.ListFollowees)
+}
+
+type followListFunc func(ctx *gin.Context, userId int64, cursor service.ListCursor, limit int64) ([]*service.FollowRelation, service.ListCursor, bool, error)
+
+func (u *UserHandler) followList(context *gin.Context, list followListFunc) {
+	userId, err := strconv.ParseInt(context.Param("userId"), 10, 64)
+	if err != nil || userId <= 0 {
+		result.RespWithError(context, result.PARAM_NOT_EQUAL_CODE, "请求传参或设置有误", nil)
+		return
+	}
+	req := &FollowListReq{}
+	if err = request.ParseRequestBody(context, req); err != nil {
+		result.RespWithError(context, result.PARAM_NOT_EQUAL_CODE, "请求传参或设置有误", nil)
+		return
+	}
+	if req.Limit <= 0 || req.Limit > 50 {
+		req.Limit = 20
+	}
+	cursor, err := service.DecodeCursor(req.Cursor)
+	if err != nil {
+		result.RespWithError(context, result.PARAM_FORMAT_ERROR_CODE, "分页游标不合法", nil)
+		return
+	}
+	relations, next, hasMore, err := list(context, userId, cursor, req.Limit)
+	if err != nil {
+		log.Println(err)
+		result.RespWithError(context, result.UNKNOWN_ERROR_CODE, "服务内部异常，请联系管理员", nil)
+		return
+	}
+	// 粉丝列表展示关注者，关注列表展示被关注者
+	result.RespWithSuccess(context, "获取成功", &FollowListReply{
+		Users: slice.Map[*service.FollowRelation, *FollowUser](relations, func(idx int, src *service.FollowRelation) *FollowUser {
+			shownId := src.FolloweeId
+			if src.FolloweeId == userId {
+				shownId = src.FollowerId
+			}
+			return &FollowUser{
+				UserId:       shownId,
+				NickName:     src.NickName,
+				AvatarUrl:    src.AvatarUrl,
+				FollowedTime: time.UnixMilli(src.FollowedTime).Local().Format(time.DateTime),
+			}
+		}),
+		NextCursor: service.EncodeCursor(next),
+		HasMore:    hasMore,
+	})
+}
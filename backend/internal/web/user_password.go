@@ -0,0 +1,70 @@
+package web
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"ibook/internal/service"
+	"ibook/pkg/utils/request"
+	"ibook/pkg/utils/result"
+	"log"
+)
+
+// SendResetPasswordCode 向注册邮箱发送重置密码的验证码，邮箱未注册时同样返回成功
+func (u *UserHandler) SendResetPasswordCode(context *gin.Context) {
+	req := &UserResetPasswordSendReq{}
+	if err := request.ParseRequestBody(context, req); err != nil || ValidateUserResetPasswordSendReq(req) != nil {
+		result.RespWithError(context, result.PARAM_NOT_EQUAL_CODE, "请求传参或设置有误", nil)
+		return
+	}
+	if ok, _ := u.emailExp.MatchString(req.Email); !ok {
+		result.RespWithError(context, result.PARAM_FORMAT_ERROR_CODE, "邮箱格式错误", nil)
+		return
+	}
+	if err := u.svc.SendResetPasswordCode(context, req.Email); err != nil {
+		if errors.Is(err, service.VerifyCodeSendTooManyErr) {
+			result.RespWithError(context, result.VERIFY_CODE_SEND_TOO_MANY_CODE, "验证码发送过于频繁", nil)
+			return
+		}
+		log.Println(err)
+		result.RespWithError(context, result.UNKNOWN_ERROR_CODE, "系统错误", nil)
+		return
+	}
+	result.RespWithSuccess(context, "如果该邮箱已注册，验证码将发送到该邮箱", nil)
+}
+
+// ResetPassword 校验验证码并设置新密码，成功后已登录的设备需要重新登录
+func (u *UserHandler) ResetPassword(context *gin.Context) {
+	req := &UserResetPasswordReq{}
+	if err := request.ParseRequestBody(context, req); err != nil || ValidateUserResetPasswordReq(req) != nil {
+		result.RespWithError(context, result.PARAM_NOT_EQUAL_CODE, "请求传参或设置有误", nil)
+		return
+	}
+	if ok, _ := u.emailExp.MatchString(req.Email); !ok {
+		result.RespWithError(context, result.PARAM_FORMAT_ERROR_CODE, "邮箱或密码格式错误", nil)
+		return
+	}
+	if ok, _ := u.passwordExp.MatchString(req.Password); !ok {
+		result.RespWithError(context, result.PARAM_FORMAT_ERROR_CODE, "邮箱或密码格式错误", nil)
+		return
+	}
+	err := u.svc.ResetPassword(context, req.Email, req.VerifyCode, req.Password, req.ConfirmPassword)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.PasswordNotEqualErr):
+			result.RespWithError(context, result.TWO_PASSWORD_NOT_EQUAL_CODE, "两次输入的密码不一致", nil)
+		case errors.Is(err, service.VerifyCodeComparedErr):
+			result.RespWithError(context, result.VERIFY_CODE_COMPARED_ERROR_CODE, "验证码输入错误，请尝试再次输入", nil)
+		case errors.Is(err, service.VerifyCodeNotExists):
+			result.RespWithError(context, result.VERIFY_CODE_NOT_EXISTS_CODE, "当前邮箱不存在有效验证码", nil)
+		case errors.Is(err, service.VerifyCodeRetryTooManyErr):
+			result.RespWithError(context, result.VERIFY_CODE_RETRY_TOO_MANY_CODE, "重试次数过多，请尝试获取新的验证码", nil)
+		case errors.Is(err, service.UserNotExistsErr):
+			result.RespWithError(context, result.USER_DO_NOT_EXISTS_CODE, "用户不存在", nil)
+		default:
+			log.Println(err)
+			result.RespWithError(context, result.UNKNOWN_ERROR_CODE, "服务内部异常，请联系管理员", nil)
+		}
+		return
+	}
+	result.RespWithSuccess(context, "密码已重置，请重新登录", nil)
+}
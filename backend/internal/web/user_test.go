@@ -31,7 +31,7 @@ func TestUserSignup(t *testing.T) {
 			h := NewUserHandler(usersvc)
 			h.RegisterRoutesV1(server)
 
-			usersvc.EXPECT().SignUp(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(&service.User{
+			usersvc.EXPECT().SignUp(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(&service.User{
 				Id:          0,
 				Email:       "781201402@qq.com",
 				PhoneNumber: "",
@@ -55,3 +55,178 @@ func TestUserSignup(t *testing.T) {
 		})
 	}
 }
+
+func TestUserEdit(t *testing.T) {
+	testCases := []struct {
+		name     string
+		body     string
+		mock     func(usersvc *usvcmocks.MockUserService)
+		wantCode string
+	}{
+		{
+			name: "修改昵称与简介",
+			body: `{"nickName": "ibook", "bio": "hello"}`,
+			mock: func(usersvc *usvcmocks.MockUserService) {
+				usersvc.EXPECT().EditProfile(gomock.Any(), int64(1), gomock.Any()).Return(&service.User{
+					Id:       1,
+					NickName: "ibook",
+					Bio:      "hello",
+				}, nil)
+			},
+			wantCode: `"code":200`,
+		},
+		{
+			name:     "未传任何字段",
+			body:     `{}`,
+			mock:     func(usersvc *usvcmocks.MockUserService) {},
+			wantCode: `"code":4000`,
+		},
+		{
+			name: "昵称修改过于频繁",
+			body: `{"nickName": "ibook"}`,
+			mock: func(usersvc *usvcmocks.MockUserService) {
+				usersvc.EXPECT().EditProfile(gomock.Any(), int64(1), gomock.Any()).Return(nil, service.NickNameCooldownErr)
+			},
+			wantCode: `"code":4014`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			server := gin.Default()
+			server.Use(func(ctx *gin.Context) {
+				ctx.Set("userId", int64(1))
+			})
+			usersvc := usvcmocks.NewMockUserService(ctrl)
+			tc.mock(usersvc)
+			h := NewUserHandler(usersvc)
+			h.RegisterRoutesV1(server)
+
+			req, err := http.NewRequest(http.MethodPost, "/users/edit", bytes.NewBuffer([]byte(tc.body)))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+			server.ServeHTTP(resp, req)
+			assert.Equal(t, http.StatusOK, resp.Code)
+			require.Contains(t, resp.Body.String(), tc.wantCode)
+		})
+	}
+}
+
+func TestUserFollow(t *testing.T) {
+	testCases := []struct {
+		name     string
+		body     string
+		mock     func(usersvc *usvcmocks.MockUserService)
+		wantCode string
+	}{
+		{
+			name: "关注用户",
+			body: `{"userId": 2, "follow": 1}`,
+			mock: func(usersvc *usvcmocks.MockUserService) {
+				usersvc.EXPECT().Follow(gomock.Any(), int64(1), int64(2)).Return(nil)
+			},
+			wantCode: `"code":200`,
+		},
+		{
+			name: "取消关注",
+			body: `{"userId": 2, "follow": 0}`,
+			mock: func(usersvc *usvcmocks.MockUserService) {
+				usersvc.EXPECT().Unfollow(gomock.Any(), int64(1), int64(2)).Return(nil)
+			},
+			wantCode: `"code":200`,
+		},
+		{
+			name: "关注自己",
+			body: `{"userId": 1, "follow": 1}`,
+			mock: func(usersvc *usvcmocks.MockUserService) {
+				usersvc.EXPECT().Follow(gomock.Any(), int64(1), int64(1)).Return(service.FollowSelfErr)
+			},
+			wantCode: `"code":4001`,
+		},
+		{
+			name:     "关注状态不合法",
+			body:     `{"userId": 2, "follow": 2}`,
+			mock:     func(usersvc *usvcmocks.MockUserService) {},
+			wantCode: `"code":4000`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			server := gin.Default()
+			server.Use(func(ctx *gin.Context) {
+				ctx.Set("userId", int64(1))
+			})
+			usersvc := usvcmocks.NewMockUserService(ctrl)
+			tc.mock(usersvc)
+			h := NewUserHandler(usersvc)
+			h.RegisterRoutesV1(server)
+
+			req, err := http.NewRequest(http.MethodPost, "/users/follow", bytes.NewBuffer([]byte(tc.body)))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+			server.ServeHTTP(resp, req)
+			assert.Equal(t, http.StatusOK, resp.Code)
+			require.Contains(t, resp.Body.String(), tc.wantCode)
+		})
+	}
+}
+
+func TestUserResetPassword(t *testing.T) {
+	testCases := []struct {
+		name     string
+		body     string
+		mock     func(usersvc *usvcmocks.MockUserService)
+		wantCode string
+	}{
+		{
+			name: "重置成功",
+			body: `{"email": "781201402@qq.com", "verifyCode": "123456", "password": "123@123#abc", "confirmPassword": "123@123#abc"}`,
+			mock: func(usersvc *usvcmocks.MockUserService) {
+				usersvc.EXPECT().ResetPassword(gomock.Any(), "781201402@qq.com", "123456", "123@123#abc", "123@123#abc").Return(nil)
+			},
+			wantCode: `"code":200`,
+		},
+		{
+			name:     "密码不符合要求",
+			body:     `{"email": "781201402@qq.com", "verifyCode": "123456", "password": "12345678", "confirmPassword": "12345678"}`,
+			mock:     func(usersvc *usvcmocks.MockUserService) {},
+			wantCode: `"code":4001`,
+		},
+		{
+			name: "验证码错误",
+			body: `{"email": "781201402@qq.com", "verifyCode": "000000", "password": "123@123#abc", "confirmPassword": "123@123#abc"}`,
+			mock: func(usersvc *usvcmocks.MockUserService) {
+				usersvc.EXPECT().ResetPassword(gomock.Any(), "781201402@qq.com", "000000", "123@123#abc", "123@123#abc").
+					Return(service.VerifyCodeComparedErr)
+			},
+			wantCode: `"code":4009`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			server := gin.Default()
+			usersvc := usvcmocks.NewMockUserService(ctrl)
+			tc.mock(usersvc)
+			h := NewUserHandler(usersvc)
+			h.RegisterRoutesV1(server)
+
+			req, err := http.NewRequest(http.MethodPost, "/users/password/reset", bytes.NewBuffer([]byte(tc.body)))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+			server.ServeHTTP(resp, req)
+			assert.Equal(t, http.StatusOK, resp.Code)
+			require.Contains(t, resp.Body.String(), tc.wantCode)
+		})
+	}
+}
@@ -31,9 +31,62 @@ type UserLoginResp struct {
 }
 
 type UserProfileResp struct {
-	UserId   int64  `json:"userId"`
-	NickName string `json:"nickName"`
-	Email    string `json:"email"`
+	UserId    int64  `json:"userId"`
+	NickName  string `json:"nickName"`
+	Email     string `json:"email"`
+	Bio       string `json:"bio"`
+	Birthday  string `json:"birthday"`
+	AvatarUrl string `json:"avatarUrl"`
+	// 以下字段只在查看个人主页时返回
+	FollowerCnt  int64 `json:"followerCnt"`
+	FollowingCnt int64 `json:"followingCnt"`
+	// 当前登录用户是否已关注该用户
+	Followed bool `json:"followed"`
+}
+
+// UserEditReq 字段不传或为 null 时不修改，birthday 与 avatarUrl 传空字符串表示清空
+type UserEditReq struct {
+	NickName *string `json:"nickName"`
+	Bio      *string `json:"bio"`
+	// 格式为 YYYY-MM-DD
+	Birthday  *string `json:"birthday"`
+	AvatarUrl *string `json:"avatarUrl"`
+}
+
+type UserResetPasswordSendReq struct {
+	Email string `json:"email"`
+}
+
+type UserResetPasswordReq struct {
+	Email           string `json:"email"`
+	VerifyCode      string `json:"verifyCode"`
+	Password        string `json:"password"`
+	ConfirmPassword string `json:"confirmPassword"`
+}
+
+type UserFollowReq struct {
+	UserId int64 `json:"userId"`
+	// 1 为关注，0 为取消关注
+	Follow uint8 `json:"follow"`
+}
+
+type FollowListReq struct {
+	// 上一页返回的 nextCursor，为空时查询第一页
+	Cursor string `form:"cursor" json:"cursor"`
+	Limit  int64  `form:"limit" json:"limit"`
+}
+
+type FollowListReply struct {
+	Users      []*FollowUser `json:"users"`
+	NextCursor string        `json:"nextCursor,omitempty"`
+	HasMore    bool          `json:"hasMore"`
+}
+
+type FollowUser struct {
+	UserId       int64  `json:"userId"`
+	NickName     string `json:"nickName"`
+	AvatarUrl    string `json:"avatarUrl"`
+	FollowedTime string `json:"followedTime"`
 }
 
 type UserSmsLoginSendReq struct {
@@ -59,6 +112,35 @@ func ValidateUserLoginReq(req *UserLoginReq) error {
 	return nil
 }
 
+func ValidateUserEditReq(req *UserEditReq) error {
+	if req.NickName == nil && req.Bio == nil && req.Birthday == nil && req.AvatarUrl == nil {
+		return InvalidReqBodyErr
+	}
+	return nil
+}
+
+func ValidateUserResetPasswordSendReq(req *UserResetPasswordSendReq) error {
+	if strings.TrimSpace(req.Email) == "" {
+		return InvalidReqBodyErr
+	}
+	return nil
+}
+
+func ValidateUserResetPasswordReq(req *UserResetPasswordReq) error {
+	if strings.TrimSpace(req.Email) == "" || strings.TrimSpace(req.VerifyCode) == "" ||
+		strings.TrimSpace(req.Password) == "" || strings.TrimSpace(req.ConfirmPassword) == "" {
+		return InvalidReqBodyErr
+	}
+	return nil
+}
+
+func ValidateUserFollowReq(req *UserFollowReq) error {
+	if req.UserId <= 0 || req.Follow > 1 {
+		return InvalidReqBodyErr
+	}
+	return nil
+}
+
 func ValidateUserSmsLoginSendReq(req *UserSmsLoginSendReq) error {
 	if strings.TrimSpace(req.PhoneNumber) == "" {
 		return InvalidReqBodyErr
@@ -6,7 +6,7 @@ import (
 )
 
 // WebProviderSet is data providers.
-var WebProviderSet = wire.NewSet(NewUserHandler, NewArticleHandler)
+var WebProviderSet = wire.NewSet(NewUserHandler, NewArticleHandler, NewSearchHandler, NewUploadHandler)
 
 var (
 	InvalidReqBodyErr = errors.New("请求参数不合法")
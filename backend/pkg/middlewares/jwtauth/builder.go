@@ -4,6 +4,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 	"ibook/internal/conf"
+	"log"
 	"net/http"
 	"strings"
 	"time"
@@ -17,6 +18,19 @@ var (
 type myClaims struct {
 	jwt.RegisteredClaims
 	UserId int64 `json:"userId"`
+	// IssuedAtMs 毫秒精度的签发时间，iat 只精确到秒，无法区分同一秒内吊销前后签发的令牌
+	IssuedAtMs int64 `json:"iatMs"`
+}
+
+// issuedAtMs 旧版本签发的令牌没有 iatMs，按 iat 所在秒的起始时间计算
+func (c *myClaims) issuedAtMs() int64 {
+	if c.IssuedAtMs > 0 {
+		return c.IssuedAtMs
+	}
+	if c.IssuedAt == nil {
+		return 0
+	}
+	return c.IssuedAt.UnixMilli()
 }
 
 func GenerateToken(userId int64) string {
@@ -27,7 +41,8 @@ func GenerateToken(userId int64) string {
 			ExpiresAt: jwt.NewNumericDate(lifeTime),
 			IssuedAt:  jwt.NewNumericDate(now),
 		},
-		UserId: userId,
+		UserId:     userId,
+		IssuedAtMs: now.UnixMilli(),
 	})
 	tokenString, err := token.SignedString([]byte(secretKey))
 	if err != nil {
@@ -36,10 +51,15 @@ func GenerateToken(userId int64) string {
 	return tokenString
 }
 
+// RevokedBeforeFunc 返回用户令牌的吊销时间（Unix 毫秒），签发时间早于该时间的令牌视为无效，未吊销时返回 0
+type RevokedBeforeFunc func(ctx *gin.Context, userId int64) (int64, error)
+
 type LoginJWTMiddlewareBuilder struct {
 	paths               []string
+	prefixes            []string
 	secretKey           string
 	lifeDurationSeconds int64
+	revokedBefore       RevokedBeforeFunc
 }
 
 func NewLoginJWTMiddlewareBuilder() *LoginJWTMiddlewareBuilder {
@@ -51,6 +71,18 @@ func (l *LoginJWTMiddlewareBuilder) IgnorePaths(path string) *LoginJWTMiddleware
 	return l
 }
 
+// IgnorePathPrefix 放行以 prefix 开头的全部路径，用于静态文件等无需登录的资源
+func (l *LoginJWTMiddlewareBuilder) IgnorePathPrefix(prefix string) *LoginJWTMiddlewareBuilder {
+	l.prefixes = append(l.prefixes, prefix)
+	return l
+}
+
+// RevokeChecker 设置令牌吊销时间的查询方法，用于修改密码等场景下使旧令牌失效
+func (l *LoginJWTMiddlewareBuilder) RevokeChecker(fn RevokedBeforeFunc) *LoginJWTMiddlewareBuilder {
+	l.revokedBefore = fn
+	return l
+}
+
 // SetEncryptEnv 用户需要传入用以加密的 secretKey 与 token 的有效时长（单位为秒）
 func SetEncryptEnv(userSecretKey string, userLifeDurationSeconds int64) {
 	secretKey = userSecretKey
@@ -67,6 +99,11 @@ func (l *LoginJWTMiddlewareBuilder) Build() gin.HandlerFunc {
 				return
 			}
 		}
+		for _, prefix := range l.prefixes {
+			if strings.HasPrefix(ctx.Request.URL.Path, prefix) {
+				return
+			}
+		}
 		tokenHeader := ctx.GetHeader("Authorization")
 		if tokenHeader == "" {
 			unAuthorized(ctx)
@@ -91,6 +128,20 @@ func (l *LoginJWTMiddlewareBuilder) Build() gin.HandlerFunc {
 			return
 		}
 
+		if l.revokedBefore != nil {
+			// 查询失败时拒绝访问，否则已吊销的令牌在缓存故障期间仍然有效
+			before, err := l.revokedBefore(ctx, claims.UserId)
+			if err != nil {
+				log.Println("查询令牌吊销时间失败：", err)
+				unavailable(ctx)
+				return
+			}
+			if before > 0 && claims.issuedAtMs() < before {
+				unAuthorized(ctx)
+				return
+			}
+		}
+
 		now := time.Now().UTC()
 		// 每十秒钟刷新一次
 		if claims.IssuedAt.Sub(now) < -time.Second*10 {
@@ -101,6 +152,14 @@ func (l *LoginJWTMiddlewareBuilder) Build() gin.HandlerFunc {
 	}
 }
 
+func unavailable(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{
+		"code": http.StatusServiceUnavailable,
+		"msg":  "服务暂时不可用，请稍后重试",
+	})
+	ctx.Abort()
+}
+
 func unAuthorized(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, gin.H{
 		"code": http.StatusUnauthorized,
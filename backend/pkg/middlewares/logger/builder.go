@@ -49,7 +49,7 @@ func (b *MiddlewareBuilder) Build() gin.HandlerFunc {
 			Value: reqId,
 		})
 		ctx.Set("ctx-logger", specLogger)
-		specLogger.Info("[请求入参]:", []logger.Field{{"Method", al.Method}, {"URL", al.Url}}...)
+		specLogger.Info("[请求入参]:", []logger.Field{{Key: "Method", Value: al.Method}, {Key: "URL", Value: al.Url}}...)
 		if b.allowReqBody && ctx.Request.Body != nil {
 			// Body 读完就没有了
 			body, _ := ctx.GetRawData()
@@ -111,16 +111,16 @@ type AccessLog struct {
 func transferLogStructToString(log *AccessLog) []logger.Field {
 	return []logger.Field{
 		{
-			"Duration",
-			log.Duration,
+			Key:   "Duration",
+			Value: log.Duration,
 		},
 		{
-			"Status",
-			log.Status,
+			Key:   "Status",
+			Value: log.Status,
 		},
 		{
-			"ResponseBody",
-			log.RespBody,
+			Key:   "ResponseBody",
+			Value: log.RespBody,
 		},
 	}
 }
@@ -0,0 +1,52 @@
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+)
+
+var (
+	ErrNotFound   = errors.New("blobstore: 对象不存在")
+	ErrInvalidKey = errors.New("blobstore: key 不合法")
+)
+
+// maxKeyLength 与 S3 对象 key 的长度上限一致
+const maxKeyLength = 1024
+
+// Info 对象的元信息
+type Info struct {
+	Size        int64
+	ContentType string
+}
+
+// BlobStore 二进制对象存储，key 为以 / 分隔的相对路径，例如 images/avatar/ab/abcd.png
+type BlobStore interface {
+	// Put 写入对象，key 已存在时覆盖
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	// Get 读取对象，调用方负责关闭返回的 ReadCloser，对象不存在时返回 ErrNotFound
+	Get(ctx context.Context, key string) (io.ReadCloser, *Info, error)
+	// Stat 查询对象的元信息，对象不存在时返回 ErrNotFound
+	Stat(ctx context.Context, key string) (*Info, error)
+	// Delete 删除对象，对象不存在时不返回错误
+	Delete(ctx context.Context, key string) error
+}
+
+// ValidKey 校验 key 只由字母、数字与 ._- 组成的路径段构成，不允许空段、. 与 ..，避免在本地存储中越出根目录
+func ValidKey(key string) bool {
+	if key == "" || len(key) > maxKeyLength {
+		return false
+	}
+	for _, seg := range strings.Split(key, "/") {
+		if seg == "" || seg == "." || seg == ".." {
+			return false
+		}
+		for _, c := range seg {
+			if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '.' || c == '_' || c == '-') {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,104 @@
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// LocalStore 将对象保存在本地目录中，适用于单机部署与开发环境
+// 本地文件不保存 Content-Type，读取时按 key 的扩展名推断
+type LocalStore struct {
+	root string
+}
+
+func NewLocalStore(root string) (*LocalStore, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, err
+	}
+	return &LocalStore{root: root}, nil
+}
+
+func (s *LocalStore) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	if !ValidKey(key) {
+		return ErrInvalidKey
+	}
+	p := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	// 先写入临时文件再重命名，读取方不会读到写了一半的文件
+	tmp, err := os.CreateTemp(filepath.Dir(p), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), p)
+}
+
+func (s *LocalStore) Get(ctx context.Context, key string) (io.ReadCloser, *Info, error) {
+	if !ValidKey(key) {
+		return nil, nil, ErrInvalidKey
+	}
+	f, err := os.Open(s.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	st, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	return f, &Info{Size: st.Size(), ContentType: contentTypeByKey(key)}, nil
+}
+
+func (s *LocalStore) Stat(ctx context.Context, key string) (*Info, error) {
+	if !ValidKey(key) {
+		return nil, ErrInvalidKey
+	}
+	st, err := os.Stat(s.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Info{Size: st.Size(), ContentType: contentTypeByKey(key)}, nil
+}
+
+func (s *LocalStore) Delete(ctx context.Context, key string) error {
+	if !ValidKey(key) {
+		return ErrInvalidKey
+	}
+	err := os.Remove(s.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (s *LocalStore) path(key string) string {
+	return filepath.Join(s.root, filepath.FromSlash(key))
+}
+
+func contentTypeByKey(key string) string {
+	if ct := mime.TypeByExtension(path.Ext(key)); ct != "" {
+		return ct
+	}
+	return "application/octet-stream"
+}
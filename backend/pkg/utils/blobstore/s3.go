@@ -0,0 +1,277 @@
+package blobstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	s3Service   = "s3"
+	s3Algorithm = "AWS4-HMAC-SHA256"
+	// emptyPayloadHash 空请求体的 SHA256，用于 GET / HEAD / DELETE
+	emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+// S3Config S3 兼容存储的连接配置，AWS S3、MinIO、Ceph RGW 以及各家云厂商的兼容接口均可使用
+type S3Config struct {
+	// Endpoint 服务地址，例如 https://s3.us-east-1.amazonaws.com 或 http://127.0.0.1:9000
+	Endpoint        string
+	Region          string
+	Bucket          string
+	AccessKeyId     string
+	SecretAccessKey string
+	// PathStyle 为 true 时使用 {endpoint}/{bucket}/{key} 形式的地址，否则使用 {bucket}.{endpoint}/{key}
+	// MinIO 等自建服务通常需要开启
+	PathStyle bool
+	// Client 为 nil 时使用带超时的默认客户端
+	Client *http.Client
+}
+
+// S3Store 通过 S3 REST 接口读写对象，请求使用 Signature V4 签名
+type S3Store struct {
+	cfg      S3Config
+	endpoint *url.URL
+	client   *http.Client
+	now      func() time.Time
+}
+
+func NewS3Store(cfg S3Config) (*S3Store, error) {
+	endpoint, err := url.Parse(strings.TrimRight(cfg.Endpoint, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("blobstore: endpoint 不合法: %w", err)
+	}
+	if endpoint.Scheme != "http" && endpoint.Scheme != "https" || endpoint.Host == "" {
+		return nil, fmt.Errorf("blobstore: endpoint 需为 http(s) 地址: %s", cfg.Endpoint)
+	}
+	if cfg.Bucket == "" || cfg.Region == "" {
+		return nil, errors.New("blobstore: bucket 与 region 不能为空")
+	}
+	client := cfg.Client
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &S3Store{cfg: cfg, endpoint: endpoint, client: client, now: time.Now}, nil
+}
+
+func (s *S3Store) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	if !ValidKey(key) {
+		return ErrInvalidKey
+	}
+	req, err := s.newRequest(ctx, http.MethodPut, key, data)
+	if err != nil {
+		return err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	sum := sha256.Sum256(data)
+	resp, err := s.do(req, hex.EncodeToString(sum[:]))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return s3Error(resp)
+	}
+	return nil
+}
+
+func (s *S3Store) Get(ctx context.Context, key string) (io.ReadCloser, *Info, error) {
+	if !ValidKey(key) {
+		return nil, nil, ErrInvalidKey
+	}
+	req, err := s.newRequest(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	resp, err := s.do(req, emptyPayloadHash)
+	if err != nil {
+		return nil, nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Body, &Info{Size: resp.ContentLength, ContentType: resp.Header.Get("Content-Type")}, nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, nil, ErrNotFound
+	default:
+		defer resp.Body.Close()
+		return nil, nil, s3Error(resp)
+	}
+}
+
+func (s *S3Store) Stat(ctx context.Context, key string) (*Info, error) {
+	if !ValidKey(key) {
+		return nil, ErrInvalidKey
+	}
+	req, err := s.newRequest(ctx, http.MethodHead, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.do(req, emptyPayloadHash)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return &Info{Size: resp.ContentLength, ContentType: resp.Header.Get("Content-Type")}, nil
+	case http.StatusNotFound:
+		return nil, ErrNotFound
+	default:
+		// HEAD 请求没有响应体，只能返回状态码
+		return nil, fmt.Errorf("blobstore: s3 返回 %s", resp.Status)
+	}
+}
+
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	if !ValidKey(key) {
+		return ErrInvalidKey
+	}
+	req, err := s.newRequest(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(req, emptyPayloadHash)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusNotFound:
+		return nil
+	default:
+		return s3Error(resp)
+	}
+}
+
+// objectURL 生成对象地址，key 已经过 ValidKey 校验，只包含无需转义的字符
+func (s *S3Store) objectURL(key string) *url.URL {
+	u := *s.endpoint
+	if s.cfg.PathStyle {
+		u.Path = u.Path + "/" + s.cfg.Bucket + "/" + key
+	} else {
+		u.Host = s.cfg.Bucket + "." + u.Host
+		u.Path = u.Path + "/" + key
+	}
+	return &u
+}
+
+func (s *S3Store) newRequest(ctx context.Context, method string, key string, body []byte) (*http.Request, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	return http.NewRequestWithContext(ctx, method, s.objectURL(key).String(), reader)
+}
+
+func (s *S3Store) do(req *http.Request, payloadHash string) (*http.Response, error) {
+	s.sign(req, payloadHash)
+	return s.client.Do(req)
+}
+
+// sign 按 Signature V4 为请求签名，签名的请求头为 host、content-type、content-md5、range 与全部 x-amz-* 头
+func (s *S3Store) sign(req *http.Request, payloadHash string) {
+	t := s.now().UTC()
+	amzDate := t.Format("20060102T150405Z")
+	date := t.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		if lower == "content-type" || lower == "content-md5" || lower == "range" || strings.HasPrefix(lower, "x-amz-") {
+			headers[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		uriEncode(req.URL.Path, false),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := date + "/" + s.cfg.Region + "/" + s3Service + "/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := s3Algorithm + "\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	signingKey := hmacSHA256([]byte("AWS4"+s.cfg.SecretAccessKey), date)
+	signingKey = hmacSHA256(signingKey, s.cfg.Region)
+	signingKey = hmacSHA256(signingKey, s3Service)
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, s.cfg.AccessKeyId, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		values := append([]string(nil), query[k]...)
+		sort.Strings(values)
+		for _, v := range values {
+			pairs = append(pairs, uriEncode(k, true)+"="+uriEncode(v, true))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// uriEncode 按 S3 的规则转义，只保留 A-Za-z0-9-_.~，encodeSlash 为 false 时保留 /
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// s3Error 读取错误响应体的前一部分，S3 的错误信息以 XML 形式返回
+func s3Error(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("blobstore: s3 返回 %s: %s", resp.Status, strings.TrimSpace(string(body)))
+}
@@ -0,0 +1,31 @@
+package fulltext
+
+import (
+	"github.com/go-ego/gse"
+	"strings"
+)
+
+// gseTokenizer 基于 gse 的中文分词器，使用搜索引擎模式切分，长词会额外切出其中的短词
+type gseTokenizer struct {
+	seg gse.Segmenter
+}
+
+// NewGseTokenizer 加载内置的中文词典与停用词，耗时约数秒，应在服务启动时创建一次
+func NewGseTokenizer() (Tokenizer, error) {
+	seg, err := gse.NewEmbed("zh")
+	if err != nil {
+		return nil, err
+	}
+	if err = seg.LoadStopEmbed(); err != nil {
+		return nil, err
+	}
+	return &gseTokenizer{seg: seg}, nil
+}
+
+func (t *gseTokenizer) Tokenize(text string) []string {
+	tokens := t.seg.Trim(t.seg.CutSearch(text, true))
+	for i := range tokens {
+		tokens[i] = strings.ToLower(tokens[i])
+	}
+	return tokens
+}
@@ -0,0 +1,89 @@
+package fulltext
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+// Highlight 截取 text 中首个命中词附近不超过 maxRunes 个字符的片段，并用 <em> 标记所有命中的词
+// 返回的片段已做 HTML 转义，可直接嵌入页面
+func Highlight(text string, terms []string, maxRunes int) string {
+	runes := []rune(text)
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+	termRunes := make([][]rune, 0, len(terms))
+	for _, term := range terms {
+		if term != "" {
+			termRunes = append(termRunes, []rune(strings.ToLower(term)))
+		}
+	}
+
+	// 1. 确定片段范围，命中词前保留约 1/4 的上下文
+	start := 0
+	if first, _ := matchAt(lower, termRunes, 0); first >= 0 {
+		start = first - maxRunes/4
+		if start < 0 {
+			start = 0
+		}
+	}
+	end := start + maxRunes
+	if end > len(runes) {
+		end = len(runes)
+		if start = end - maxRunes; start < 0 {
+			start = 0
+		}
+	}
+
+	// 2. 在片段内标记命中的词
+	var sb strings.Builder
+	if start > 0 {
+		sb.WriteString("...")
+	}
+	for i := start; i < end; {
+		pos, length := matchAt(lower[:end], termRunes, i)
+		if pos < 0 {
+			sb.WriteString(html.EscapeString(string(runes[i:end])))
+			break
+		}
+		sb.WriteString(html.EscapeString(string(runes[i:pos])))
+		sb.WriteString("<em>")
+		sb.WriteString(html.EscapeString(string(runes[pos : pos+length])))
+		sb.WriteString("</em>")
+		i = pos + length
+	}
+	if end < len(runes) {
+		sb.WriteString("...")
+	}
+	return sb.String()
+}
+
+// matchAt 从 from 开始查找最早出现的词，同一位置有多个词命中时取最长的
+func matchAt(text []rune, terms [][]rune, from int) (int, int) {
+	for i := from; i < len(text); i++ {
+		longest := 0
+		for _, term := range terms {
+			if len(term) > longest && hasPrefix(text[i:], term) {
+				longest = len(term)
+			}
+		}
+		if longest > 0 {
+			return i, longest
+		}
+	}
+	return -1, 0
+}
+
+func hasPrefix(text []rune, prefix []rune) bool {
+	if len(text) < len(prefix) {
+		return false
+	}
+	for i := range prefix {
+		if text[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
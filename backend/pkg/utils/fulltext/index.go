@@ -0,0 +1,226 @@
+package fulltext
+
+import (
+	"bytes"
+	"encoding/gob"
+	"math"
+	"sort"
+	"sync"
+)
+
+const (
+	// titleWeight 标题中出现的词按正文中出现 titleWeight 次计算
+	titleWeight = 3
+	// BM25 参数
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// Tokenizer 将文本切分为用于建立索引与检索的词
+type Tokenizer interface {
+	Tokenize(text string) []string
+}
+
+type Document struct {
+	Id      int64
+	Title   string
+	Content string
+}
+
+type Hit struct {
+	Id    int64
+	Score float64
+}
+
+// Index 内存中的倒排索引，使用 BM25 计算相关度，可并发读写
+type Index struct {
+	tokenizer Tokenizer
+
+	mu sync.RWMutex
+	// postings 词 -> 文档 id -> 加权词频
+	postings map[string]map[int64]int32
+	// docLens 文档 id -> 加权长度
+	docLens map[int64]int32
+	// docTerms 文档 id -> 文档包含的词，用于删除或更新文档
+	docTerms map[int64][]string
+	totalLen int64
+}
+
+func NewIndex(tokenizer Tokenizer) *Index {
+	return &Index{
+		tokenizer: tokenizer,
+		postings:  make(map[string]map[int64]int32),
+		docLens:   make(map[int64]int32),
+		docTerms:  make(map[int64][]string),
+	}
+}
+
+// Tokenize 使用索引的分词器切分文本，并去除重复的词
+func (idx *Index) Tokenize(text string) []string {
+	tokens := idx.tokenizer.Tokenize(text)
+	res := make([]string, 0, len(tokens))
+	seen := make(map[string]struct{}, len(tokens))
+	for _, token := range tokens {
+		if _, ok := seen[token]; ok {
+			continue
+		}
+		seen[token] = struct{}{}
+		res = append(res, token)
+	}
+	return res
+}
+
+// Upsert 写入文档，文档已存在时覆盖
+func (idx *Index) Upsert(doc Document) {
+	// 分词较慢，在加锁之前完成
+	tf := make(map[string]int32)
+	var length int32
+	for _, token := range idx.tokenizer.Tokenize(doc.Title) {
+		tf[token] += titleWeight
+		length += titleWeight
+	}
+	for _, token := range idx.tokenizer.Tokenize(doc.Content) {
+		tf[token]++
+		length++
+	}
+	terms := make([]string, 0, len(tf))
+	for term := range tf {
+		terms = append(terms, term)
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.deleteLocked(doc.Id)
+	for term, cnt := range tf {
+		docs, ok := idx.postings[term]
+		if !ok {
+			docs = make(map[int64]int32)
+			idx.postings[term] = docs
+		}
+		docs[doc.Id] = cnt
+	}
+	idx.docLens[doc.Id] = length
+	idx.docTerms[doc.Id] = terms
+	idx.totalLen += int64(length)
+}
+
+func (idx *Index) Delete(id int64) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.deleteLocked(id)
+}
+
+func (idx *Index) deleteLocked(id int64) {
+	terms, ok := idx.docTerms[id]
+	if !ok {
+		return
+	}
+	for _, term := range terms {
+		docs := idx.postings[term]
+		delete(docs, id)
+		if len(docs) == 0 {
+			delete(idx.postings, term)
+		}
+	}
+	idx.totalLen -= int64(idx.docLens[id])
+	delete(idx.docLens, id)
+	delete(idx.docTerms, id)
+}
+
+// Len 返回索引中的文档数
+func (idx *Index) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return len(idx.docLens)
+}
+
+// Search 检索包含任意查询词的文档，按相关度倒序返回 [offset, offset+limit) 范围内的结果以及命中总数
+func (idx *Index) Search(terms []string, offset int, limit int) ([]Hit, int) {
+	idx.mu.RLock()
+	n := float64(len(idx.docLens))
+	if n == 0 || len(terms) == 0 {
+		idx.mu.RUnlock()
+		return []Hit{}, 0
+	}
+	avgLen := float64(idx.totalLen) / n
+	scores := make(map[int64]float64)
+	for _, term := range terms {
+		docs := idx.postings[term]
+		if len(docs) == 0 {
+			continue
+		}
+		df := float64(len(docs))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		for id, cnt := range docs {
+			tf := float64(cnt)
+			norm := bm25K1 * (1 - bm25B + bm25B*float64(idx.docLens[id])/avgLen)
+			scores[id] += idf * tf * (bm25K1 + 1) / (tf + norm)
+		}
+	}
+	idx.mu.RUnlock()
+
+	hits := make([]Hit, 0, len(scores))
+	for id, score := range scores {
+		hits = append(hits, Hit{Id: id, Score: score})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].Id > hits[j].Id
+	})
+	total := len(hits)
+	if offset >= total {
+		return []Hit{}, total
+	}
+	end := offset + limit
+	if end > total {
+		end = total
+	}
+	return hits[offset:end], total
+}
+
+type snapshot struct {
+	Postings map[string]map[int64]int32
+	DocLens  map[int64]int32
+	DocTerms map[int64][]string
+	TotalLen int64
+}
+
+// GobEncode 实现 gob.GobEncoder，用于将索引持久化到磁盘
+func (idx *Index) GobEncode() ([]byte, error) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(snapshot{
+		Postings: idx.postings,
+		DocLens:  idx.docLens,
+		DocTerms: idx.docTerms,
+		TotalLen: idx.totalLen,
+	})
+	return buf.Bytes(), err
+}
+
+// GobDecode 实现 gob.GobDecoder，分词器需在解码前通过 NewIndex 设置
+func (idx *Index) GobDecode(data []byte) error {
+	var s snapshot
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&s); err != nil {
+		return err
+	}
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.postings = s.Postings
+	idx.docLens = s.DocLens
+	idx.docTerms = s.DocTerms
+	idx.totalLen = s.TotalLen
+	if idx.postings == nil {
+		idx.postings = make(map[string]map[int64]int32)
+	}
+	if idx.docLens == nil {
+		idx.docLens = make(map[int64]int32)
+	}
+	if idx.docTerms == nil {
+		idx.docTerms = make(map[int64][]string)
+	}
+	return nil
+}
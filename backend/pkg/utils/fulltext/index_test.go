@@ -0,0 +1,148 @@
+package fulltext
+
+import (
+	"bytes"
+	"encoding/gob"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+type spaceTokenizer struct{}
+
+func (spaceTokenizer) Tokenize(text string) []string {
+	return strings.Fields(strings.ToLower(text))
+}
+
+func newTestIndex() *Index {
+	idx := NewIndex(spaceTokenizer{})
+	idx.Upsert(Document{Id: 1, Title: "go concurrency", Content: "goroutine and channel"})
+	idx.Upsert(Document{Id: 2, Title: "redis cache", Content: "cache aside pattern with go"})
+	idx.Upsert(Document{Id: 3, Title: "mysql index", Content: "b+ tree"})
+	return idx
+}
+
+func TestIndexSearch(t *testing.T) {
+	testCases := []struct {
+		name    string
+		terms   []string
+		offset  int
+		limit   int
+		wantIds []int64
+		total   int
+	}{
+		{
+			name:    "标题命中优先",
+			terms:   []string{"go"},
+			limit:   10,
+			wantIds: []int64{1, 2},
+			total:   2,
+		},
+		{
+			name:    "多个词命中",
+			terms:   []string{"cache", "go"},
+			limit:   10,
+			wantIds: []int64{2, 1},
+			total:   2,
+		},
+		{
+			name:    "分页",
+			terms:   []string{"go"},
+			offset:  1,
+			limit:   1,
+			wantIds: []int64{2},
+			total:   2,
+		},
+		{
+			name:    "无结果",
+			terms:   []string{"kafka"},
+			limit:   10,
+			wantIds: []int64{},
+			total:   0,
+		},
+	}
+	idx := newTestIndex()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			hits, total := idx.Search(tc.terms, tc.offset, tc.limit)
+			ids := make([]int64, 0, len(hits))
+			for _, hit := range hits {
+				ids = append(ids, hit.Id)
+			}
+			assert.Equal(t, tc.wantIds, ids)
+			assert.Equal(t, tc.total, total)
+		})
+	}
+}
+
+func TestIndexUpsertAndDelete(t *testing.T) {
+	idx := newTestIndex()
+	idx.Upsert(Document{Id: 1, Title: "python", Content: "asyncio"})
+	hits, _ := idx.Search([]string{"go"}, 0, 10)
+	assert.Len(t, hits, 1)
+	assert.Equal(t, int64(2), hits[0].Id)
+
+	idx.Delete(2)
+	hits, _ = idx.Search([]string{"go"}, 0, 10)
+	assert.Empty(t, hits)
+	assert.Equal(t, 2, idx.Len())
+	assert.NotContains(t, idx.postings, "go")
+}
+
+func TestIndexGob(t *testing.T) {
+	idx := newTestIndex()
+	var buf bytes.Buffer
+	require.NoError(t, gob.NewEncoder(&buf).Encode(idx))
+
+	restored := NewIndex(spaceTokenizer{})
+	require.NoError(t, gob.NewDecoder(&buf).Decode(restored))
+	assert.Equal(t, idx.Len(), restored.Len())
+	want, _ := idx.Search([]string{"go", "cache"}, 0, 10)
+	got, _ := restored.Search([]string{"go", "cache"}, 0, 10)
+	assert.Equal(t, want, got)
+}
+
+func TestHighlight(t *testing.T) {
+	testCases := []struct {
+		name     string
+		text     string
+		terms    []string
+		maxRunes int
+		want     string
+	}{
+		{
+			name:     "标记命中词",
+			text:     "Go 语言的并发编程",
+			terms:    []string{"go", "并发"},
+			maxRunes: 20,
+			want:     "<em>Go</em> 语言的<em>并发</em>编程",
+		},
+		{
+			name:     "截取命中词附近的片段",
+			text:     "一二三四五六七八九十并发",
+			terms:    []string{"并发"},
+			maxRunes: 4,
+			want:     "...九十<em>并发</em>",
+		},
+		{
+			name:     "转义 HTML",
+			text:     "<b>redis</b>",
+			terms:    []string{"redis"},
+			maxRunes: 20,
+			want:     "&lt;b&gt;<em>redis</em>&lt;/b&gt;",
+		},
+		{
+			name:     "未命中时取开头",
+			text:     "abcdef",
+			terms:    []string{"x"},
+			maxRunes: 3,
+			want:     "abc...",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, Highlight(tc.text, tc.terms, tc.maxRunes))
+		})
+	}
+}
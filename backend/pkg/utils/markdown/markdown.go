@@ -0,0 +1,79 @@
+package markdown
+
+import (
+	"bytes"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/text"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var (
+	// goldmark 默认不输出原始 HTML，渲染结果仍需经过 bluemonday 清洗（如 javascript: 链接）
+	md = goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+	policy = func() *bluemonday.Policy {
+		p := bluemonday.UGCPolicy()
+		// 保留代码块的语言标记，供前端做语法高亮
+		p.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[\w+-]+$`)).OnElements("code")
+		return p
+	}()
+)
+
+// Render 将 markdown 渲染为清洗过的 HTML，可直接嵌入页面
+func Render(src string) (string, error) {
+	var buf bytes.Buffer
+	if err := md.Convert([]byte(src), &buf); err != nil {
+		return "", err
+	}
+	return policy.Sanitize(buf.String()), nil
+}
+
+// PlainText 去除 markdown 标记，返回正文的纯文本，代码块与原始 HTML 不计入
+func PlainText(src string) string {
+	source := []byte(src)
+	doc := md.Parser().Parse(text.NewReader(source))
+	var sb strings.Builder
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		switch node := n.(type) {
+		case *ast.FencedCodeBlock, *ast.CodeBlock, *ast.HTMLBlock, *ast.RawHTML:
+			return ast.WalkSkipChildren, nil
+		case *ast.Text:
+			if entering {
+				sb.Write(node.Segment.Value(source))
+				if node.SoftLineBreak() || node.HardLineBreak() {
+					sb.WriteByte(' ')
+				}
+			}
+		case *ast.String:
+			if entering {
+				sb.Write(node.Value)
+			}
+		default:
+			// 块级元素之间补充空白，避免相邻段落的文字粘连
+			if !entering && n.Type() == ast.TypeBlock {
+				sb.WriteByte(' ')
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+	return CollapseSpace(sb.String())
+}
+
+// CollapseSpace 将连续的空白字符合并为一个空格，并去除首尾空白
+func CollapseSpace(s string) string {
+	return strings.Join(strings.FieldsFunc(s, unicode.IsSpace), " ")
+}
+
+// Truncate 按字符截取不超过 n 个字符的文本
+func Truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
@@ -0,0 +1,89 @@
+package markdown
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	testCases := []struct {
+		name     string
+		src      string
+		contains []string
+		excludes []string
+	}{
+		{
+			name:     "基本语法",
+			src:      "# 标题\n\n**加粗** 与 `code`",
+			contains: []string{"<h1", "<strong>加粗</strong>", "<code>code</code>"},
+		},
+		{
+			name:     "保留代码语言",
+			src:      "```go\nfmt.Println()\n```",
+			contains: []string{`<code class="language-go">`},
+		},
+		{
+			name:     "过滤原始 HTML",
+			src:      "<script>alert(1)</script>\n\n<img src=x onerror=alert(1)>",
+			excludes: []string{"<script", "onerror"},
+		},
+		{
+			name:     "过滤 javascript 链接",
+			src:      "[点我](javascript:alert(1))",
+			excludes: []string{"javascript:"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			html, err := Render(tc.src)
+			assert.NoError(t, err)
+			for _, s := range tc.contains {
+				assert.Contains(t, html, s)
+			}
+			for _, s := range tc.excludes {
+				assert.NotContains(t, html, s)
+			}
+		})
+	}
+}
+
+func TestPlainText(t *testing.T) {
+	testCases := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "去除标记",
+			src:  "# 标题\n\n这是**一段**[链接](https://example.com)。\n第二行",
+			want: "标题 这是一段链接。 第二行",
+		},
+		{
+			name: "跳过代码块与 HTML",
+			src:  "开头\n\n```\ncode\n```\n\n<div>html</div>\n\n结尾",
+			want: "开头 结尾",
+		},
+		{
+			name: "列表",
+			src:  "- 一\n- 二",
+			want: "一 二",
+		},
+		{
+			name: "空内容",
+			src:  "",
+			want: "",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, PlainText(tc.src))
+		})
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "短文本", Truncate("短文本", 100))
+	assert.Equal(t, "中文字", Truncate("中文字符串", 3))
+	assert.Equal(t, strings.Repeat("a", 100), Truncate(strings.Repeat("a", 150), 100))
+}
@@ -12,5 +12,10 @@ const (
 	VERIFY_CODE_RETRY_TOO_MANY_CODE = 4008
 	VERIFY_CODE_COMPARED_ERROR_CODE = 4009
 	VERIFY_CODE_NOT_EXISTS_CODE     = 4010
+	RECORD_DO_NOT_EXISTS_CODE       = 4011
+	PERMISSION_DENIED_CODE          = 4012
+	VERSION_CONFLICT_CODE           = 4013
+	OPERATION_TOO_FREQUENT_CODE     = 4014
 	UNKNOWN_ERROR_CODE              = 5000
+	SERVICE_UNAVAILABLE_CODE        = 5003
 )
@@ -0,0 +1,219 @@
+package textdiff
+
+import (
+	"strings"
+	"unicode"
+)
+
+type OpType int8
+
+const (
+	OpEqual OpType = iota
+	OpInsert
+	OpDelete
+)
+
+func (t OpType) String() string {
+	switch t {
+	case OpInsert:
+		return "insert"
+	case OpDelete:
+		return "delete"
+	default:
+		return "equal"
+	}
+}
+
+// Op 一段连续的相同类型的修改，Text 为对应 token 拼接后的文本
+type Op struct {
+	Type OpType
+	Text string
+}
+
+// Lines 按行切分文本，每行保留行尾的换行符，拼接后与原文一致
+func Lines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	res := strings.SplitAfter(s, "\n")
+	if res[len(res)-1] == "" {
+		res = res[:len(res)-1]
+	}
+	return res
+}
+
+// Words 按词切分文本：连续的字母数字、连续的空白各为一个 token，
+// 中日韩文字与标点符号逐字切分，拼接后与原文一致
+func Words(s string) []string {
+	var res []string
+	runes := []rune(s)
+	for i := 0; i < len(runes); {
+		j := i + 1
+		switch {
+		case isWordRune(runes[i]):
+			for j < len(runes) && isWordRune(runes[j]) {
+				j++
+			}
+		case unicode.IsSpace(runes[i]):
+			for j < len(runes) && unicode.IsSpace(runes[j]) {
+				j++
+			}
+		}
+		res = append(res, string(runes[i:j]))
+		i = j
+	}
+	return res
+}
+
+func isWordRune(r rune) bool {
+	if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) {
+		return false
+	}
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+const (
+	// maxDiffTokens 去掉公共前后缀后参与计算的 token 数上限
+	maxDiffTokens = 200000
+	// maxEditDistance 最短编辑距离的上限，回溯需要保存每一轮的状态，占用的内存与其平方成正比
+	maxEditDistance = 2000
+)
+
+// Diff 使用 Myers 算法计算从 a 到 b 的最短编辑序列，相邻的同类修改会被合并
+// 差异过大（超过 maxDiffTokens 或 maxEditDistance）时不再计算最短编辑序列，
+// 公共前后缀之间的部分整体作为一次删除加一次插入返回
+func Diff(a, b []string) []Op {
+	// 公共前后缀不参与计算，可以明显减少长文本小改动时的开销
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix &&
+		a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	edits := make([]edit, 0, len(a)+len(b))
+	for _, token := range a[:prefix] {
+		edits = append(edits, edit{typ: OpEqual, text: token})
+	}
+	midA, midB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	mid, ok := myers(midA, midB)
+	if !ok {
+		mid = replaceAll(midA, midB)
+	}
+	edits = append(edits, mid...)
+	for _, token := range a[len(a)-suffix:] {
+		edits = append(edits, edit{typ: OpEqual, text: token})
+	}
+	return merge(edits)
+}
+
+type edit struct {
+	typ  OpType
+	text string
+}
+
+// myers 编辑距离超过 maxEditDistance 或 token 数超过 maxDiffTokens 时返回 false
+func myers(a, b []string) ([]edit, bool) {
+	n, m := len(a), len(b)
+	max := n + m
+	if max == 0 {
+		return nil, true
+	}
+	if max > maxDiffTokens {
+		return nil, false
+	}
+	offset := max
+	v := make([]int, 2*max+2)
+	// trace[d] 保存第 d 轮开始前 v 中 k 属于 [-d, d] 的部分，回溯第 d 轮时只会用到这一段
+	var trace [][]int
+	for d := 0; d <= max && d <= maxEditDistance; d++ {
+		snapshot := make([]int, 2*d+1)
+		copy(snapshot, v[offset-d:offset+d+1])
+		trace = append(trace, snapshot)
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrack(trace, a, b), true
+			}
+		}
+	}
+	return nil, false
+}
+
+func backtrack(trace [][]int, a, b []string) []edit {
+	x, y := len(a), len(b)
+	res := make([]edit, 0, x+y)
+	for d := len(trace) - 1; d >= 0; d-- {
+		// 快照中下标 d+k 对应对角线 k
+		v := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[d+k-1] < v[d+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		// 第 0 轮从 (0, 0) 出发，快照中没有 k = ±1 的位置
+		prevX := 0
+		if d > 0 {
+			prevX = v[d+prevK]
+		}
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			res = append(res, edit{typ: OpEqual, text: a[x-1]})
+			x--
+			y--
+		}
+		if d > 0 {
+			if x == prevX {
+				res = append(res, edit{typ: OpInsert, text: b[y-1]})
+			} else {
+				res = append(res, edit{typ: OpDelete, text: a[x-1]})
+			}
+		}
+		x, y = prevX, prevY
+	}
+	for i, j := 0, len(res)-1; i < j; i, j = i+1, j-1 {
+		res[i], res[j] = res[j], res[i]
+	}
+	return res
+}
+
+// replaceAll 将 a 整体删除后插入 b
+func replaceAll(a, b []string) []edit {
+	res := make([]edit, 0, len(a)+len(b))
+	for _, token := range a {
+		res = append(res, edit{typ: OpDelete, text: token})
+	}
+	for _, token := range b {
+		res = append(res, edit{typ: OpInsert, text: token})
+	}
+	return res
+}
+
+func merge(edits []edit) []Op {
+	res := make([]Op, 0)
+	var sb strings.Builder
+	for i, e := range edits {
+		sb.WriteString(e.text)
+		if i == len(edits)-1 || edits[i+1].typ != e.typ {
+			res = append(res, Op{Type: e.typ, Text: sb.String()})
+			sb.Reset()
+		}
+	}
+	return res
+}
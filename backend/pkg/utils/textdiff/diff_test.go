@@ -0,0 +1,178 @@
+package textdiff
+
+import (
+	"github.com/stretchr/testify/assert"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	testCases := []struct {
+		name  string
+		split func(string) []string
+		a     string
+		b     string
+		want  []Op
+	}{
+		{
+			name:  "完全相同",
+			split: Lines,
+			a:     "a\nb\n",
+			b:     "a\nb\n",
+			want:  []Op{{Type: OpEqual, Text: "a\nb\n"}},
+		},
+		{
+			name:  "两段均为空",
+			split: Lines,
+			a:     "",
+			b:     "",
+			want:  []Op{},
+		},
+		{
+			name:  "按行新增与删除",
+			split: Lines,
+			a:     "a\nb\nc\n",
+			b:     "a\nc\nd\n",
+			want: []Op{
+				{Type: OpEqual, Text: "a\n"},
+				{Type: OpDelete, Text: "b\n"},
+				{Type: OpEqual, Text: "c\n"},
+				{Type: OpInsert, Text: "d\n"},
+			},
+		},
+		{
+			name:  "按词替换",
+			split: Words,
+			a:     "hello big world",
+			b:     "hello small world",
+			want: []Op{
+				{Type: OpEqual, Text: "hello "},
+				{Type: OpDelete, Text: "big"},
+				{Type: OpInsert, Text: "small"},
+				{Type: OpEqual, Text: " world"},
+			},
+		},
+		{
+			name:  "中文逐字比较",
+			split: Words,
+			a:     "今天天气很好",
+			b:     "今天天气不好",
+			want: []Op{
+				{Type: OpEqual, Text: "今天天气"},
+				{Type: OpDelete, Text: "很"},
+				{Type: OpInsert, Text: "不"},
+				{Type: OpEqual, Text: "好"},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ops := Diff(tc.split(tc.a), tc.split(tc.b))
+			assert.Equal(t, tc.want, ops)
+		})
+	}
+}
+
+func TestDiffReconstruct(t *testing.T) {
+	a := "ABCABBA"
+	b := "CBABAC"
+	ops := Diff(strings.Split(a, ""), strings.Split(b, ""))
+	var oldText, newText strings.Builder
+	edits := 0
+	for _, op := range ops {
+		switch op.Type {
+		case OpEqual:
+			oldText.WriteString(op.Text)
+			newText.WriteString(op.Text)
+		case OpDelete:
+			oldText.WriteString(op.Text)
+			edits += len(op.Text)
+		case OpInsert:
+			newText.WriteString(op.Text)
+			edits += len(op.Text)
+		}
+	}
+	assert.Equal(t, a, oldText.String())
+	assert.Equal(t, b, newText.String())
+	// Myers 论文中该例子的最短编辑距离为 5
+	assert.Equal(t, 5, edits)
+}
+
+func TestDiffLargeInput(t *testing.T) {
+	tokens := func(prefix string, n int) []string {
+		res := make([]string, n)
+		for i := range res {
+			res[i] = prefix + strconv.Itoa(i) + " "
+		}
+		return res
+	}
+	testCases := []struct {
+		name string
+		a    []string
+		b    []string
+		// wantFallback 差异过大时公共前后缀之间整体替换
+		wantFallback bool
+		wantEdits    int
+	}{
+		{
+			name:         "编辑距离超过上限",
+			a:            tokens("a", 50000),
+			b:            tokens("b", 50000),
+			wantFallback: true,
+			wantEdits:    100000,
+		},
+		{
+			name:         "token 数超过上限",
+			a:            tokens("a", 150000),
+			b:            tokens("b", 150000),
+			wantFallback: true,
+			wantEdits:    300000,
+		},
+		{
+			name: "长文本中的少量修改",
+			a:    tokens("a", 100000),
+			b: func() []string {
+				b := tokens("a", 100000)
+				for i := 1000; i < 100000; i += 10000 {
+					b[i] = "changed "
+				}
+				return b
+			}(),
+			wantEdits: 20,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var before, after runtime.MemStats
+			runtime.ReadMemStats(&before)
+			ops := Diff(tc.a, tc.b)
+			runtime.ReadMemStats(&after)
+			// 未限制时需要保存 O((N+M)·D) 个整数，这里的输入会分配数十 GB
+			assert.Less(t, after.TotalAlloc-before.TotalAlloc, uint64(256<<20))
+
+			var oldText, newText strings.Builder
+			edits := 0
+			for _, op := range ops {
+				switch op.Type {
+				case OpEqual:
+					oldText.WriteString(op.Text)
+					newText.WriteString(op.Text)
+				case OpDelete:
+					oldText.WriteString(op.Text)
+					edits += strings.Count(op.Text, " ")
+				case OpInsert:
+					newText.WriteString(op.Text)
+					edits += strings.Count(op.Text, " ")
+				}
+			}
+			assert.Equal(t, strings.Join(tc.a, ""), oldText.String())
+			assert.Equal(t, strings.Join(tc.b, ""), newText.String())
+			assert.Equal(t, tc.wantEdits, edits)
+			if tc.wantFallback {
+				assert.Equal(t, []OpType{OpDelete, OpInsert}, []OpType{ops[0].Type, ops[1].Type})
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package thumbnail
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/draw"
+	// 注册 gif 解码器，gif 只取第一帧
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+)
+
+const (
+	// maxPixels 解码前按图片头中的尺寸限制像素数，避免超大尺寸的图片耗尽内存
+	maxPixels   = 40_000_000
+	jpegQuality = 85
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("thumbnail: 不支持的图片格式")
+	ErrTooManyPixels     = errors.New("thumbnail: 图片尺寸过大")
+)
+
+// Decode 解码 jpeg / png / gif 图片，gif 只取第一帧，返回图片与格式名
+func Decode(data []byte) (image.Image, string, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", ErrUnsupportedFormat
+	}
+	if format != "jpeg" && format != "png" && format != "gif" {
+		return nil, "", ErrUnsupportedFormat
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || int64(cfg.Width)*int64(cfg.Height) > maxPixels {
+		return nil, "", ErrTooManyPixels
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", err
+	}
+	return img, format, nil
+}
+
+// Fit 等比缩放到宽不超过 maxWidth，原图更小时不放大
+func Fit(src image.Image, maxWidth int) image.Image {
+	b := src.Bounds()
+	if b.Dx() <= maxWidth {
+		return src
+	}
+	height := b.Dy() * maxWidth / b.Dx()
+	if height < 1 {
+		height = 1
+	}
+	return resize(src, b, maxWidth, height)
+}
+
+// Square 居中裁剪为正方形后缩放到 side×side，原图更小时只裁剪不放大
+func Square(src image.Image, side int) image.Image {
+	b := src.Bounds()
+	n := min(b.Dx(), b.Dy())
+	x0 := b.Min.X + (b.Dx()-n)/2
+	y0 := b.Min.Y + (b.Dy()-n)/2
+	crop := image.Rect(x0, y0, x0+n, y0+n)
+	return resize(src, crop, min(side, n), min(side, n))
+}
+
+// Encode 原图为 jpeg 时编码为 jpeg，否则编码为 png 以保留透明通道，返回编码后的数据与 Content-Type
+func Encode(img image.Image, format string) ([]byte, string, error) {
+	buf := &bytes.Buffer{}
+	if format == "jpeg" {
+		if err := jpeg.Encode(buf, img, &jpeg.Options{Quality: jpegQuality}); err != nil {
+			return nil, "", err
+		}
+		return buf.Bytes(), "image/jpeg", nil
+	}
+	if err := png.Encode(buf, img); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), "image/png", nil
+}
+
+// resize 将 src 中 rect 区域按面积平均缩放到 width×height，缩小时比最近邻采样更平滑
+func resize(src image.Image, rect image.Rectangle, width, height int) image.Image {
+	// 先转换为 RGBA（预乘 alpha），便于直接按字节累加
+	rgba := image.NewRGBA(image.Rect(0, 0, rect.Dx(), rect.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), src, rect.Min, draw.Src)
+	srcW, srcH := rect.Dx(), rect.Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		sy0 := y * srcH / height
+		sy1 := max((y+1)*srcH/height, sy0+1)
+		for x := 0; x < width; x++ {
+			sx0 := x * srcW / width
+			sx1 := max((x+1)*srcW/width, sx0+1)
+			var r, g, b, a, cnt uint64
+			for sy := sy0; sy < sy1; sy++ {
+				off := sy*rgba.Stride + sx0*4
+				for sx := sx0; sx < sx1; sx++ {
+					r += uint64(rgba.Pix[off])
+					g += uint64(rgba.Pix[off+1])
+					b += uint64(rgba.Pix[off+2])
+					a += uint64(rgba.Pix[off+3])
+					off += 4
+					cnt++
+				}
+			}
+			doff := y*dst.Stride + x*4
+			dst.Pix[doff] = uint8(r / cnt)
+			dst.Pix[doff+1] = uint8(g / cnt)
+			dst.Pix[doff+2] = uint8(b / cnt)
+			dst.Pix[doff+3] = uint8(a / cnt)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,81 @@
+package thumbnail
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newImage(w, h int, c color.Color) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestFit(t *testing.T) {
+	testCases := []struct {
+		name     string
+		w, h     int
+		maxWidth int
+		wantW    int
+		wantH    int
+	}{
+		{name: "等比缩小", w: 800, h: 600, maxWidth: 320, wantW: 320, wantH: 240},
+		{name: "原图更小时不放大", w: 200, h: 100, maxWidth: 320, wantW: 200, wantH: 100},
+		{name: "极扁的图片高度至少为 1", w: 1000, h: 1, maxWidth: 10, wantW: 10, wantH: 1},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			b := Fit(newImage(tc.w, tc.h, color.White), tc.maxWidth).Bounds()
+			assert.Equal(t, tc.wantW, b.Dx())
+			assert.Equal(t, tc.wantH, b.Dy())
+		})
+	}
+}
+
+func TestSquare(t *testing.T) {
+	// 左半边红色、右半边蓝色，居中裁剪后左右两侧的颜色应保持不变
+	src := image.NewRGBA(image.Rect(0, 0, 400, 200))
+	for y := 0; y < 200; y++ {
+		for x := 0; x < 400; x++ {
+			if x < 200 {
+				src.Set(x, y, color.RGBA{R: 255, A: 255})
+			} else {
+				src.Set(x, y, color.RGBA{B: 255, A: 255})
+			}
+		}
+	}
+	dst := Square(src, 64)
+	assert.Equal(t, image.Rect(0, 0, 64, 64), dst.Bounds())
+	assert.Equal(t, color.RGBA{R: 255, A: 255}, dst.At(0, 32))
+	assert.Equal(t, color.RGBA{B: 255, A: 255}, dst.At(63, 32))
+
+	small := Square(newImage(30, 50, color.White), 64)
+	assert.Equal(t, image.Rect(0, 0, 30, 30), small.Bounds())
+}
+
+func TestDecodeAndEncode(t *testing.T) {
+	buf := &bytes.Buffer{}
+	require.NoError(t, png.Encode(buf, newImage(10, 10, color.RGBA{G: 255, A: 128})))
+	img, format, err := Decode(buf.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, "png", format)
+	assert.Equal(t, 10, img.Bounds().Dx())
+
+	data, contentType, err := Encode(img, format)
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", contentType)
+	assert.NotEmpty(t, data)
+
+	_, _, err = Decode([]byte("not an image"))
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+}